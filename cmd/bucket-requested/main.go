@@ -73,6 +73,12 @@ func handler(ctx context.Context, event json.RawMessage) error {
 	obj := files.NewS3Object(e.BucketName(), e.ObjectKey())
 	log.Printf("Received event for bucket name: %s, object key: %s", obj.Bucket, obj.Key)
 
+	if buckets.IsVerifyRequest(obj.Key) {
+		// Verification requests are handled by the verify-requested function
+		log.Printf("Ignoring verification request: %s", obj.Key)
+		return nil
+	}
+
 	requestedBuckets, err := buckets.GetBuckets(ctx, s3Client, obj, bucketLimit)
 	if err != nil {
 		bucketsStatus[buckets.BucketRequestedFileErrorKey] = err.Error()
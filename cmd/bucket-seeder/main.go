@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/inventory"
+	"duracloud/internal/seeding"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+var (
+	bucketPrefix       string
+	checksumTable      string
+	dynamodbClient     *dynamodb.Client
+	managedBucketName  string
+	objectCreatedQueue string
+	progressStore      *seeding.ProgressStore
+	s3Client           *s3.Client
+	seedRate           = seeding.DefaultRate
+	sqsClient          *sqs.Client
+)
+
+type SeedRequest struct {
+	Bucket string `json:"bucket"`
+	Source string `json:"source,omitempty"`
+}
+
+type SeedResponse struct {
+	seeding.Progress
+	Message string `json:"message"`
+	Summary string `json:"summary,omitempty"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	if value := os.Getenv("SEED_RATE"); value != "" {
+		seedRate, err = strconv.Atoi(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read seed rate: %v", err))
+		}
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	objectCreatedQueue = os.Getenv("SQS_OBJECT_CREATED_URL")
+	s3Client = s3.NewFromConfig(awsConfig)
+	progressStore = seeding.NewProgressStore(s3Client, managedBucketName)
+	sqsClient = sqs.NewFromConfig(awsConfig)
+}
+
+func handler(ctx context.Context, request SeedRequest) (SeedResponse, error) {
+	bucketName := request.Bucket
+	if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+		return SeedResponse{}, fmt.Errorf("bucket is not tracked by this stack: %s", bucketName)
+	}
+
+	progress, found, err := progressStore.Load(ctx, bucketName)
+	if err != nil {
+		return SeedResponse{}, err
+	}
+
+	if !found {
+		source := request.Source
+		if source == "" {
+			source = seeding.SourceVersions
+		}
+
+		progress, err = seeding.NewProgress(bucketName, source)
+		if err != nil {
+			return SeedResponse{}, err
+		}
+	} else if request.Source != "" && request.Source != progress.Source {
+		return SeedResponse{}, seeding.ErrorSourceMismatch(bucketName, progress.Source)
+	}
+
+	log.Printf("Seeding %s from %s after key: %q version: %q",
+		bucketName, progress.Source, progress.KeyMarker, progress.VersionIdMarker)
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, "")
+	seeder := seeding.NewSeeder(ctx, s3Client, ddb, sqsClient, objectCreatedQueue).WithRate(seedRate)
+
+	if err := seed(ctx, seeder, &progress); err != nil {
+		// Keep what was enqueued so the next invocation resumes from there
+		if saveErr := progressStore.Save(ctx, progress); saveErr != nil {
+			log.Printf("Failed to save seeding progress of %s: %v", bucketName, saveErr)
+		}
+		return SeedResponse{}, fmt.Errorf("failed to seed %s after %d versions: %w", bucketName, progress.Listed, err)
+	}
+
+	if !progress.Complete {
+		if err := progressStore.Save(ctx, progress); err != nil {
+			return SeedResponse{}, err
+		}
+
+		message := fmt.Sprintf("Enqueued %d deposits of %d versions in %s, invoke again to continue",
+			progress.Enqueued, progress.Listed, bucketName)
+		log.Println(message)
+		return SeedResponse{Progress: progress, Message: message}, nil
+	}
+
+	summaryKey, err := progressStore.Complete(ctx, progress)
+	if err != nil {
+		return SeedResponse{}, err
+	}
+	summary := files.NewS3Object(managedBucketName, summaryKey).URI()
+
+	message := fmt.Sprintf("Seeded %s: enqueued %d deposits of %d versions (%d with a record, %d deleted, %d failed)",
+		bucketName, progress.Enqueued, progress.Listed, progress.Existing, progress.Deleted, progress.Failed)
+	log.Println(message)
+
+	return SeedResponse{Progress: progress, Message: message, Summary: summary}, nil
+}
+
+func seed(ctx context.Context, seeder *seeding.Seeder, progress *seeding.Progress) error {
+	if progress.Source == seeding.SourceVersions {
+		return seeder.SeedVersions(progress)
+	}
+
+	// The inventory is kept so a seeding resumes with the versions it was started with
+	if progress.Inventory == "" {
+		inventoryObj, err := inventory.LatestInventory(ctx, s3Client, managedBucketName, progress.Bucket)
+		if err != nil {
+			return err
+		}
+		progress.Inventory = inventoryObj.Key
+	}
+
+	reader, err := files.DownloadObject(ctx, s3Client, files.NewS3Object(managedBucketName, progress.Inventory), false)
+	if err != nil {
+		return err
+	}
+
+	objects, err := inventory.ReadInventory(reader)
+	_ = reader.Close()
+	if err != nil {
+		return err
+	}
+
+	return seeder.SeedInventory(progress, objects)
+}
+
+func main() {
+	lambda.Start(handler)
+}
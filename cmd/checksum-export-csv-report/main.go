@@ -2,20 +2,26 @@ package main
 
 import (
 	"context"
+	"duracloud/internal/db"
 	"duracloud/internal/exports"
 	"duracloud/internal/files"
 	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
 var (
-	s3Client *s3.Client
+	dynamodbClient *dynamodb.Client
+	exportFormats  []exports.ExportFormat
+	historyTable   string
+	s3Client       *s3.Client
 )
 
 func init() {
@@ -24,6 +30,13 @@ func init() {
 		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
 	}
 
+	exportFormats, err = exports.ParseFormats(os.Getenv("EXPORT_FORMATS"))
+	if err != nil {
+		panic(fmt.Sprintf("Invalid export formats: %v", err))
+	}
+
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	historyTable = os.Getenv("DYNAMODB_HISTORY_TABLE")
 	s3Client = s3.NewFromConfig(awsConfig)
 }
 
@@ -46,7 +59,12 @@ func handler(ctx context.Context, event json.RawMessage) error {
 
 	log.Printf("Processing manifest: %s, Key: %s", obj.Bucket, obj.Key)
 
-	exporter := exports.NewExporter(ctx, s3Client, obj)
+	exporter := exports.NewExporter(ctx, s3Client, obj).WithFormats(exportFormats...)
+	if historyTable != "" {
+		// Only the history table is read when writing reports
+		exporter.WithHistory(db.NewDB(ctx, dynamodbClient, "", "").WithHistory(historyTable))
+	}
+
 	err := exporter.ProcessManifest()
 	if err != nil {
 		return fmt.Errorf("error generating export report: %w", err)
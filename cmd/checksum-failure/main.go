@@ -56,9 +56,24 @@ func handler(ctx context.Context, event events.DynamoDBEvent) error {
 			continue
 		}
 
+		if db.IsMigration(record) {
+			// The failure was reported when it was recorded in the table keyed by ObjectKey
+			continue
+		}
+
+		if !db.IsNewCheck(record) {
+			// The failure was reported when the check was recorded
+			continue
+		}
+
 		bucket := record.Change.NewImage[string(db.ChecksumTableBucketNameId)].String()
 		object := record.Change.NewImage[string(db.ChecksumTableObjectKeyId)].String()
 
+		var versionId string
+		if version, exists := record.Change.NewImage[string(db.ChecksumTableVersionIdId)]; exists {
+			versionId = version.String()
+		}
+
 		checksumSuccess, exists := record.Change.NewImage[string(db.ChecksumTableStatusId)]
 		if !exists {
 			log.Printf("Checksum status field not found for %s/%s", bucket, object)
@@ -78,16 +93,42 @@ func handler(ctx context.Context, event events.DynamoDBEvent) error {
 			errorMessage = lastError.String()
 		}
 
+		var failure string
+		if category, exists := record.Change.NewImage[string(db.ChecksumTableCategoryId)]; exists {
+			failure = category.String()
+		}
+
+		var replica, replicaMessage string
+		if status, exists := record.Change.NewImage[string(db.ChecksumTableReplicaId)]; exists {
+			replica = status.String()
+		}
+		if message, exists := record.Change.NewImage[string(db.ChecksumTableReplicaMessageId)]; exists && db.IsReplicaFailure(replica) {
+			replicaMessage = message.String()
+		}
+
+		checksums := make(map[string]string)
+		if stored, exists := record.Change.NewImage[string(db.ChecksumTableChecksumsId)]; exists && stored.DataType() == events.DataTypeMap {
+			for algorithm, value := range stored.Map() {
+				checksums[algorithm] = value.String()
+			}
+		}
+
 		notification := notifications.ChecksumFailureNotification{
-			Account:      accountID,
-			Bucket:       bucket,
-			Object:       object,
-			Date:         record.Change.ApproximateCreationDateTime.String(),
-			ErrorMessage: errorMessage,
-			Stack:        stackName,
-			Title:        fmt.Sprintf("DuraCloud Checksum Verification Failure (2): %s/%s", bucket, object),
-			Template:     notificationTmpl,
-			Topic:        snsTopicArn,
+			Account:        accountID,
+			Bucket:         bucket,
+			Checksums:      checksums,
+			Object:         object,
+			Date:           record.Change.ApproximateCreationDateTime.String(),
+			Diverged:       db.Diverged(failure, replica),
+			ErrorMessage:   errorMessage,
+			Failure:        failure,
+			Replica:        replica,
+			ReplicaMessage: replicaMessage,
+			Stack:          stackName,
+			Title:          fmt.Sprintf("DuraCloud Checksum Verification Failure (2): %s/%s", bucket, object),
+			Template:       notificationTmpl,
+			Topic:          snsTopicArn,
+			VersionId:      versionId,
 		}
 
 		if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
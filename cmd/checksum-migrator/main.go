@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	checksumTable           string
+	dynamodbClient          *dynamodb.Client
+	objectKeyChecksumTable  string
+	objectKeySchedulerTable string
+	s3Client                *s3.Client
+	schedulerTable          string
+)
+
+type MigrateRequest struct {
+	StartBucket string `json:"startBucket,omitempty"`
+	StartKey    string `json:"startKey,omitempty"`
+}
+
+type MigrateResponse struct {
+	Complete   bool   `json:"complete"`
+	Copied     int    `json:"copied"`
+	Dropped    int    `json:"dropped"`
+	Existing   int    `json:"existing"`
+	Message    string `json:"message"`
+	NextBucket string `json:"nextBucket,omitempty"`
+	NextKey    string `json:"nextKey,omitempty"`
+	Records    int    `json:"records"`
+	Scheduled  int    `json:"scheduled"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	objectKeyChecksumTable = os.Getenv("DYNAMODB_OBJECT_KEY_CHECKSUM_TABLE")
+	objectKeySchedulerTable = os.Getenv("DYNAMODB_OBJECT_KEY_SCHEDULER_TABLE")
+	s3Client = s3.NewFromConfig(awsConfig)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+}
+
+func handler(ctx context.Context, request MigrateRequest) (MigrateResponse, error) {
+	if request.StartKey != "" && request.StartBucket == "" {
+		return MigrateResponse{}, fmt.Errorf("a start key requires a start bucket: %s", request.StartKey)
+	}
+
+	log.Printf("Copying checksum records from %s to %s after bucket: %q key: %q",
+		objectKeyChecksumTable, checksumTable, request.StartBucket, request.StartKey)
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	result, err := ddb.MigrateObjectKeyTables(objectKeyChecksumTable, objectKeySchedulerTable,
+		request.StartBucket, request.StartKey, currentVersion(ctx))
+	if err != nil {
+		return MigrateResponse{}, fmt.Errorf("failed to copy checksum records after %d records (next bucket: %q key: %q): %w",
+			result.Records, result.NextBucket, result.NextKey, err)
+	}
+
+	message := fmt.Sprintf("Copied %d of %d checksum records with %d scheduled verifications (%d already copied, %d of deleted objects dropped)",
+		result.Copied, result.Records, result.Scheduled, result.Existing, result.Dropped)
+	if !result.Complete {
+		message += ", invoke again with the next bucket and key to continue"
+	}
+	log.Println(message)
+
+	return MigrateResponse{
+		Complete:   result.Complete,
+		Copied:     result.Copied,
+		Dropped:    result.Dropped,
+		Existing:   result.Existing,
+		Message:    message,
+		NextBucket: result.NextBucket,
+		NextKey:    result.NextKey,
+		Records:    result.Records,
+		Scheduled:  result.Scheduled,
+	}, nil
+}
+
+// currentVersion reads the current version of an object from S3, an object whose current
+// version is a delete marker (or that has no version at all) has none
+func currentVersion(ctx context.Context) db.CurrentVersion {
+	return func(obj files.S3Object) (string, bool, error) {
+		resp, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(obj.Bucket),
+			Key:    aws.String(obj.Key),
+		})
+		if err != nil {
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				switch apiErr.ErrorCode() {
+				case "NoSuchBucket", "NoSuchKey", "NotFound":
+					return "", false, nil
+				}
+			}
+			return "", false, fmt.Errorf("failed to read the current version of %s: %w", obj.URI(), err)
+		}
+
+		// An object written before versioning was enabled is the null version, which is read
+		// with the version id "null" so a later version is not verified in its place
+		return aws.ToString(resp.VersionId), true, nil
+	}
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/rebalancing"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	bucketPrefix      string
+	checksumTable     string
+	dynamodbClient    *dynamodb.Client
+	managedBucketName string
+	progressStore     *rebalancing.ProgressStore
+	s3Client          *s3.Client
+	schedulerTable    string
+)
+
+type RebalanceRequest struct {
+	Bucket string `json:"bucket"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+type RebalanceResponse struct {
+	rebalancing.Progress
+	Message string `json:"message"`
+	Report  string `json:"report,omitempty"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	s3Client = s3.NewFromConfig(awsConfig)
+	progressStore = rebalancing.NewProgressStore(s3Client, managedBucketName)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+}
+
+func handler(ctx context.Context, request RebalanceRequest) (RebalanceResponse, error) {
+	bucketName := request.Bucket
+	if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+		return RebalanceResponse{}, fmt.Errorf("bucket is not tracked by this stack: %s", bucketName)
+	}
+
+	progress, found, err := progressStore.Load(ctx, bucketName)
+	if err != nil {
+		return RebalanceResponse{}, err
+	}
+
+	if !found {
+		// Invalid tags are an error here, entries are moved within the windows of the policy
+		policy, err := buckets.GetFixityPolicy(ctx, s3Client, bucketName)
+		if err != nil {
+			return RebalanceResponse{}, err
+		}
+		progress = rebalancing.NewProgress(bucketName, policy, request.DryRun)
+	} else if request.DryRun != progress.DryRun {
+		return RebalanceResponse{}, rebalancing.ErrorDryRunMismatch(bucketName, progress.DryRun)
+	}
+
+	log.Printf("Rebalancing scheduled verifications for %s with policy %s (%s phase, dry run: %t) from key: %q",
+		bucketName, progress.Policy, progress.Phase, progress.DryRun, progress.NextKey)
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	if err := rebalancing.Run(ddb, &progress); err != nil {
+		// Keep what was read and moved so the next invocation resumes from there
+		if saveErr := progressStore.Save(ctx, progress); saveErr != nil {
+			log.Printf("Failed to save rebalancing progress of %s: %v", bucketName, saveErr)
+		}
+		return RebalanceResponse{}, err
+	}
+
+	if !progress.Complete {
+		if err := progressStore.Save(ctx, progress); err != nil {
+			return RebalanceResponse{}, err
+		}
+
+		message := fmt.Sprintf("Surveyed %d scheduled verifications in %s, invoke again to continue",
+			progress.Entries, bucketName)
+		if progress.Phase == rebalancing.PhaseRebalance {
+			message = fmt.Sprintf("Rebalanced %d of %d scheduled verifications in %s (%d moved), invoke again to continue",
+				progress.Moved+progress.Kept+progress.Fixed, progress.Entries, bucketName, progress.Moved)
+		}
+		log.Println(message)
+		return RebalanceResponse{Progress: progress, Message: message}, nil
+	}
+
+	reportKey, err := progressStore.Complete(ctx, progress)
+	if err != nil {
+		return RebalanceResponse{}, err
+	}
+	report := files.NewS3Object(managedBucketName, reportKey).URI()
+
+	beforeDay, before := progress.Before.Peak()
+	afterDay, after := progress.After.Peak()
+	moved := "moved"
+	if progress.DryRun {
+		moved = "dry run, would move"
+	}
+	message := fmt.Sprintf("Rebalanced %s (%s %d of %d scheduled verifications, %d kept, %d fixed): "+
+		"peak day %s with %d objects (%d bytes) before and %s with %d objects (%d bytes) after",
+		bucketName, moved, progress.Moved, progress.Entries, progress.Kept, progress.Fixed,
+		beforeDay, before.Objects, before.Bytes, afterDay, after.Objects, after.Bytes)
+	log.Println(message)
+
+	return RebalanceResponse{Progress: progress, Message: message, Report: report}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
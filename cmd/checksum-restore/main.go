@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/buckets"
+	"duracloud/internal/checksum"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/notifications"
+	"duracloud/internal/queues"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+var (
+	//go:embed templates/repair-notification.txt
+	repairTemplate string
+
+	accountID      string
+	checksumTable  string
+	dynamodbClient *dynamodb.Client
+	historyTable   string
+	repairMode     checksum.RepairMode
+	repairTmpl     *template.Template
+	restoreTable   string
+	s3Client       *s3.Client
+	schedulerTable string
+	snsClient      *sns.Client
+	snsTopicArn    string
+	stackName      string
+)
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	accountID, err = accounts.GetAccountID(context.Background(), awsConfig)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to get AWS account ID: %v", err))
+	}
+
+	repairTmpl, err = template.New("repair").Parse(repairTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse repair template: %v", err))
+	}
+
+	repairMode, err = checksum.ParseRepairMode(os.Getenv("CHECKSUM_REPAIR_MODE"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read repair mode: %v", err))
+	}
+	if repairMode != checksum.RepairModeOff {
+		log.Printf("Checksum repair is enabled: %s", repairMode)
+	}
+
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	historyTable = os.Getenv("DYNAMODB_HISTORY_TABLE")
+	restoreTable = os.Getenv("DYNAMODB_RESTORE_TABLE")
+	s3Client = s3.NewFromConfig(awsConfig)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+	snsClient = sns.NewFromConfig(awsConfig)
+	snsTopicArn = os.Getenv("SNS_TOPIC_ARN")
+	stackName = os.Getenv("STACK_NAME")
+}
+
+func handler(ctx context.Context, event queues.S3EventBridgeEvent) error {
+	if !event.IsObjectRestoreCompleted() || !buckets.IsReplicationBucket(event.BucketName()) {
+		log.Printf("Ignoring %s event for bucket: %s", event.DetailType, event.BucketName())
+		return nil
+	}
+
+	// The restore was requested for the primary object version of this replica
+	obj := files.NewS3ObjectVersion(
+		strings.TrimSuffix(event.BucketName(), buckets.ReplicationSuffix),
+		event.ObjectKey(),
+		event.VersionId(),
+	)
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable).
+		WithHistory(historyTable).
+		WithRestores(restoreTable)
+
+	_, err := ddb.GetRestore(obj)
+	if errors.Is(err, db.ErrRestoreNotFound) {
+		log.Printf("Ignoring restore of %s: no pending replica check", obj.URI())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	verifier := checksum.NewVerifier(ctx, ddb, s3Client, obj).WithReplica()
+	if repairMode != checksum.RepairModeOff {
+		// Corrupted primaries are only reported unless repair is enabled
+		verifier.WithRepair(repairMode)
+	}
+	ok, err := verifier.VerifyReplica()
+	if err != nil {
+		// The pending restore is kept so the event can be retried
+		return err
+	}
+
+	if ok {
+		log.Printf("Replica verification successful: %s", obj.URI())
+	} else {
+		// The record failed so the checksum failure function sends the notification
+		log.Printf("Replica verification failed: %s", obj.URI())
+	}
+
+	if repair := verifier.Repair(); repair.Status != "" {
+		notification := notifications.ChecksumRepairNotification{
+			Account:         accountID,
+			Bucket:          obj.Bucket,
+			Object:          obj.Key,
+			Date:            time.Now().Format(time.RFC3339),
+			RepairMessage:   repair.Message,
+			RepairVersionId: repair.VersionId,
+			Replica:         checksum.ReplicaObject(obj).URI(),
+			Stack:           stackName,
+			Status:          repair.Status,
+			Title:           fmt.Sprintf("DuraCloud Checksum Repair (%s): %s", repair.Status, obj.URI()),
+			Template:        repairTmpl,
+			Topic:           snsTopicArn,
+			VersionId:       obj.VersionId,
+		}
+
+		if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
+			log.Printf("Failed to send checksum repair notification: %v", err)
+		}
+	}
+
+	return ddb.DeleteRestore(obj)
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	bucketPrefix   string
+	checksumTable  string
+	dynamodbClient *dynamodb.Client
+	s3Client       *s3.Client
+	schedulerTable string
+)
+
+type RespreadRequest struct {
+	Bucket   string `json:"bucket"`
+	StartKey string `json:"startKey,omitempty"`
+}
+
+type RespreadResponse struct {
+	Bucket      string `json:"bucket"`
+	Complete    bool   `json:"complete"`
+	Message     string `json:"message"`
+	NextKey     string `json:"nextKey,omitempty"`
+	Policy      string `json:"policy"`
+	Rescheduled int    `json:"rescheduled"`
+	Skipped     int    `json:"skipped"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	s3Client = s3.NewFromConfig(awsConfig)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+}
+
+func handler(ctx context.Context, request RespreadRequest) (RespreadResponse, error) {
+	bucketName := request.Bucket
+	if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+		return RespreadResponse{}, fmt.Errorf("bucket is not tracked by this stack: %s", bucketName)
+	}
+
+	// Invalid tags are an error here, the operator is expecting the new policy
+	policy, err := buckets.GetFixityPolicy(ctx, s3Client, bucketName)
+	if err != nil {
+		return RespreadResponse{}, err
+	}
+
+	log.Printf("Respreading scheduled verifications for %s with policy %s from key: %q",
+		bucketName, policy, request.StartKey)
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	result, err := ddb.Respread(bucketName, policy, request.StartKey)
+	if err != nil {
+		return RespreadResponse{}, fmt.Errorf("failed to respread %s after %d objects: %w",
+			bucketName, result.Rescheduled+result.Skipped, err)
+	}
+
+	message := fmt.Sprintf("Rescheduled %d objects in %s (%d skipped)", result.Rescheduled, bucketName, result.Skipped)
+	if !result.Complete {
+		message += ", invoke again with the next key to continue"
+	}
+	log.Println(message)
+
+	return RespreadResponse{
+		Bucket:      bucketName,
+		Complete:    result.Complete,
+		Message:     message,
+		NextKey:     result.NextKey,
+		Policy:      policy.String(),
+		Rescheduled: result.Rescheduled,
+		Skipped:     result.Skipped,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
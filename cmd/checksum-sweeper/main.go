@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/notifications"
+	"duracloud/internal/sweeping"
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// defaultAlertThreshold is the number of orphans found by a sweep that sends an alert
+const defaultAlertThreshold = 100
+
+// sweepReserve is the time kept back from the Lambda deadline to report the buckets swept so
+// far, buckets that are not started in time are reported as not swept
+const sweepReserve = 2 * time.Minute
+
+var (
+	//go:embed templates/sweep-notification.txt
+	sweepTemplate string
+
+	accountID         string
+	alertThreshold    = defaultAlertThreshold
+	bucketPrefix      string
+	checksumTable     string
+	dynamodbClient    *dynamodb.Client
+	managedBucketName string
+	progressStore     *sweeping.ProgressStore
+	recheckPolicy     db.RecheckPolicy
+	s3Client          *s3.Client
+	schedulerTable    string
+	snsClient         *sns.Client
+	snsTopicArn       string
+	stackName         string
+	sweepTmpl         *template.Template
+)
+
+type SweepRequest struct {
+	Bucket   string `json:"bucket,omitempty"`
+	StartKey string `json:"startKey,omitempty"`
+}
+
+type SweepResponse struct {
+	Buckets []notifications.ChecksumSweepSummary `json:"buckets"`
+	Message string                               `json:"message"`
+	Orphans int                                  `json:"orphans"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	accountID, err = accounts.GetAccountID(context.Background(), awsConfig)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to get AWS account ID: %v", err))
+	}
+
+	sweepTmpl, err = template.New("sweep").Parse(sweepTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse sweep template: %v", err))
+	}
+
+	if value := os.Getenv("ORPHAN_ALERT_THRESHOLD"); value != "" {
+		alertThreshold, err = strconv.Atoi(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read orphan alert threshold: %v", err))
+		}
+	}
+
+	recheckPolicy, err = db.NewRecheckPolicy(os.Getenv("RECHECK_FAILED"), os.Getenv("RECHECK_FAILED_AFTER"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read recheck policy: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	s3Client = s3.NewFromConfig(awsConfig)
+	progressStore = sweeping.NewProgressStore(s3Client, managedBucketName)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+	snsClient = sns.NewFromConfig(awsConfig)
+	snsTopicArn = os.Getenv("SNS_TOPIC_ARN")
+	stackName = os.Getenv("STACK_NAME")
+}
+
+func handler(ctx context.Context, request SweepRequest) (SweepResponse, error) {
+	if request.StartKey != "" && request.Bucket == "" {
+		return SweepResponse{}, fmt.Errorf("a start key requires a bucket: %s", request.StartKey)
+	}
+
+	// Scheduled runs sweep every content bucket of the stack, not only those with an inventory
+	bucketNames := []string{request.Bucket}
+	if request.Bucket == "" {
+		var err error
+		bucketNames, err = buckets.StackBuckets(ctx, s3Client, bucketPrefix)
+		if err != nil {
+			return SweepResponse{}, err
+		}
+	}
+
+	notification := notifications.ChecksumSweepNotification{
+		Account:   accountID,
+		Date:      time.Now().Format(time.RFC3339),
+		Recheck:   recheckPolicy.String(),
+		Stack:     stackName,
+		Threshold: alertThreshold,
+		Title:     fmt.Sprintf("DuraCloud Checksum Sweep: %s", stackName),
+		Template:  sweepTmpl,
+		Topic:     snsTopicArn,
+	}
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	deadline, hasDeadline := ctx.Deadline()
+	failed := false
+
+	for _, bucketName := range bucketNames {
+		if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+			log.Printf("Skipping bucket not tracked by this stack: %s", bucketName)
+			continue
+		}
+
+		if hasDeadline && time.Until(deadline) < sweepReserve {
+			notification.Buckets = append(notification.Buckets, notifications.ChecksumSweepSummary{
+				Bucket: bucketName,
+				Error:  "not swept before the timeout, the next run sweeps it",
+			})
+			continue
+		}
+
+		summary, err := sweep(ctx, ddb, bucketName, request.StartKey)
+		if err != nil {
+			log.Printf("Failed to sweep checksum records of %s: %v", bucketName, err)
+			summary.Error = err.Error()
+			failed = true
+		}
+		notification.Orphans += summary.Orphans
+		notification.Buckets = append(notification.Buckets, summary)
+	}
+
+	message := fmt.Sprintf("Rescheduled %d orphaned checksum records in %d buckets", notification.Orphans, len(notification.Buckets))
+	log.Println(message)
+
+	// Failures are reported with the orphans rescheduled before them
+	if failed || (alertThreshold > 0 && notification.Orphans >= alertThreshold) {
+		if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
+			return SweepResponse{}, err
+		}
+	}
+
+	return SweepResponse{
+		Buckets: notification.Buckets,
+		Message: message,
+		Orphans: notification.Orphans,
+	}, nil
+}
+
+// sweep sweeps the checksum records of a bucket from startKey, or without one from where the
+// last run stopped. The progress of a pass that does not finish is saved so the next run
+// resumes it rather than starting the bucket over.
+func sweep(ctx context.Context, ddb *db.DB, bucketName, startKey string) (notifications.ChecksumSweepSummary, error) {
+	summary := notifications.ChecksumSweepSummary{Bucket: bucketName}
+
+	progress := sweeping.NewProgress(bucketName)
+	resumed := startKey == ""
+	if resumed {
+		saved, found, err := progressStore.Load(ctx, bucketName)
+		if err != nil {
+			return summary, err
+		}
+		if found {
+			progress = saved
+		}
+	} else {
+		progress.NextKey = startKey
+	}
+
+	// Invalid tags are logged and the default policy is used, as when an object is verified
+	policy, err := buckets.GetFixityPolicy(ctx, s3Client, bucketName)
+	if err != nil {
+		log.Printf("Using the default fixity policy for %s: %v", bucketName, err)
+	}
+
+	log.Printf("Sweeping checksum records of %s with fixity policy %s and recheck policy %s from key: %q",
+		bucketName, policy, recheckPolicy, progress.NextKey)
+
+	result, err := ddb.SweepOrphans(bucketName, policy, recheckPolicy, progress.NextKey)
+	summary.NextKey = result.NextKey
+	summary.Orphans = result.Orphans
+	summary.Rechecked = result.Rechecked
+	summary.Records = result.Records
+	summary.Unscheduled = result.Unscheduled
+
+	progress.Add(result)
+	if resumed {
+		// Records swept before an error are not swept again
+		if saveErr := saveProgress(ctx, progress); saveErr != nil {
+			log.Printf("Failed to save sweep progress of %s: %v", bucketName, saveErr)
+			if err == nil {
+				err = saveErr
+			}
+		}
+	}
+	if err != nil {
+		return summary, err
+	}
+
+	log.Printf("Swept %d checksum records of %s: %d orphans rescheduled, %d failures rechecked, %d failures unscheduled",
+		result.Records, bucketName, result.Orphans, result.Rechecked, result.Unscheduled)
+	if !result.Complete {
+		log.Printf("Sweep of %s stopped before the timeout at key: %q", bucketName, result.NextKey)
+	}
+
+	return summary, nil
+}
+
+// saveProgress saves the progress of a sweep that stopped, or writes the totals of a complete
+// sweep and removes its progress
+func saveProgress(ctx context.Context, progress sweeping.Progress) error {
+	if !progress.Complete {
+		return progressStore.Save(ctx, progress)
+	}
+
+	summaryKey, err := progressStore.Complete(ctx, progress)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Completed the sweep of %s started %s: %d records, summary %s",
+		progress.Bucket, progress.Started.Format(time.RFC3339), progress.Records, summaryKey)
+	return nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
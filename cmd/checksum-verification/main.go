@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"duracloud/internal/accounts"
+	"duracloud/internal/buckets"
 	"duracloud/internal/checksum"
 	"duracloud/internal/db"
 	"duracloud/internal/notifications"
@@ -10,6 +11,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"text/template"
 	"time"
 
@@ -27,15 +29,30 @@ var (
 	//go:embed templates/failure-notification.txt
 	notificationTemplate string
 
-	accountID        string
-	checksumTable    string
-	dynamodbClient   *dynamodb.Client
-	notificationTmpl *template.Template
-	s3Client         *s3.Client
-	schedulerTable   string
-	snsClient        *sns.Client
-	snsTopicArn      string
-	stackName        string
+	//go:embed templates/repair-notification.txt
+	repairTemplate string
+
+	accountID         string
+	budget            db.ByteBudget
+	budgetTable       string
+	checkReplicas     bool
+	checksumTable     string
+	eventBridge       *buckets.EventBridgeBuckets
+	dynamodbClient    *dynamodb.Client
+	fixityPolicies    *buckets.FixityPolicies
+	historyTable      string
+	managedBucketName string
+	notificationTmpl  *template.Template
+	repairMode        checksum.RepairMode
+	repairTmpl        *template.Template
+	replicaInterval   db.Period
+	restoreReplicas   bool
+	restoreTable      string
+	s3Client          *s3.Client
+	schedulerTable    string
+	snsClient         *sns.Client
+	snsTopicArn       string
+	stackName         string
 )
 
 func init() {
@@ -59,9 +76,62 @@ func init() {
 		panic(fmt.Sprintf("Failed to parse notification template: %v", err))
 	}
 
+	repairTmpl, err = template.New("repair").Parse(repairTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse repair template: %v", err))
+	}
+
+	repairMode, err = checksum.ParseRepairMode(os.Getenv("CHECKSUM_REPAIR_MODE"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read repair mode: %v", err))
+	}
+	if repairMode != checksum.RepairModeOff {
+		log.Printf("Checksum repair is enabled: %s", repairMode)
+	}
+
+	if value := os.Getenv("REPLICA_CHECKS"); value != "" {
+		checkReplicas, err = strconv.ParseBool(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read replica checks: %v", err))
+		}
+	}
+
+	if value := os.Getenv("REPLICA_RESTORES"); value != "" {
+		restoreReplicas, err = strconv.ParseBool(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read replica restores: %v", err))
+		}
+	}
+	if restoreReplicas && !checkReplicas {
+		log.Printf("Replica restores are ignored: replica checks are not enabled")
+	}
+	if repairMode != checksum.RepairModeOff && !checkReplicas {
+		log.Printf("Checksum repair is refused for every object: replica checks are not enabled")
+	}
+
+	replicaInterval = checksum.DefaultReplicaInterval
+	if value := os.Getenv("REPLICA_CHECK_INTERVAL"); value != "" {
+		replicaInterval, err = db.ParsePeriod(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read replica check interval: %v", err))
+		}
+	}
+
+	budget, err = db.ParseByteBudget(os.Getenv("VERIFICATION_BYTE_BUDGET"), os.Getenv("VERIFICATION_BUDGET_PERIOD"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read verification byte budget: %v", err))
+	}
+
+	budgetTable = os.Getenv("DYNAMODB_BUDGET_TABLE")
+
 	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
 	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	historyTable = os.Getenv("DYNAMODB_HISTORY_TABLE")
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	restoreTable = os.Getenv("DYNAMODB_RESTORE_TABLE")
 	s3Client = s3.NewFromConfig(awsConfig)
+	eventBridge = buckets.NewEventBridgeBuckets(s3Client)
+	fixityPolicies = buckets.NewFixityPolicies(s3Client)
 	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
 	snsClient = sns.NewFromConfig(awsConfig)
 	snsTopicArn = os.Getenv("SNS_TOPIC_ARN")
@@ -69,10 +139,13 @@ func init() {
 }
 
 func handler(ctx context.Context, event events.DynamoDBEvent) error {
-	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable).
+		WithBudget(budgetTable).
+		WithHistory(historyTable).
+		WithRestores(restoreTable)
 
 	for _, record := range event.Records {
-		if !db.IsTTLExpiry(record) {
+		if !db.IsTTLExpiry(record) && !db.IsVerificationRequest(record) {
 			continue
 		}
 
@@ -82,8 +155,45 @@ func handler(ctx context.Context, event events.DynamoDBEvent) error {
 			continue
 		}
 
-		verifier := checksum.NewVerifier(ctx, ddb, s3Client, obj)
+		policy, err := fixityPolicies.Get(ctx, obj.Bucket)
+		if err != nil {
+			log.Printf("Using the default fixity policy for %s: %v", obj.Bucket, err)
+		}
+
+		verifier := checksum.NewVerifier(ctx, ddb, s3Client, obj).
+			WithCheckpointer(checksum.NewS3Checkpointer(s3Client, managedBucketName)).
+			WithPolicy(policy)
+		if checkReplicas {
+			verifier.WithReplica().WithReplicaInterval(replicaInterval)
+		}
+		if checkReplicas && restoreReplicas {
+			// A restore completes with an EventBridge event of the replication bucket
+			replicaBucket := checksum.ReplicaObject(obj).Bucket
+			if enabled, err := eventBridge.Ensure(ctx, replicaBucket); err != nil {
+				log.Printf("Unable to enable EventBridge notifications on %s: %v", replicaBucket, err)
+			} else if enabled {
+				log.Printf("Enabled EventBridge notifications on %s", replicaBucket)
+			}
+			verifier.WithRestore()
+		}
+		if repairMode != checksum.RepairModeOff {
+			// Corrupted primaries are only reported unless repair is enabled
+			verifier.WithRepair(repairMode)
+		}
+		if budgetTable != "" {
+			verificationBudget := budget
+			if db.IsVerificationRequest(record) {
+				// Verifications requested on demand are counted but not bound by the byte budget
+				verificationBudget.Bytes = 0
+			}
+			verifier.WithBudget(verificationBudget)
+		}
+
 		ok, err := verifier.Verify()
+		if verifier.Deferred() {
+			log.Printf("Checksum verification deferred: %s", obj.URI())
+			continue
+		}
 		if err != nil {
 			// This indicates we failed to access or update the database or schedule the next check
 			log.Printf("Failure processing checksum verification: %s", err.Error())
@@ -97,6 +207,7 @@ func handler(ctx context.Context, event events.DynamoDBEvent) error {
 				Title:        fmt.Sprintf("DuraCloud Checksum Processing Failure: %s/%s", obj.Bucket, obj.Key),
 				Template:     notificationTmpl,
 				Topic:        snsTopicArn,
+				VersionId:    obj.VersionId,
 			}
 
 			if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
@@ -113,17 +224,43 @@ func handler(ctx context.Context, event events.DynamoDBEvent) error {
 				Bucket:       obj.Bucket,
 				Object:       obj.Key,
 				Date:         record.Change.ApproximateCreationDateTime.String(),
+				Diverged:     db.Diverged(verifier.FailureCategory(), verifier.ReplicaStatus()),
 				ErrorMessage: "First notice of checksum verification failure.",
+				Failure:      verifier.FailureCategory(),
+				Replica:      verifier.ReplicaStatus(),
 				Stack:        stackName,
 				Title:        fmt.Sprintf("DuraCloud Checksum Verification Failure (1): %s", obj.URI()),
 				Template:     notificationTmpl,
 				Topic:        snsTopicArn,
+				VersionId:    obj.VersionId,
 			}
 
 			if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
 				log.Printf("Failed to send checksum failure notification: %v", err)
 			}
 		}
+
+		if repair := verifier.Repair(); repair.Status != "" {
+			notification := notifications.ChecksumRepairNotification{
+				Account:         accountID,
+				Bucket:          obj.Bucket,
+				Object:          obj.Key,
+				Date:            time.Now().Format(time.RFC3339),
+				RepairMessage:   repair.Message,
+				RepairVersionId: repair.VersionId,
+				Replica:         checksum.ReplicaObject(obj).URI(),
+				Stack:           stackName,
+				Status:          repair.Status,
+				Title:           fmt.Sprintf("DuraCloud Checksum Repair (%s): %s", repair.Status, obj.URI()),
+				Template:        repairTmpl,
+				Topic:           snsTopicArn,
+				VersionId:       obj.VersionId,
+			}
+
+			if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
+				log.Printf("Failed to send checksum repair notification: %v", err)
+			}
+		}
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"duracloud/internal/files"
 	"duracloud/internal/queues"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -22,6 +23,7 @@ var (
 	bucketPrefix   string
 	checksumTable  string
 	dynamodbClient *dynamodb.Client
+	historyTable   string
 	schedulerTable string
 )
 
@@ -39,6 +41,7 @@ func init() {
 	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
 	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
 	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	historyTable = os.Getenv("DYNAMODB_HISTORY_TABLE")
 	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
 }
 
@@ -54,7 +57,7 @@ func handler(ctx context.Context, event json.RawMessage) (events.SQSEventRespons
 	}
 
 	parsedEvents, failedEvents := sqsEventWrapper.UnwrapS3EventBridgeEvents()
-	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable).WithHistory(historyTable)
 
 	for _, parsedEvent := range parsedEvents {
 		if parsedEvent.BucketPrefix() != bucketPrefix {
@@ -67,17 +70,21 @@ func handler(ctx context.Context, event json.RawMessage) (events.SQSEventRespons
 			continue
 		}
 
-		obj := files.NewS3Object(parsedEvent.BucketName(), parsedEvent.ObjectKey())
-		log.Printf("Processing delete event for bucket name: %s, object key: %s", obj.Bucket, obj.Key)
-
-		if err := ddb.Delete(obj); err != nil {
-			_, checkErr := ddb.Get(obj)
-			if checkErr == nil {
-				// Record exists, so we should retry processing
-				failedEvents = append(failedEvents, events.SQSBatchItemFailure{
-					ItemIdentifier: parsedEvent.MessageId,
-				})
-			}
+		obj := files.NewS3ObjectVersion(parsedEvent.BucketName(), parsedEvent.ObjectKey(), parsedEvent.VersionId())
+		if parsedEvent.IsDeleteMarkerCreated() {
+			// The versions are retained (and verified) until the lifecycle rule expires them
+			log.Printf("Delete marker created for %s, retaining checksum records", obj.URI())
+			continue
+		}
+
+		log.Printf("Processing %s event for bucket name: %s, object key: %s, version id: %s",
+			parsedEvent.DetailType, obj.Bucket, obj.Key, obj.VersionId)
+
+		sequencer, message := deletion(parsedEvent.S3EventBridgeEvent)
+		if retry := deleteVersion(ddb, obj, sequencer, message); retry {
+			failedEvents = append(failedEvents, events.SQSBatchItemFailure{
+				ItemIdentifier: parsedEvent.MessageId,
+			})
 		}
 	}
 
@@ -88,6 +95,47 @@ func handler(ctx context.Context, event json.RawMessage) (events.SQSEventRespons
 	}, nil
 }
 
+// deletion returns the sequencer that orders a delete event and how the version was deleted
+func deletion(event queues.S3EventBridgeEvent) (string, string) {
+	if event.IsLifecycleExpiration() {
+		// A version only expires once it is noncurrent, no later event can write its record
+		return "", "object version expired by the lifecycle rule"
+	}
+	return event.Sequencer(), "object version permanently deleted"
+}
+
+// deleteVersion removes the checksum records of a permanently deleted (or expired) object
+// version and records the deletion in the fixity history, it reports whether the event should
+// be retried
+func deleteVersion(store db.ChecksumStore, obj files.S3Object, sequencer, message string) bool {
+	err := store.Delete(obj, sequencer)
+	if errors.Is(err, db.ErrStaleSequencer) {
+		// The object was uploaded again after this delete and its deposit already processed
+		log.Printf("Ignoring out of order delete of %s: %v", obj.URI(), err)
+		return false
+	}
+	if err != nil {
+		// Record exists, so we should retry processing
+		_, checkErr := store.Get(obj)
+		return checkErr == nil
+	}
+
+	err = db.RecordEvent(store, db.FixityEvent{
+		BucketName: obj.Bucket,
+		ObjectKey:  obj.Key,
+		VersionId:  obj.VersionId,
+		EventType:  db.FixityEventDeletion,
+		Message:    message,
+		Success:    true,
+	})
+	if err != nil {
+		// The records are gone so a retry would not reach this point again
+		log.Printf("Failed to record deletion of %s in history: %v", obj.URI(), err)
+	}
+
+	return false
+}
+
 func main() {
 	lambda.Start(handler)
 }
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/queues"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// lifecycleExpiration is the event S3 sends when the lifecycle rule expires a noncurrent version
+const lifecycleExpiration = `{
+  "version": "0",
+  "id": "2ee9cc15-d022-99ea-1fb8-1b1bac4850f9",
+  "detail-type": "Lifecycle Expiration",
+  "source": "aws.s3",
+  "account": "123456789012",
+  "time": "2025-06-03T00:00:00Z",
+  "region": "us-east-1",
+  "resources": ["arn:aws:s3:::stack-bucket"],
+  "detail": {
+    "version": "0",
+    "bucket": {"name": "stack-bucket"},
+    "object": {
+      "key": "file.txt",
+      "size": 5,
+      "etag": "b1946ac92492d2347c6235b4d2611184",
+      "version-id": "v1",
+      "sequencer": "00617F08299329D189"
+    },
+    "request-id": "20EB74C14654DC47",
+    "requester": "s3.amazonaws.com",
+    "deletion-type": "Permanently Deleted"
+  }
+}`
+
+func TestLifecycleExpiration(t *testing.T) {
+	wrapper := queues.SQSEventWrapper{Event: &events.SQSEvent{
+		Records: []events.SQSMessage{{MessageId: "message-1", Body: lifecycleExpiration}},
+	}}
+	parsed, failed := wrapper.UnwrapS3EventBridgeEvents()
+	if len(parsed) != 1 || len(failed) != 0 {
+		t.Fatalf("Expected 1 parsed event, got %d (%d failed)", len(parsed), len(failed))
+	}
+
+	event := parsed[0]
+	if !event.IsObjectDeleted() || !event.IsLifecycleExpiration() || event.IsDeleteMarkerCreated() {
+		t.Errorf("Expected the expiry of a version, got %+v", event)
+	}
+
+	store := db.NewMemoryStore()
+	obj := files.NewS3ObjectVersion(event.BucketName(), event.ObjectKey(), event.VersionId())
+	record := db.ChecksumRecord{
+		BucketName:          obj.Bucket,
+		ObjectKey:           obj.Key,
+		VersionId:           obj.VersionId,
+		Checksum:            "b1946ac92492d2347c6235b4d2611184",
+		LastChecksumSuccess: true,
+		// Sequencers of lifecycle events are not ordered with those of the object's requests
+		Sequencer: "00FFFFFFFFFFFFFFFF",
+	}
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Schedule(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sequencer, message := deletion(event.S3EventBridgeEvent)
+	if retry := deleteVersion(store, obj, sequencer, message); retry {
+		t.Errorf("Expected the expiry to be processed")
+	}
+
+	if _, err := store.Get(obj); !errors.Is(err, db.ErrChecksumRecordNotFound) {
+		t.Errorf("Expected the record of the expired version to be deleted, got %v", err)
+	}
+	history, _ := store.History(obj)
+	if len(history) != 1 || history[0].EventType != db.FixityEventDeletion || history[0].Message != message {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
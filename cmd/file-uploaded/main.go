@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"duracloud/internal/buckets"
 	"duracloud/internal/checksum"
 	"duracloud/internal/db"
 	"duracloud/internal/files"
@@ -21,11 +22,15 @@ import (
 )
 
 var (
-	bucketPrefix   string
-	checksumTable  string
-	dynamodbClient *dynamodb.Client
-	s3Client       *s3.Client
-	schedulerTable string
+	bucketPrefix      string
+	checksumTable     string
+	dynamodbClient    *dynamodb.Client
+	fixityPolicies    *buckets.FixityPolicies
+	historyTable      string
+	legacyTable       string
+	managedBucketName string
+	s3Client          *s3.Client
+	schedulerTable    string
 )
 
 func init() {
@@ -42,7 +47,11 @@ func init() {
 	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
 	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
 	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	historyTable = os.Getenv("DYNAMODB_HISTORY_TABLE")
+	legacyTable = os.Getenv("DYNAMODB_LEGACY_TABLE")
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
 	s3Client = s3.NewFromConfig(awsConfig)
+	fixityPolicies = buckets.NewFixityPolicies(s3Client)
 	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
 }
 
@@ -58,7 +67,7 @@ func handler(ctx context.Context, event json.RawMessage) (events.SQSEventRespons
 	}
 
 	parsedEvents, failedEvents := sqsEventWrapper.UnwrapS3EventBridgeEvents()
-	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable).WithHistory(historyTable).WithLegacy(legacyTable)
 
 	for _, parsedEvent := range parsedEvents {
 		if parsedEvent.BucketPrefix() != bucketPrefix {
@@ -71,10 +80,19 @@ func handler(ctx context.Context, event json.RawMessage) (events.SQSEventRespons
 			continue
 		}
 
-		obj := files.NewS3Object(parsedEvent.BucketName(), parsedEvent.ObjectKey())
-		log.Printf("Processing upload event for bucket name: %s, object key: %s", obj.Bucket, obj.Key)
+		obj := files.NewS3ObjectVersion(parsedEvent.BucketName(), parsedEvent.ObjectKey(), parsedEvent.VersionId())
+		log.Printf("Processing upload event for bucket name: %s, object key: %s, version id: %s",
+			obj.Bucket, obj.Key, obj.VersionId)
 
-		verifier := checksum.NewVerifier(ctx, ddb, s3Client, obj)
+		policy, err := fixityPolicies.Get(ctx, obj.Bucket)
+		if err != nil {
+			log.Printf("Using the default fixity policy for %s: %v", obj.Bucket, err)
+		}
+
+		verifier := checksum.NewVerifier(ctx, ddb, s3Client, obj).
+			WithCheckpointer(checksum.NewS3Checkpointer(s3Client, managedBucketName)).
+			WithPolicy(policy).
+			WithSequencer(parsedEvent.Sequencer())
 		if err := verifier.Deposit(parsedEvent.Etag()); err != nil {
 			if files.TryObject(ctx, s3Client, obj) {
 				// Only retry if the uploaded file (still) exists
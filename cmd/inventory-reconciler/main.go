@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/inventory"
+	"duracloud/internal/notifications"
+	"duracloud/internal/queues"
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// reconcileReserve is the time kept back from the Lambda deadline to report the buckets
+// reconciled so far, buckets that are not started in time are reported as skipped
+const reconcileReserve = 2 * time.Minute
+
+var (
+	//go:embed templates/reconciliation-notification.txt
+	reconciliationTemplate string
+
+	accountID          string
+	bucketPrefix       string
+	checksumTable      string
+	dynamodbClient     *dynamodb.Client
+	enqueueDeposits    bool
+	managedBucketName  string
+	objectCreatedQueue string
+	reconciliationTmpl *template.Template
+	s3Client           *s3.Client
+	snsClient          *sns.Client
+	snsTopicArn        string
+	sqsClient          *sqs.Client
+	stackName          string
+)
+
+type ReconcileRequest struct {
+	Bucket string `json:"bucket,omitempty"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	accountID, err = accounts.GetAccountID(context.Background(), awsConfig)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to get AWS account ID: %v", err))
+	}
+
+	reconciliationTmpl, err = template.New("reconciliation").Parse(reconciliationTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse reconciliation template: %v", err))
+	}
+
+	if value := os.Getenv("ENQUEUE_DEPOSITS"); value != "" {
+		enqueueDeposits, err = strconv.ParseBool(value)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to read enqueue deposits: %v", err))
+		}
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	objectCreatedQueue = os.Getenv("SQS_OBJECT_CREATED_URL")
+	s3Client = s3.NewFromConfig(awsConfig)
+	snsClient = sns.NewFromConfig(awsConfig)
+	snsTopicArn = os.Getenv("SNS_TOPIC_ARN")
+	sqsClient = sqs.NewFromConfig(awsConfig)
+	stackName = os.Getenv("STACK_NAME")
+}
+
+func handler(ctx context.Context, request ReconcileRequest) error {
+	// Scheduled runs reconcile every bucket with an inventory
+	bucketNames := []string{request.Bucket}
+	if request.Bucket == "" {
+		var err error
+		bucketNames, err = inventory.InventoryBuckets(ctx, s3Client, managedBucketName)
+		if err != nil {
+			return err
+		}
+	}
+
+	notification := notifications.InventoryReconciliationNotification{
+		Account:  accountID,
+		Date:     time.Now().Format(time.RFC3339),
+		Stack:    stackName,
+		Title:    fmt.Sprintf("DuraCloud Inventory Reconciliation: %s", stackName),
+		Template: reconciliationTmpl,
+		Topic:    snsTopicArn,
+	}
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, "")
+	deadline, hasDeadline := ctx.Deadline()
+
+	for _, bucketName := range bucketNames {
+		if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+			log.Printf("Skipping bucket not tracked by this stack: %s", bucketName)
+			continue
+		}
+
+		if hasDeadline && time.Until(deadline) < reconcileReserve {
+			notification.Buckets = append(notification.Buckets, notifications.InventoryReconciliationSummary{
+				Bucket: bucketName,
+				Error:  "not reconciled before the timeout, invoke the function with this bucket to reconcile it",
+			})
+			continue
+		}
+
+		summary, err := reconcile(ctx, ddb, bucketName)
+		if err != nil {
+			log.Printf("Failed to reconcile inventory of %s: %v", bucketName, err)
+			summary.Error = err.Error()
+		}
+		notification.Buckets = append(notification.Buckets, summary)
+	}
+
+	if len(notification.Buckets) == 0 {
+		log.Println("No buckets with inventories found")
+		return nil
+	}
+
+	return notifications.SendNotification(ctx, snsClient, notification)
+}
+
+func reconcile(ctx context.Context, store inventory.RecordSource, bucketName string) (notifications.InventoryReconciliationSummary, error) {
+	summary := notifications.InventoryReconciliationSummary{Bucket: bucketName}
+
+	inventoryObj, err := inventory.LatestInventory(ctx, s3Client, managedBucketName, bucketName)
+	if err != nil {
+		return summary, err
+	}
+	summary.Inventory = inventoryObj.URI()
+	log.Printf("Reconciling inventory %s", inventoryObj.URI())
+
+	reader, err := files.DownloadObject(ctx, s3Client, inventoryObj, false)
+	if err != nil {
+		return summary, err
+	}
+
+	objects, err := inventory.ReadInventory(reader)
+	_ = reader.Close()
+	if err != nil {
+		return summary, err
+	}
+
+	report, err := inventory.Reconcile(ctx, s3Client, store, bucketName, objects)
+	if err != nil {
+		return summary, err
+	}
+	report.Inventory = inventoryObj
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		return summary, err
+	}
+
+	reportObj := files.NewS3Object(managedBucketName, report.Key())
+	if err := files.UploadObject(ctx, s3Client, reportObj, &buf, "text/csv"); err != nil {
+		return summary, err
+	}
+	log.Printf("Reconciliation report uploaded to %s", reportObj.URI())
+
+	summary.MissingRecords = report.Count(inventory.StatusMissingRecord)
+	summary.Objects = report.Objects
+	summary.OrphanRecords = report.Count(inventory.StatusOrphanRecord)
+	summary.Records = report.Records
+	summary.Report = reportObj.URI()
+	summary.SizeMismatches = report.Count(inventory.StatusSizeMismatch)
+
+	if enqueueDeposits {
+		summary.Enqueued, err = enqueue(ctx, bucketName, report.MissingRecords())
+		if err != nil {
+			return summary, fmt.Errorf("failed to enqueue deposits after %d: %w", summary.Enqueued, err)
+		}
+	}
+
+	return summary, nil
+}
+
+// enqueue sends an object created event for each version missing its record to the queue of
+// the file-uploaded function, it returns the number of deposits enqueued
+func enqueue(ctx context.Context, bucketName string, missing []inventory.Discrepancy) (int, error) {
+	enqueued := 0
+
+	for batch := range slices.Chunk(missing, queues.MaxBatchSize) {
+		objects := make([]queues.CreatedObject, 0, len(batch))
+		for _, discrepancy := range batch {
+			objects = append(objects, queues.CreatedObject{
+				Object: files.NewS3ObjectVersion(bucketName, discrepancy.Key, discrepancy.VersionId),
+				ETag:   discrepancy.ETag,
+			})
+		}
+
+		failed, err := queues.SendObjectCreated(ctx, sqsClient, objectCreatedQueue, objects)
+		if err != nil {
+			return enqueued, err
+		}
+		enqueued += len(objects) - len(failed)
+	}
+
+	log.Printf("Enqueued %d deposits of %d versions missing their record in %s", enqueued, len(missing), bucketName)
+	return enqueued, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
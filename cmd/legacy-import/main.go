@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/legacy"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	bucketPrefix      string
+	dynamodbClient    *dynamodb.Client
+	legacyTable       string
+	managedBucketName string
+	s3Client          *s3.Client
+)
+
+type ImportRequest struct {
+	Key      string `json:"key"`
+	StartRow int    `json:"startRow,omitempty"`
+}
+
+type ImportResponse struct {
+	legacy.Result
+	Message string `json:"message"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	legacyTable = os.Getenv("DYNAMODB_LEGACY_TABLE")
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	s3Client = s3.NewFromConfig(awsConfig)
+}
+
+func handler(ctx context.Context, request ImportRequest) (ImportResponse, error) {
+	if !strings.HasPrefix(request.Key, legacy.ImportPrefix) {
+		return ImportResponse{}, fmt.Errorf("legacy import must be uploaded to %s in the managed bucket: %s",
+			legacy.ImportPrefix, request.Key)
+	}
+
+	format, err := legacy.FormatOf(request.Key)
+	if err != nil {
+		return ImportResponse{}, err
+	}
+
+	startRow := max(request.StartRow, 1)
+	obj := files.NewS3Object(managedBucketName, request.Key)
+	log.Printf("Importing legacy checksums from %s starting at row %d", obj.URI(), startRow)
+
+	reader, err := files.DownloadObject(ctx, s3Client, obj, false)
+	if err != nil {
+		return ImportResponse{}, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	ddb := db.NewDB(ctx, dynamodbClient, "", "").WithLegacy(legacyTable)
+	result, err := legacy.NewImporter(ctx, ddb, bucketPrefix, request.Key).Import(reader, format, startRow)
+	if err != nil {
+		// Staging is idempotent so the rows before the failure can be imported again
+		return ImportResponse{}, fmt.Errorf("failed to import %s after %d rows from row %d: %w",
+			obj.URI(), result.Read, startRow, err)
+	}
+
+	for _, rowErr := range result.Errors {
+		log.Printf("Skipped invalid row of %s: %s", obj.URI(), rowErr)
+	}
+
+	message := fmt.Sprintf("Imported %s: staged %d of %d rows (%d invalid, %d already deposited)",
+		obj.URI(), result.Staged, result.Read, result.Invalid, result.Late)
+	if !result.Complete {
+		message = fmt.Sprintf("%s, invoke again with startRow %d to continue", message, result.NextRow)
+	}
+	log.Println(message)
+
+	return ImportResponse{Result: result, Message: message}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
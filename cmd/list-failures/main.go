@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+var (
+	bucketPrefix   string
+	checksumTable  string
+	dynamodbClient *dynamodb.Client
+)
+
+type FailuresRequest struct {
+	Bucket   string `json:"bucket"`
+	Index    bool   `json:"index,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+	StartKey string `json:"startKey,omitempty"`
+}
+
+type Failure struct {
+	Category       string `json:"category,omitempty"`
+	Key            string `json:"key"`
+	LastChecked    string `json:"lastChecked"`
+	Message        string `json:"message"`
+	ReplicaMessage string `json:"replicaMessage,omitempty"`
+	ReplicaStatus  string `json:"replicaStatus,omitempty"`
+	VersionId      string `json:"versionId,omitempty"`
+}
+
+type FailuresResponse struct {
+	Bucket   string    `json:"bucket"`
+	Complete bool      `json:"complete"`
+	Failures []Failure `json:"failures,omitempty"`
+	Indexed  int       `json:"indexed,omitempty"`
+	Message  string    `json:"message"`
+	NextKey  string    `json:"nextKey,omitempty"`
+}
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+}
+
+func handler(ctx context.Context, request FailuresRequest) (FailuresResponse, error) {
+	bucketName := request.Bucket
+	if buckets.GetBucketPrefix(bucketName) != bucketPrefix || buckets.IsIgnoreFilesBucket(bucketName) {
+		return FailuresResponse{}, fmt.Errorf("bucket is not tracked by this stack: %s", bucketName)
+	}
+
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, "")
+
+	if request.Index {
+		log.Printf("Indexing failing objects of %s from key: %q", bucketName, request.StartKey)
+
+		result, err := ddb.IndexFailures(bucketName, request.StartKey)
+		if err != nil {
+			return FailuresResponse{}, fmt.Errorf("failed to index failures of %s after %d objects: %w",
+				bucketName, result.Indexed, err)
+		}
+
+		message := fmt.Sprintf("Indexed %d failing objects in %s", result.Indexed, bucketName)
+		if !result.Complete {
+			message += ", invoke again with the next key to continue"
+		}
+		log.Println(message)
+
+		return FailuresResponse{
+			Bucket:   bucketName,
+			Complete: result.Complete,
+			Indexed:  result.Indexed,
+			Message:  message,
+			NextKey:  result.NextKey,
+		}, nil
+	}
+
+	page, err := ddb.ListFailures(bucketName, db.Page{Limit: request.Limit, StartKey: request.StartKey})
+	if err != nil {
+		return FailuresResponse{}, fmt.Errorf("failed to list failures of %s: %w", bucketName, err)
+	}
+
+	failures := make([]Failure, 0, len(page.Records))
+	for _, record := range page.Records {
+		failures = append(failures, Failure{
+			Category:       record.FailureCategory,
+			Key:            record.ObjectKey,
+			LastChecked:    record.LastChecksumDate.Format(time.RFC3339),
+			Message:        record.LastChecksumMessage,
+			ReplicaMessage: record.ReplicaMessage,
+			ReplicaStatus:  record.ReplicaStatus,
+			VersionId:      record.VersionId,
+		})
+	}
+
+	message := fmt.Sprintf("Listed %d failing objects in %s", len(failures), bucketName)
+	if page.NextKey != "" {
+		message += ", invoke again with the next key to continue"
+	}
+	log.Println(message)
+
+	return FailuresResponse{
+		Bucket:   bucketName,
+		Complete: page.NextKey == "",
+		Failures: failures,
+		Message:  message,
+		NextKey:  page.NextKey,
+	}, nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/manifests"
+	"duracloud/internal/notifications"
+	"duracloud/internal/queues"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+var (
+	//go:embed templates/reconciliation-notification.txt
+	reconciliationTemplate string
+
+	accountID          string
+	bucketPrefix       string
+	checksumTable      string
+	dynamodbClient     *dynamodb.Client
+	managedBucketName  string
+	reconciliationTmpl *template.Template
+	s3Client           *s3.Client
+	schedulerTable     string
+	snsClient          *sns.Client
+	snsTopicArn        string
+	stackName          string
+)
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	accountID, err = accounts.GetAccountID(context.Background(), awsConfig)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to get AWS account ID: %v", err))
+	}
+
+	reconciliationTmpl, err = template.New("reconciliation").Parse(reconciliationTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to parse reconciliation template: %v", err))
+	}
+
+	bucketPrefix = os.Getenv("S3_BUCKET_PREFIX")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	s3Client = s3.NewFromConfig(awsConfig)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+	snsClient = sns.NewFromConfig(awsConfig)
+	snsTopicArn = os.Getenv("SNS_TOPIC_ARN")
+	stackName = os.Getenv("STACK_NAME")
+}
+
+func handler(ctx context.Context, event json.RawMessage) (events.SQSEventResponse, error) {
+	var sqsEvent events.SQSEvent
+	if err := json.Unmarshal(event, &sqsEvent); err != nil {
+		log.Printf("Failed to parse SQS event: %v", err)
+		return events.SQSEventResponse{}, nil
+	}
+
+	// Manifests are reconciled again while the objects they list are being deposited
+	attempts := make(map[string]int, len(sqsEvent.Records))
+	for _, record := range sqsEvent.Records {
+		attempts[record.MessageId], _ = strconv.Atoi(record.Attributes["ApproximateReceiveCount"])
+	}
+
+	sqsEventWrapper := queues.SQSEventWrapper{
+		Event: &sqsEvent,
+	}
+
+	parsedEvents, failedEvents := sqsEventWrapper.UnwrapS3EventBridgeEvents()
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+
+	for _, parsedEvent := range parsedEvents {
+		if parsedEvent.BucketPrefix() != bucketPrefix {
+			continue
+		}
+
+		if !parsedEvent.IsObjectCreated() ||
+			parsedEvent.IsIgnoreFilesBucket() ||
+			!manifests.IsManifest(parsedEvent.ObjectKey()) {
+			continue
+		}
+
+		obj := files.NewS3ObjectVersion(parsedEvent.BucketName(), parsedEvent.ObjectKey(), parsedEvent.VersionId())
+		log.Printf("Processing manifest for bucket name: %s, object key: %s, version id: %s",
+			obj.Bucket, obj.Key, obj.VersionId)
+
+		if err := reconcile(ctx, ddb, obj, attempts[parsedEvent.MessageId]); err != nil {
+			log.Printf("Failed to reconcile manifest %s: %v", obj.URI(), err)
+			if files.TryObject(ctx, s3Client, obj) {
+				// Only retry if the manifest (still) exists
+				failedEvents = append(failedEvents, events.SQSBatchItemFailure{
+					ItemIdentifier: parsedEvent.MessageId,
+				})
+			}
+		}
+	}
+
+	log.Printf("Finished processing manifest events. Failed events: %d", len(failedEvents))
+
+	return events.SQSEventResponse{
+		BatchItemFailures: failedEvents,
+	}, nil
+}
+
+func reconcile(ctx context.Context, store db.ChecksumStore, obj files.S3Object, attempt int) error {
+	notification := notifications.ManifestReconciliationNotification{
+		Account:  accountID,
+		Bucket:   obj.Bucket,
+		Date:     time.Now().Format(time.RFC3339),
+		Manifest: obj.Key,
+		Stack:    stackName,
+		Title:    fmt.Sprintf("DuraCloud Manifest Reconciliation: %s", obj.URI()),
+		Template: reconciliationTmpl,
+		Topic:    snsTopicArn,
+	}
+
+	manifest, err := manifests.Load(ctx, s3Client, obj)
+	if errors.Is(err, manifests.ErrInvalidManifest) ||
+		errors.Is(err, manifests.ErrUnsupportedAlgorithm) ||
+		errors.Is(err, manifests.ErrUnsupportedManifest) {
+		// Retrying will not fix the manifest so the depositor is told instead
+		notification.Error = err.Error()
+		return notifications.SendNotification(ctx, snsClient, notification)
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := manifests.Reconcile(ctx, s3Client, store, manifest)
+	if err != nil {
+		return err
+	}
+
+	pending := report.Count(manifests.StatusPending)
+	if pending > 0 && attempt < manifests.ReconcileAttempts {
+		return fmt.Errorf("%d objects pending deposit (attempt %d of %d)",
+			pending, attempt, manifests.ReconcileAttempts)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		return err
+	}
+
+	reportObj := files.NewS3Object(managedBucketName, report.Key())
+	if err := files.UploadObject(ctx, s3Client, reportObj, &buf, "text/csv"); err != nil {
+		return err
+	}
+	log.Printf("Reconciliation report uploaded to %s", reportObj.URI())
+
+	notification.Extra = report.Count(manifests.StatusExtra)
+	notification.Matched = report.Matched
+	notification.Mismatched = report.Count(manifests.StatusMismatched)
+	notification.Missing = report.Count(manifests.StatusMissing)
+	notification.Objects = report.Objects
+	notification.Pending = pending
+	notification.Report = reportObj.URI()
+
+	if err := notifications.SendNotification(ctx, snsClient, notification); err != nil {
+		// The report was written so the manifest is not reconciled again
+		log.Printf("Failed to send manifest reconciliation notification: %v", err)
+	}
+
+	return nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"duracloud/internal/db"
 	"duracloud/internal/reports"
 	"duracloud/internal/templates"
 	_ "embed"
@@ -15,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -22,6 +24,10 @@ var (
 	//go:embed templates/storage-report.html
 	storageReportTemplate string
 
+	budget            db.ByteBudget
+	budgetTable       string
+	checksumTable     string
+	dynamodbClient    *dynamodb.Client
 	managedBucketName string
 	s3Client          *s3.Client
 	stackName         string
@@ -45,6 +51,14 @@ func init() {
 		panic(fmt.Sprintf("Failed to parse storage report template: %v", err))
 	}
 
+	budget, err = db.ParseByteBudget(os.Getenv("VERIFICATION_BYTE_BUDGET"), os.Getenv("VERIFICATION_BUDGET_PERIOD"))
+	if err != nil {
+		panic(fmt.Sprintf("Unable to read verification byte budget: %v", err))
+	}
+
+	budgetTable = os.Getenv("DYNAMODB_BUDGET_TABLE")
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
 	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
 	stackName = os.Getenv("STACK_NAME")
 	s3Client = s3.NewFromConfig(awsConfig)
@@ -55,6 +69,21 @@ func handler(ctx context.Context) error {
 
 	generator := reports.NewStorageReportGenerator(s3Client, stackName, managedBucketName)
 
+	if checksumTable != "" {
+		generator.WithFailures(db.NewDB(ctx, dynamodbClient, checksumTable, ""))
+	}
+
+	if budgetTable != "" {
+		// Verification usage for the week up to the report
+		since := budget.Window(time.Now().AddDate(0, 0, -7))
+		usage, err := db.NewDB(ctx, dynamodbClient, "", "").WithBudget(budgetTable).BudgetUsage(budget.Period, since)
+		if err != nil {
+			log.Printf("Warning: failed to get verification usage: %v", err)
+		} else {
+			generator.WithVerificationUsage(budget, usage)
+		}
+	}
+
 	reportHTML, err := generator.GenerateReport(ctx, storageReportTmpl)
 	if err != nil {
 		return fmt.Errorf("failed to generate report: %w", err)
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/queues"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var (
+	awsCtx            accounts.AWSContext
+	checksumTable     string
+	dynamodbClient    *dynamodb.Client
+	managedBucketName string
+	s3Client          *s3.Client
+	schedulerTable    string
+	targetLimit       int
+)
+
+func init() {
+	awsConfig, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.AddWithMaxAttempts(
+				retry.NewStandard(), 5)
+		}),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to load AWS config: %v", err))
+	}
+
+	accountID, err := accounts.GetAccountID(context.Background(), awsConfig)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to get AWS account ID: %v", err))
+	}
+
+	targetLimit, err = buckets.GetBucketRequestLimit(os.Getenv("S3_MAX_VERIFY_TARGETS_PER_REQUEST"))
+	if err != nil {
+		log.Printf("Invalid S3_MAX_VERIFY_TARGETS_PER_REQUEST, using default: %v", err)
+		targetLimit = buckets.DefaultVerifyRequestLimit
+	}
+
+	checksumTable = os.Getenv("DYNAMODB_CHECKSUM_TABLE")
+	dynamodbClient = dynamodb.NewFromConfig(awsConfig)
+	managedBucketName = os.Getenv("S3_MANAGED_BUCKET")
+	s3Client = s3.NewFromConfig(awsConfig)
+	schedulerTable = os.Getenv("DYNAMODB_SCHEDULER_TABLE")
+
+	awsCtx = accounts.AWSContext{
+		AccountID: accountID,
+		Region:    awsConfig.Region,
+		StackName: os.Getenv("S3_BUCKET_PREFIX"),
+	}
+}
+
+func handler(ctx context.Context, event queues.S3EventBridgeEvent) error {
+	ctx = context.WithValue(ctx, accounts.AWSContextKey, awsCtx)
+	if !event.IsObjectCreated() ||
+		!buckets.IsBucketRequestBucket(event.BucketName()) ||
+		!buckets.IsVerifyRequest(event.ObjectKey()) {
+		log.Printf("Ignoring %s event for bucket: %s", event.DetailType, event.BucketName())
+		return nil
+	}
+
+	obj := files.NewS3Object(event.BucketName(), event.ObjectKey())
+	log.Printf("Received verification request for bucket name: %s, object key: %s", obj.Bucket, obj.Key)
+
+	targetsStatus := make(map[string]string)
+	targets, err := buckets.GetVerifyTargets(ctx, s3Client, obj, targetLimit)
+	if err != nil {
+		targetsStatus[buckets.VerifyRequestedFileErrorKey] = err.Error()
+		_ = buckets.WriteVerifyStatus(ctx, s3Client, managedBucketName, targetsStatus)
+		return fmt.Errorf("could not retrieve verification targets: %v", err)
+	}
+
+	log.Printf("Retrieved %d verification targets from request file", len(targets))
+
+	// The request is recorded on the scheduler entries so the verification can be traced to it
+	requestId := fmt.Sprintf("%s@%s", obj.URI(), time.Now().UTC().Format(time.RFC3339))
+	ddb := db.NewDB(ctx, dynamodbClient, checksumTable, schedulerTable)
+
+	for _, target := range targets {
+		result, err := ddb.RequestVerifications(target.Bucket, target.Key, !target.IsPrefix(), requestId, "")
+		var status string
+		switch {
+		case err != nil:
+			status = fmt.Sprintf("Failed after %d verifications requested: %v", result.Requested, err)
+		case !result.Complete:
+			status = fmt.Sprintf("Incomplete, %d verifications requested (%d skipped) up to %s",
+				result.Requested, result.Skipped, result.NextKey)
+		case result.Requested+result.Skipped == 0:
+			status = "No deposited objects found"
+		default:
+			status = fmt.Sprintf("%d verifications requested (%d skipped, already pending)",
+				result.Requested, result.Skipped)
+		}
+
+		log.Printf("Verification target status: %s %s", target, status)
+		targetsStatus[target.String()] = status
+	}
+
+	err = buckets.WriteVerifyStatus(ctx, s3Client, managedBucketName, targetsStatus)
+	if err != nil {
+		return fmt.Errorf("could not write verification status to managed bucket: %v", err)
+	}
+
+	log.Printf("Successfully processed verification request for bucket name: %s, object key: %s", obj.Bucket, obj.Key)
+
+	return nil
+}
+
+func main() {
+	lambda.Start(handler)
+}
@@ -8,10 +8,12 @@ import (
 	"duracloud/internal/files"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
@@ -112,6 +114,31 @@ func GetBuckets(ctx context.Context, s3Client *s3.Client, obj files.S3Object, li
 	return buckets, nil
 }
 
+// StackBuckets returns the buckets of a stack that hold content (not the managed, logs, replication
+// or bucket request buckets), the buckets whose names start with the stack prefix
+func StackBuckets(ctx context.Context, s3Client s3.ListBucketsAPIClient, prefix string) ([]string, error) {
+	var buckets []string
+
+	paginator := s3.NewListBucketsPaginator(s3Client, &s3.ListBucketsInput{
+		Prefix: aws.String(prefix + "-"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, ErrorListingBuckets(prefix, err)
+		}
+
+		for _, bucket := range page.Buckets {
+			name := aws.ToString(bucket.Name)
+			if GetBucketPrefix(name) == prefix && !IsIgnoreFilesBucket(name) {
+				buckets = append(buckets, name)
+			}
+		}
+	}
+
+	return buckets, nil
+}
+
 func HasReservedPrefix(name string) bool {
 	for _, prefix := range ReservedPrefixes {
 		if strings.HasPrefix(name, prefix) {
@@ -189,15 +216,26 @@ func ValidateBucketName(ctx context.Context, name string) bool {
 }
 
 func WriteStatus(ctx context.Context, s3Client *s3.Client, bucketName string, log map[string]string) error {
+	return writeLog(ctx, s3Client, bucketName, "bucket-request-log", log)
+}
+
+// writeLog writes a "[name] status" line per entry to logs/ in the managed bucket
+func writeLog(ctx context.Context, s3Client *s3.Client, bucketName string, logName string, log map[string]string) error {
+	names := make([]string, 0, len(log))
+	for name := range log {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	var builder strings.Builder
-	for bucket, status := range log {
-		builder.WriteString(fmt.Sprintf("[%s] %s\n", bucket, status))
+	for _, name := range names {
+		builder.WriteString(fmt.Sprintf("[%s] %s\n", name, log[name]))
 	}
 	logContent := builder.String()
 	reader := bytes.NewReader([]byte(logContent))
 	now := time.Now().UTC()
 	timestamp := now.Format(time.RFC3339)
-	key := fmt.Sprintf("logs/bucket-request-log-%s.txt", timestamp)
+	key := fmt.Sprintf("logs/%s-%s.txt", logName, timestamp)
 	obj := files.NewS3Object(bucketName, key)
 
 	err := files.UploadObject(ctx, s3Client, obj, reader, "text/plain")
@@ -3,8 +3,13 @@ package buckets
 import (
 	"context"
 	"duracloud/internal/accounts"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 // createTestContext creates a context with AWS context for testing
@@ -403,3 +408,38 @@ func TestGetBucketPrefix(t *testing.T) {
 		})
 	}
 }
+
+type mockListBucketsClient struct {
+	names []string
+}
+
+func (m *mockListBucketsClient) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	var output s3.ListBucketsOutput
+	for _, name := range m.names {
+		if strings.HasPrefix(name, aws.ToString(params.Prefix)) {
+			output.Buckets = append(output.Buckets, types.Bucket{Name: aws.String(name)})
+		}
+	}
+	return &output, nil
+}
+
+func TestStackBuckets(t *testing.T) {
+	client := &mockListBucketsClient{names: []string{
+		"duracloud-test-bucket-requested",
+		"duracloud-test-logs",
+		"duracloud-test-managed",
+		"duracloud-test-photos",
+		"duracloud-test-photos-repl",
+		"duracloud-test-videos",
+		"duracloud-testing-photos",
+		"other-test-photos",
+	}}
+
+	names, err := StackBuckets(context.Background(), client, "duracloud-test")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(names, []string{"duracloud-test-photos", "duracloud-test-videos"}) {
+		t.Errorf("Expected the content buckets of the stack, got %v", names)
+	}
+}
@@ -22,11 +22,17 @@ var (
 	ErrBucketStatusUploadFailed     = errors.New("failed to write bucket status")
 	ErrDeletingBucketPolicy         = errors.New("failed to delete bucket policy")
 	ErrExceededMaxBucketsPerRequest = errors.New("exceeded maximum allowed buckets per request")
+	ErrExceededMaxVerifyTargets     = errors.New("exceeded maximum allowed verification targets per request")
 	ErrInvalidBucketName            = errors.New("invalid bucket name requested")
+	ErrInvalidFixityPolicy          = errors.New("invalid fixity policy")
+	ErrInvalidVerifyTarget          = errors.New("invalid verification target requested")
+	ErrListingBuckets               = errors.New("failed to list buckets")
 	ErrMarshallingBucketPolicy      = errors.New("failed to marshal bucket policy")
 	ErrMarshallingPolicy            = errors.New("failed to marshal policy")
 	ErrReadingMaxBucketsPerRequest  = errors.New("unable to read max buckets per request variable")
 	ErrReadingResponse              = errors.New("error reading response")
+	ErrRetrievingBucketTags         = errors.New("failed to get bucket tags")
+	ErrRetrievingNotifications      = errors.New("failed to get bucket notification configuration")
 	ErrRetrievingObject             = errors.New("failed to get object")
 )
 
@@ -94,10 +100,26 @@ func ErrorExceededMaxBucketsPerRequest(limit, requested int) error {
 	return fmt.Errorf("%w: limit=%d requested=%d", ErrExceededMaxBucketsPerRequest, limit, requested)
 }
 
+func ErrorExceededMaxVerifyTargetsPerRequest(limit, requested int) error {
+	return fmt.Errorf("%w: limit=%d requested=%d", ErrExceededMaxVerifyTargets, limit, requested)
+}
+
 func ErrorInvalidBucketName(bucketName string) error {
 	return fmt.Errorf("%w: bucket=%s", ErrInvalidBucketName, bucketName)
 }
 
+func ErrorInvalidFixityPolicy(bucketName string, cause error) error {
+	return fmt.Errorf("%w: bucket=%s cause=%v", ErrInvalidFixityPolicy, bucketName, cause)
+}
+
+func ErrorInvalidVerifyTarget(target string) error {
+	return fmt.Errorf("%w: target=%s", ErrInvalidVerifyTarget, target)
+}
+
+func ErrorListingBuckets(prefix string, cause error) error {
+	return fmt.Errorf("%w: prefix=%s cause=%v", ErrListingBuckets, prefix, cause)
+}
+
 func ErrorMarshallingBucketPolicy(cause error) error {
 	return fmt.Errorf("%w: cause=%v", ErrMarshallingBucketPolicy, cause)
 }
@@ -114,6 +136,14 @@ func ErrorReadingResponse(cause error) error {
 	return fmt.Errorf("%w: cause=%v", ErrReadingResponse, cause)
 }
 
+func ErrorRetrievingBucketTags(bucket string, cause error) error {
+	return fmt.Errorf("%w: bucket=%s cause=%v", ErrRetrievingBucketTags, bucket, cause)
+}
+
+func ErrorRetrievingNotifications(bucket string, cause error) error {
+	return fmt.Errorf("%w: bucket=%s cause=%v", ErrRetrievingNotifications, bucket, cause)
+}
+
 func ErrorRetrievingObject(key, bucket string, cause error) error {
 	return fmt.Errorf("%w: key=%s bucket=%s cause=%v", ErrRetrievingObject, key, bucket, cause)
 }
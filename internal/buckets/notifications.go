@@ -0,0 +1,84 @@
+package buckets
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// NotificationClient reads and writes the notification configuration of a bucket
+type NotificationClient interface {
+	GetBucketNotificationConfiguration(ctx context.Context, params *s3.GetBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketNotificationConfigurationOutput, error)
+	PutBucketNotificationConfiguration(ctx context.Context, params *s3.PutBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketNotificationConfigurationOutput, error)
+}
+
+// EnsureEventBridge enables EventBridge notifications on a bucket that does not send them, such
+// as a replication bucket created before Setup enabled them, the other notifications of the
+// bucket are kept. It reports whether they had to be enabled.
+func EnsureEventBridge(ctx context.Context, s3Client NotificationClient, name string) (bool, error) {
+	resp, err := s3Client.GetBucketNotificationConfiguration(ctx, &s3.GetBucketNotificationConfigurationInput{
+		Bucket: aws.String(name),
+	})
+	if err != nil {
+		return false, ErrorRetrievingNotifications(name, err)
+	}
+	if resp.EventBridgeConfiguration != nil {
+		return false, nil
+	}
+
+	_, err = s3Client.PutBucketNotificationConfiguration(ctx, &s3.PutBucketNotificationConfigurationInput{
+		Bucket: aws.String(name),
+		NotificationConfiguration: &types.NotificationConfiguration{
+			EventBridgeConfiguration:     &types.EventBridgeConfiguration{},
+			LambdaFunctionConfigurations: resp.LambdaFunctionConfigurations,
+			QueueConfigurations:          resp.QueueConfigurations,
+			TopicConfigurations:          resp.TopicConfigurations,
+		},
+	})
+	if err != nil {
+		return false, ErrorApplyingEventBridge(err)
+	}
+
+	return true, nil
+}
+
+// EventBridgeBuckets remembers the buckets a function instance has enabled EventBridge
+// notifications on, so each bucket is only read once
+type EventBridgeBuckets struct {
+	mu       sync.Mutex
+	enabled  map[string]bool
+	s3Client NotificationClient
+}
+
+func NewEventBridgeBuckets(s3Client NotificationClient) *EventBridgeBuckets {
+	return &EventBridgeBuckets{
+		enabled:  make(map[string]bool),
+		s3Client: s3Client,
+	}
+}
+
+// Ensure enables EventBridge notifications on a bucket unless this instance already has,
+// a bucket that cannot be read or updated is tried again on the next call
+func (e *EventBridgeBuckets) Ensure(ctx context.Context, name string) (bool, error) {
+	e.mu.Lock()
+	enabled := e.enabled[name]
+	e.mu.Unlock()
+
+	if enabled {
+		return false, nil
+	}
+
+	changed, err := EnsureEventBridge(ctx, e.s3Client, name)
+	if err != nil {
+		return false, err
+	}
+
+	e.mu.Lock()
+	e.enabled[name] = true
+	e.mu.Unlock()
+
+	return changed, nil
+}
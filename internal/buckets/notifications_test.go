@@ -0,0 +1,89 @@
+package buckets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type mockNotificationClient struct {
+	configurations map[string]*types.NotificationConfiguration
+	gets           int
+	puts           int
+}
+
+func (m *mockNotificationClient) GetBucketNotificationConfiguration(ctx context.Context, params *s3.GetBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketNotificationConfigurationOutput, error) {
+	m.gets++
+	config, ok := m.configurations[aws.ToString(params.Bucket)]
+	if !ok {
+		return nil, errors.New("NoSuchBucket")
+	}
+	return &s3.GetBucketNotificationConfigurationOutput{
+		EventBridgeConfiguration: config.EventBridgeConfiguration,
+		QueueConfigurations:      config.QueueConfigurations,
+	}, nil
+}
+
+func (m *mockNotificationClient) PutBucketNotificationConfiguration(ctx context.Context, params *s3.PutBucketNotificationConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketNotificationConfigurationOutput, error) {
+	m.puts++
+	m.configurations[aws.ToString(params.Bucket)] = params.NotificationConfiguration
+	return &s3.PutBucketNotificationConfigurationOutput{}, nil
+}
+
+func TestEnsureEventBridge(t *testing.T) {
+	queue := types.QueueConfiguration{QueueArn: aws.String("arn:aws:sqs:us-east-1:123456789012:queue")}
+	client := &mockNotificationClient{
+		configurations: map[string]*types.NotificationConfiguration{
+			"stack-new-repl": {EventBridgeConfiguration: &types.EventBridgeConfiguration{}},
+			"stack-old-repl": {QueueConfigurations: []types.QueueConfiguration{queue}},
+		},
+	}
+
+	changed, err := EnsureEventBridge(context.Background(), client, "stack-new-repl")
+	if err != nil || changed || client.puts != 0 {
+		t.Errorf("Expected an enabled bucket to be left alone, got %v (%v)", changed, err)
+	}
+
+	changed, err = EnsureEventBridge(context.Background(), client, "stack-old-repl")
+	if err != nil || !changed {
+		t.Fatalf("Expected EventBridge to be enabled, got %v (%v)", changed, err)
+	}
+	config := client.configurations["stack-old-repl"]
+	if config.EventBridgeConfiguration == nil || len(config.QueueConfigurations) != 1 {
+		t.Errorf("Expected EventBridge added to the existing notifications, got %+v", config)
+	}
+
+	if _, err := EnsureEventBridge(context.Background(), client, "stack-missing-repl"); !errors.Is(err, ErrRetrievingNotifications) {
+		t.Errorf("Expected a retrieval error, got %v", err)
+	}
+}
+
+func TestEventBridgeBuckets(t *testing.T) {
+	client := &mockNotificationClient{
+		configurations: map[string]*types.NotificationConfiguration{
+			"stack-old-repl": {},
+		},
+	}
+	eventBridge := NewEventBridgeBuckets(client)
+
+	if changed, err := eventBridge.Ensure(context.Background(), "stack-old-repl"); err != nil || !changed {
+		t.Fatalf("Expected EventBridge to be enabled, got %v (%v)", changed, err)
+	}
+	if changed, err := eventBridge.Ensure(context.Background(), "stack-old-repl"); err != nil || changed {
+		t.Errorf("Expected the bucket to be remembered, got %v (%v)", changed, err)
+	}
+	if client.gets != 1 {
+		t.Errorf("Expected 1 read of the bucket, got %d", client.gets)
+	}
+
+	// A bucket that cannot be read is tried again
+	eventBridge.Ensure(context.Background(), "stack-missing-repl")
+	eventBridge.Ensure(context.Background(), "stack-missing-repl")
+	if client.gets != 3 {
+		t.Errorf("Expected the missing bucket to be read twice, got %d reads", client.gets)
+	}
+}
@@ -0,0 +1,98 @@
+package buckets
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	FixityIntervalTagKey = "FixityInterval"
+	FixityJitterTagKey   = "FixityJitter"
+
+	// FixityPolicyCacheTTL is how long a function instance uses a bucket policy before reading it again
+	FixityPolicyCacheTTL = 5 * time.Minute
+)
+
+// GetFixityPolicy returns the fixity policy set by the FixityInterval and FixityJitter tags
+// of a bucket (periods such as "3m", "1y" or "14d"), a tag that is not set keeps the default
+func GetFixityPolicy(ctx context.Context, s3Client *s3.Client, bucketName string) (db.FixityPolicy, error) {
+	resp, err := s3Client.GetBucketTagging(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(bucketName),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+			return db.DefaultFixityPolicy, nil
+		}
+		return db.DefaultFixityPolicy, ErrorRetrievingBucketTags(bucketName, err)
+	}
+
+	var interval, jitter string
+	for _, tag := range resp.TagSet {
+		switch aws.ToString(tag.Key) {
+		case FixityIntervalTagKey:
+			interval = aws.ToString(tag.Value)
+		case FixityJitterTagKey:
+			jitter = aws.ToString(tag.Value)
+		}
+	}
+
+	policy, err := db.NewFixityPolicy(interval, jitter)
+	if err != nil {
+		return db.DefaultFixityPolicy, ErrorInvalidFixityPolicy(bucketName, err)
+	}
+
+	return policy, nil
+}
+
+type cachedFixityPolicy struct {
+	expires time.Time
+	policy  db.FixityPolicy
+}
+
+// FixityPolicies caches the fixity policy of each bucket for FixityPolicyCacheTTL
+type FixityPolicies struct {
+	mu       sync.Mutex
+	policies map[string]cachedFixityPolicy
+	s3Client *s3.Client
+}
+
+func NewFixityPolicies(s3Client *s3.Client) *FixityPolicies {
+	return &FixityPolicies{
+		policies: make(map[string]cachedFixityPolicy),
+		s3Client: s3Client,
+	}
+}
+
+// Get returns the fixity policy of a bucket, the default policy is returned
+// with the error when the bucket tags cannot be read or are invalid
+func (p *FixityPolicies) Get(ctx context.Context, bucketName string) (db.FixityPolicy, error) {
+	p.mu.Lock()
+	cached, ok := p.policies[bucketName]
+	p.mu.Unlock()
+
+	if ok && time.Now().Before(cached.expires) {
+		return cached.policy, nil
+	}
+
+	policy, err := GetFixityPolicy(ctx, p.s3Client, bucketName)
+	if err != nil {
+		return policy, err
+	}
+
+	p.mu.Lock()
+	p.policies[bucketName] = cachedFixityPolicy{
+		expires: time.Now().Add(FixityPolicyCacheTTL),
+		policy:  policy,
+	}
+	p.mu.Unlock()
+
+	return policy, nil
+}
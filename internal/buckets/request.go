@@ -478,6 +478,12 @@ func (b *BucketRequest) Setup() {
 		return
 	}
 
+	// Restores of archived replicas complete with an EventBridge event
+	if err := b.EnableEventBridge(replicationBucketName); err != nil {
+		localStatus[replicationBucketName] = err.Error()
+		return
+	}
+
 	if err := b.RemovePolicy(fullBucketName); err != nil {
 		localStatus[fullBucketName] = err.Error()
 		return
@@ -0,0 +1,96 @@
+package buckets
+
+import (
+	"bufio"
+	"context"
+	"duracloud/internal/accounts"
+	"duracloud/internal/files"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	DefaultVerifyRequestLimit   = 100
+	VerifyRequestedFile         = "verify-requested.txt"
+	VerifyRequestedFileErrorKey = "error-processing-verify-requested-file"
+)
+
+// VerifyTarget is a line of a verification request, the objects of a bucket to verify now.
+// Key is an object key, or a prefix when it is empty or ends with "/".
+type VerifyTarget struct {
+	Bucket string
+	Key    string
+}
+
+// IsPrefix reports whether the target is every object under a prefix rather than a single key
+func (t VerifyTarget) IsPrefix() bool {
+	return t.Key == "" || strings.HasSuffix(t.Key, "/")
+}
+
+func (t VerifyTarget) String() string {
+	return t.Bucket + "/" + t.Key
+}
+
+// IsVerifyRequest checks if an object uploaded to the bucket requested bucket is a verification request
+func IsVerifyRequest(key string) bool {
+	return path.Base(key) == VerifyRequestedFile
+}
+
+// GetVerifyTargets retrieves the targets of a verification request from an S3 object, validates
+// them, and enforces a maximum limit. Each line is a bucket, a bucket and prefix ("bucket/dir/")
+// or a bucket and object key ("bucket/dir/file.txt"), buckets may omit the stack name.
+func GetVerifyTargets(ctx context.Context, s3Client *s3.Client, obj files.S3Object, limit int) ([]VerifyTarget, error) {
+	resp, err := files.DownloadObject(ctx, s3Client, obj, false)
+	if err != nil {
+		return nil, ErrorRetrievingObject(obj.Key, obj.Bucket, err)
+	}
+	defer func() { _ = resp.Close() }()
+
+	return parseVerifyTargets(ctx, resp, limit)
+}
+
+func parseVerifyTargets(ctx context.Context, r io.Reader, limit int) ([]VerifyTarget, error) {
+	awsCtx, ok := ctx.Value(accounts.AWSContextKey).(accounts.AWSContext)
+	if !ok {
+		return nil, ErrorAWSContextRetrieval()
+	}
+
+	var targets []VerifyTarget
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		bucket, key, _ := strings.Cut(line, "/")
+		bucket = strings.ToLower(bucket)
+		if !strings.HasPrefix(bucket, awsCtx.StackName+"-") {
+			bucket = awsCtx.StackName + "-" + bucket
+		}
+		if IsIgnoreFilesBucket(bucket) || !ValidateBucketName(ctx, strings.TrimPrefix(bucket, awsCtx.StackName+"-")) {
+			return nil, ErrorInvalidVerifyTarget(line)
+		}
+
+		targets = append(targets, VerifyTarget{Bucket: bucket, Key: key})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, ErrorReadingResponse(err)
+	}
+
+	if len(targets) > limit {
+		return nil, ErrorExceededMaxVerifyTargetsPerRequest(limit, len(targets))
+	}
+
+	return targets, nil
+}
+
+// WriteVerifyStatus writes the outcome of each target of a verification request to the managed bucket
+func WriteVerifyStatus(ctx context.Context, s3Client *s3.Client, bucketName string, log map[string]string) error {
+	return writeLog(ctx, s3Client, bucketName, "verify-request-log", log)
+}
@@ -0,0 +1,92 @@
+package buckets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsVerifyRequest(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"verify-requested.txt", true},
+		{"requests/verify-requested.txt", true},
+		{"create-buckets.txt", false},
+		{"verify-requested.txt.bak", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsVerifyRequest(tt.key); got != tt.expected {
+				t.Errorf("IsVerifyRequest(%q) = %v, expected %v", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseVerifyTargets(t *testing.T) {
+	ctx := createTestContext("test-stack")
+	content := "test-stack-private\n" +
+		"\n" +
+		"Public/images/\n" +
+		"test-stack-private/docs/report.pdf\n"
+
+	targets, err := parseVerifyTargets(ctx, strings.NewReader(content), DefaultVerifyRequestLimit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		target   VerifyTarget
+		isPrefix bool
+	}{
+		{VerifyTarget{Bucket: "test-stack-private", Key: ""}, true},
+		{VerifyTarget{Bucket: "test-stack-public", Key: "images/"}, true},
+		{VerifyTarget{Bucket: "test-stack-private", Key: "docs/report.pdf"}, false},
+	}
+	if len(targets) != len(expected) {
+		t.Fatalf("expected %d targets, got %v", len(expected), targets)
+	}
+	for i, e := range expected {
+		if targets[i] != e.target {
+			t.Errorf("expected target %d to be %v, got %v", i, e.target, targets[i])
+		}
+		if targets[i].IsPrefix() != e.isPrefix {
+			t.Errorf("expected target %s IsPrefix() = %v", targets[i], e.isPrefix)
+		}
+	}
+}
+
+func TestParseVerifyTargetsErrors(t *testing.T) {
+	ctx := createTestContext("test-stack")
+
+	tests := []struct {
+		name     string
+		content  string
+		limit    int
+		expected error
+	}{
+		{"managed bucket", "test-stack-managed\n", DefaultVerifyRequestLimit, ErrInvalidVerifyTarget},
+		{"replication bucket", "private-repl/docs/\n", DefaultVerifyRequestLimit, ErrInvalidVerifyTarget},
+		{"bucket requested bucket", "bucket-requested\n", DefaultVerifyRequestLimit, ErrInvalidVerifyTarget},
+		{"invalid bucket name", "my_bucket/docs/\n", DefaultVerifyRequestLimit, ErrInvalidVerifyTarget},
+		{"too many targets", "private\npublic\n", 1, ErrExceededMaxVerifyTargets},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseVerifyTargets(ctx, strings.NewReader(tt.content), tt.limit)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	_, err := parseVerifyTargets(context.Background(), strings.NewReader("private\n"), DefaultVerifyRequestLimit)
+	if !errors.Is(err, ErrAWSContextRetrieval) {
+		t.Errorf("expected %v without an AWS context, got %v", ErrAWSContextRetrieval, err)
+	}
+}
@@ -0,0 +1,89 @@
+package checksum
+
+import (
+	"duracloud/internal/db"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// WithBudget counts the bytes read by verifications against a byte budget, a verification that
+// would exceed the budget is rescheduled into a later budget window. It requires a DB with a
+// budget table.
+func (v *Verifier) WithBudget(budget db.ByteBudget) *Verifier {
+	v.budget = &budget
+	return v
+}
+
+// Deferred reports whether the last verification was deferred because the byte budget was spent
+func (v *Verifier) Deferred() bool {
+	return v.deferred
+}
+
+// deferOverBudget counts the bytes the verification will read against the byte budget and
+// reschedules the record when they would exceed it, it reports whether the verification was
+// deferred. Only the next check of the record is moved, the record is not written again.
+func (v *Verifier) deferOverBudget(checksumRecord db.ChecksumRecord) (bool, error) {
+	counter, ok := v.store.(db.BudgetCounter)
+	if !ok {
+		return false, db.ErrorBudgetNotConfigured()
+	}
+
+	size, err := v.verificationSize(checksumRecord)
+	if err != nil {
+		// The verification reads the object again and records why it cannot
+		log.Printf("Unable to size %s for the byte budget: %v", v.obj.URI(), err)
+		return false, nil
+	}
+
+	ok, err = counter.ReserveBytes(*v.budget, size)
+	if err != nil || ok {
+		return false, err
+	}
+
+	next, err := v.budget.DeferUntil(time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	log.Printf("Deferring verification of %s to %s: %s budget spent", v.obj.URI(), next.Format(time.RFC3339), *v.budget)
+
+	moved, err := v.store.Reschedule(checksumRecord, next)
+	if err != nil {
+		return false, err
+	}
+	if !moved {
+		// Checked since it was read, that check scheduled the next one
+		log.Printf("Skipping verification of %s: checked since it was read", v.obj.URI())
+		return true, nil
+	}
+	v.deferred = true
+
+	if err := counter.RecordDeferred(*v.budget); err != nil {
+		log.Printf("Failed to count deferred verification: %v", err)
+	}
+
+	return true, nil
+}
+
+// verificationSize returns the bytes a verification reads, the object and its replica when
+// the replica is due to be checked (replication keeps the bytes of the version)
+func (v *Verifier) verificationSize(checksumRecord db.ChecksumRecord) (int64, error) {
+	resp, err := v.s3Client.HeadObject(v.ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(v.obj.Bucket),
+		Key:       aws.String(v.obj.Key),
+		VersionId: v.obj.VersionIdInput(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	size := aws.ToInt64(resp.ContentLength)
+	if v.replica && ReplicaDue(checksumRecord, v.replicaInterval, time.Now()) {
+		size *= 2
+	}
+
+	return size, nil
+}
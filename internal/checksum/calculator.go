@@ -3,22 +3,46 @@ package checksum
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
 	"duracloud/internal/files"
 	"errors"
-	"fmt"
 	"hash"
 	"io"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 )
 
 const (
-	MaxFileSize = 20 * 1024 * 1024 * 1024 // 20GB maximum file size (Lambda consideration)
+	MaxFileSize = 5 * 1024 * 1024 * 1024 * 1024 // 5TB maximum file size (S3 object limit)
+
+	AlgorithmMD5    = "md5"
+	AlgorithmSHA256 = "sha256"
+	AlgorithmSHA512 = "sha512"
+
+	// customAlgorithm labels the digest of a hasher supplied via NewS3CalculatorWithHasher
+	customAlgorithm = "custom"
 )
 
+// DefaultAlgorithms are calculated for every deposit, the first is the primary checksum
+var DefaultAlgorithms = []string{
+	AlgorithmMD5,
+	AlgorithmSHA256,
+	AlgorithmSHA512,
+}
+
+var hasherFuncs = map[string]func() hash.Hash{
+	AlgorithmMD5:    md5.New,
+	AlgorithmSHA256: sha256.New,
+	AlgorithmSHA512: sha512.New,
+}
+
 // S3ClientInterface defines the S3 operations required for checksum verification
 type S3ClientInterface interface {
 	HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
@@ -27,37 +51,126 @@ type S3ClientInterface interface {
 
 // S3Calculator handles checksum calculation by streaming directly from S3
 type S3Calculator struct {
-	s3Client   S3ClientInterface
-	hasherFunc func() hash.Hash
+	s3Client           S3ClientInterface
+	algorithms         []string
+	checkpointer       Checkpointer
+	checkpointInterval int64
+	hashers            map[string]func() hash.Hash
+	rangeConcurrency   int
+	rangeSize          int64
+	rangeThreshold     int64
+}
+
+// Result holds the digests calculated from a single pass over an object
+type Result struct {
+	Checksums    map[string]string
+	Continuation *Continuation   // set with ErrContinuationRequired, pass to Resume
+	ETag         string          // reconstructed from the content, unquoted
+	Native       *NativeChecksum // additional checksum reported by S3, if any
+	Parts        int
+	Size         int64
+	Supplied     map[string]string // checksums supplied in user metadata by source, if any
 }
 
 // NewS3Calculator creates a new S3 streaming calculator
 func NewS3Calculator(s3Client S3ClientInterface) *S3Calculator {
 	return &S3Calculator{
-		s3Client:   s3Client,
-		hasherFunc: md5.New,
+		s3Client:           s3Client,
+		algorithms:         []string{AlgorithmMD5},
+		hashers:            map[string]func() hash.Hash{AlgorithmMD5: md5.New},
+		rangeConcurrency:   RangeConcurrency,
+		rangeSize:          RangeSize,
+		rangeThreshold:     RangedReadThreshold,
+		checkpointInterval: CheckpointInterval,
 	}
 }
 
+// NewS3CalculatorWithAlgorithms creates a calculator that hashes with every algorithm in one pass
+func NewS3CalculatorWithAlgorithms(s3Client S3ClientInterface, algorithms ...string) (*S3Calculator, error) {
+	if len(algorithms) == 0 {
+		algorithms = DefaultAlgorithms
+	}
+
+	hashers := make(map[string]func() hash.Hash, len(algorithms))
+	for _, algorithm := range algorithms {
+		if hasherFunc, ok := hasherFuncs[algorithm]; ok {
+			hashers[algorithm] = hasherFunc
+			continue
+		}
+
+		hasherFunc, _, ok := parseNativeAlgorithmKey(algorithm)
+		if !ok {
+			return nil, ErrorUnsupportedAlgorithm(algorithm)
+		}
+		hashers[algorithm] = hasherFunc
+	}
+
+	return &S3Calculator{
+		s3Client:           s3Client,
+		algorithms:         algorithms,
+		hashers:            hashers,
+		rangeConcurrency:   RangeConcurrency,
+		rangeSize:          RangeSize,
+		rangeThreshold:     RangedReadThreshold,
+		checkpointInterval: CheckpointInterval,
+	}, nil
+}
+
 // NewS3CalculatorWithHasher creates a calculator with custom hash function
 func NewS3CalculatorWithHasher(s3Client S3ClientInterface, hasherFunc func() hash.Hash) *S3Calculator {
 	return &S3Calculator{
-		s3Client:   s3Client,
-		hasherFunc: hasherFunc,
+		s3Client:           s3Client,
+		algorithms:         []string{customAlgorithm},
+		hashers:            map[string]func() hash.Hash{customAlgorithm: hasherFunc},
+		rangeConcurrency:   RangeConcurrency,
+		rangeSize:          RangeSize,
+		rangeThreshold:     RangedReadThreshold,
+		checkpointInterval: CheckpointInterval,
 	}
 }
 
-// CalculateChecksum streams an object from S3 and calculates its checksum
+// WithCheckpointer enables checkpoints, large calculations are saved periodically and
+// when handed off, then resumed by the next call for the same object
+func (c *S3Calculator) WithCheckpointer(checkpointer Checkpointer) *S3Calculator {
+	c.checkpointer = checkpointer
+	return c
+}
+
+// CalculateChecksum streams an object from S3 and calculates its primary checksum
 func (c *S3Calculator) CalculateChecksum(ctx context.Context, obj files.S3Object) (string, error) {
+	result, err := c.Calculate(ctx, obj)
+	if err != nil {
+		return "", err
+	}
+
+	return result.Checksums[c.algorithms[0]], nil
+}
+
+// Calculate streams an object from S3 once and calculates a checksum for each algorithm
+func (c *S3Calculator) Calculate(ctx context.Context, obj files.S3Object) (Result, error) {
 	headResp, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(obj.Bucket),
-		Key:    aws.String(obj.Key),
+		Bucket:       aws.String(obj.Bucket),
+		Key:          aws.String(obj.Key),
+		ChecksumMode: types.ChecksumModeEnabled,
+		VersionId:    obj.VersionIdInput(),
 	})
 	if err != nil {
 		if isS3NotFound(err) {
-			return "", ErrorObjectNotFound(obj.URI())
+			return Result{}, ErrorObjectNotFound(obj.URI())
 		}
-		return "", ErrorMetadataNotRetrieved(obj.URI(), err)
+		return Result{}, ErrorMetadataNotRetrieved(obj.URI(), err)
+	}
+
+	// An archived object cannot be read until it is restored
+	if isArchived(headResp.StorageClass, aws.ToString(headResp.Restore)) {
+		return Result{}, ErrorObjectArchived(obj.URI(), string(headResp.StorageClass))
+	}
+
+	// An additional checksum supplied by the client is always calculated for comparison
+	algorithms := c.algorithms
+	native := nativeChecksumFromHead(headResp)
+	if native != nil && !slices.Contains(algorithms, native.Key()) {
+		algorithms = append(slices.Clone(algorithms), native.Key())
 	}
 
 	var fileSize int64
@@ -66,22 +179,56 @@ func (c *S3Calculator) CalculateChecksum(ctx context.Context, obj files.S3Object
 	}
 
 	if fileSize > MaxFileSize {
-		return "", ErrorMaxFileSizeExceeded(obj.URI(), fileSize)
+		return Result{}, ErrorMaxFileSizeExceeded(obj.URI(), fileSize)
+	}
+
+	partSizes, err := c.getPartSizes(ctx, obj, PartsCountFromETag(aws.ToString(headResp.ETag)), fileSize)
+	if err != nil {
+		return Result{}, err
+	}
+
+	state, err := c.newHashState(algorithms, partSizes)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cont := &Continuation{
+		ETag:      aws.ToString(headResp.ETag),
+		Native:    native,
+		Object:    obj,
+		PartSizes: partSizes,
+		Size:      fileSize,
+		Supplied:  suppliedChecksumsFromMetadata(headResp.Metadata),
+		state:     state,
 	}
 
 	log.Printf("Starting checksum calculation for %s - Size: %d bytes (%.2f MB)",
 		obj.URI(), fileSize, float64(fileSize)/(1024*1024))
 
+	// Large objects are read with concurrent ranged GETs and may be handed off to a continuation
+	if fileSize >= c.rangeThreshold {
+		if c.checkpointer != nil {
+			if restored := c.loadCheckpoint(ctx, cont); restored != nil {
+				cont = restored
+			}
+		}
+		return c.hashRanges(ctx, cont)
+	}
+
 	// Get the object content
 	getResp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(obj.Bucket),
-		Key:    aws.String(obj.Key),
+		Bucket:    aws.String(obj.Bucket),
+		Key:       aws.String(obj.Key),
+		VersionId: obj.VersionIdInput(),
 	})
 	if err != nil {
 		if isS3NotFound(err) {
-			return "", ErrorObjectNotFound(obj.URI())
+			return Result{}, ErrorObjectNotFound(obj.URI())
 		}
-		return "", ErrorObjectNotRetrieved(obj.URI(), err)
+		if isS3InvalidObjectState(err) {
+			return Result{}, ErrorObjectArchived(obj.URI(), string(headResp.StorageClass))
+		}
+		return Result{}, ErrorObjectNotRetrieved(obj.URI(), err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -90,7 +237,69 @@ func (c *S3Calculator) CalculateChecksum(ctx context.Context, obj files.S3Object
 		}
 	}(getResp.Body)
 
-	return c.streamAndHash(getResp.Body, obj.URI(), fileSize)
+	err = c.streamAndHash(getResp.Body, obj.URI(), fileSize, state)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return cont.result()
+}
+
+// getPartSizes returns the size of each part for a multipart object (nil when not multipart).
+// Parts are assumed to be uniform apart from the last, which is true for the AWS SDKs and CLI,
+// and the first and last part sizes are confirmed with S3 before relying on that.
+func (c *S3Calculator) getPartSizes(ctx context.Context, obj files.S3Object, parts int, fileSize int64) ([]int64, error) {
+	if parts < 1 {
+		return nil, nil
+	}
+
+	firstPartSize, err := c.getPartSize(ctx, obj, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	partSizes := UniformPartSizes(fileSize, firstPartSize)
+	if len(partSizes) == parts {
+		lastPartSize, err := c.getPartSize(ctx, obj, parts)
+		if err != nil {
+			return nil, err
+		}
+
+		if partSizes[parts-1] == lastPartSize {
+			return partSizes, nil
+		}
+	}
+
+	log.Printf("Parts are not uniform for %s, retrieving size of %d parts", obj.URI(), parts)
+
+	partSizes = make([]int64, 0, parts)
+	for partNumber := 1; partNumber <= parts; partNumber++ {
+		partSize, err := c.getPartSize(ctx, obj, partNumber)
+		if err != nil {
+			return nil, err
+		}
+		partSizes = append(partSizes, partSize)
+	}
+
+	return partSizes, nil
+}
+
+// getPartSize returns the size of a single part of a multipart object
+func (c *S3Calculator) getPartSize(ctx context.Context, obj files.S3Object, partNumber int) (int64, error) {
+	headResp, err := c.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:     aws.String(obj.Bucket),
+		Key:        aws.String(obj.Key),
+		PartNumber: aws.Int32(int32(partNumber)),
+		VersionId:  obj.VersionIdInput(),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return 0, ErrorObjectNotFound(obj.URI())
+		}
+		return 0, ErrorMetadataNotRetrieved(obj.URI(), err)
+	}
+
+	return aws.ToInt64(headResp.ContentLength), nil
 }
 
 // getAdaptiveBufferSize returns a buffer size based on file size
@@ -105,38 +314,91 @@ func (c *S3Calculator) getAdaptiveBufferSize(fileSize int64) int {
 	}
 }
 
-// streamAndHash streams content and calculates hash using adaptive buffer size
-func (c *S3Calculator) streamAndHash(reader io.Reader, uri string, expectedSize int64) (string, error) {
-	hashWriter := c.hasherFunc()
-
+// streamAndHash streams content and calculates hashes using adaptive buffer size
+func (c *S3Calculator) streamAndHash(reader io.Reader, uri string, expectedSize int64, state *hashState) error {
 	bufferSize := c.getAdaptiveBufferSize(expectedSize)
 	buffer := make([]byte, bufferSize)
 
 	log.Printf("Using %d KB buffer for %s (%.2f MB file)",
 		bufferSize/1024, uri, float64(expectedSize)/(1024*1024))
 
-	totalBytes, err := io.CopyBuffer(hashWriter, reader, buffer)
+	_, err := io.CopyBuffer(state, reader, buffer)
 	if err != nil {
-		return "", ErrorReadingFromStream(uri, err)
+		return ErrorReadingFromStream(uri, err)
+	}
+
+	return nil
+}
+
+// newHashState returns the digests for every algorithm, the md5 digest is the ETag
+// for single part objects so the ETag is only hashed separately when required
+func (c *S3Calculator) newHashState(algorithms []string, partSizes []int64) (*hashState, error) {
+	state := &hashState{
+		digests: make(map[string]digest, len(algorithms)),
+		parts:   max(len(partSizes), 1),
+	}
+
+	for _, algorithm := range algorithms {
+		d, err := c.newDigest(algorithm, partSizes)
+		if err != nil {
+			return nil, err
+		}
+		state.digests[algorithm] = d
+	}
+
+	if _, ok := state.digests[AlgorithmMD5]; !ok || len(partSizes) > 0 {
+		state.etag = newETagDigest(partSizes)
+	}
+
+	return state, nil
+}
+
+// newDigest returns a digest for the algorithm, S3 additional checksums are base64 encoded
+// and composite additional checksums are calculated per part
+func (c *S3Calculator) newDigest(algorithm string, partSizes []int64) (digest, error) {
+	if !IsNativeAlgorithm(algorithm) {
+		return newWholeDigest(c.hashers[algorithm], encodeHex), nil
+	}
+
+	hasherFunc, composite, ok := parseNativeAlgorithmKey(algorithm)
+	if !ok {
+		return nil, ErrorUnsupportedAlgorithm(algorithm)
 	}
 
-	// Verify we read the expected amount
-	if totalBytes != expectedSize {
-		return "", ErrorBytesCountDoesNotMatch(uri, expectedSize, totalBytes)
+	if !composite {
+		return newWholeDigest(hasherFunc, encodeBase64), nil
 	}
 
-	checksum := fmt.Sprintf("%x", hashWriter.Sum(nil))
-	log.Printf("Successfully calculated checksum for %s: %d bytes, checksum: %s",
-		uri, totalBytes, checksum)
+	if len(partSizes) == 0 {
+		return nil, ErrorUnsupportedAlgorithm(algorithm)
+	}
 
-	return checksum, nil
+	return newCompositeDigest(hasherFunc, encodeBase64, partSizes), nil
 }
 
 // isS3NotFound checks if an error is a "not found" error from S3
 func isS3NotFound(err error) bool {
 	var apiErr smithy.APIError
 	if errors.As(err, &apiErr) {
-		return apiErr.ErrorCode() == "NoSuchKey" || apiErr.ErrorCode() == "NoSuchBucket"
+		switch apiErr.ErrorCode() {
+		case "NoSuchBucket", "NoSuchKey", "NoSuchVersion", "NotFound":
+			return true
+		}
+	}
+	return false
+}
+
+// isS3InvalidObjectState checks if an error is S3 refusing to read an archived object
+func isS3InvalidObjectState(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidObjectState"
+}
+
+// isArchived reports whether an object must be restored before it can be read
+func isArchived(storageClass types.StorageClass, restore string) bool {
+	switch storageClass {
+	case types.StorageClassDeepArchive, types.StorageClassGlacier:
+		return !strings.Contains(restore, `ongoing-request="false"`)
 	}
 	return false
 }
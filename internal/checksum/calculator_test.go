@@ -5,39 +5,97 @@ import (
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
+	"crypto/sha512"
 	"duracloud/internal/files"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 )
 
 // Mock S3 client for testing
 type mockS3Client struct {
-	objects map[string][]byte // key format: "bucket/key"
-	errors  map[string]error  // errors to return for specific operations
+	objects   map[string][]byte          // key format: "bucket/key"
+	errors    map[string]error           // errors to return for specific operations
+	partSizes map[string][]int64         // part sizes for multipart objects
+	natives   map[string]*NativeChecksum // additional checksums supplied on upload
+	metadata  map[string]map[string]string
+	lengths   map[string]int64 // content lengths reported instead of the content size
 }
 
 func newMockS3Client() *mockS3Client {
 	return &mockS3Client{
-		objects: make(map[string][]byte),
-		errors:  make(map[string]error),
+		objects:   make(map[string][]byte),
+		errors:    make(map[string]error),
+		partSizes: make(map[string][]int64),
+		natives:   make(map[string]*NativeChecksum),
+		metadata:  make(map[string]map[string]string),
+		lengths:   make(map[string]int64),
 	}
 }
 
+func (m *mockS3Client) addNativeChecksum(bucket, key string, native NativeChecksum) {
+	m.natives[bucket+"/"+key] = &native
+}
+
+// addMetadata sets the user metadata of an object, keys are without the x-amz-meta- prefix
+func (m *mockS3Client) addMetadata(bucket, key string, metadata map[string]string) {
+	m.metadata[bucket+"/"+key] = metadata
+}
+
 func (m *mockS3Client) addObject(bucket, key string, content []byte) {
 	m.objects[bucket+"/"+key] = content
 }
 
+// addVersion adds a non-current version of an object
+func (m *mockS3Client) addVersion(bucket, key, versionId string, content []byte) {
+	m.objects[mockKey(&bucket, &key, &versionId)] = content
+}
+
+// mockKey returns the map key of an object version, format: "bucket/key" or "bucket/key?versionId=id"
+func mockKey(bucket, key, versionId *string) string {
+	if versionId != nil {
+		return *bucket + "/" + *key + "?versionId=" + *versionId
+	}
+	return *bucket + "/" + *key
+}
+
+func (m *mockS3Client) addMultipartObject(bucket, key string, content []byte, partSizes []int64) {
+	m.objects[bucket+"/"+key] = content
+	m.partSizes[bucket+"/"+key] = partSizes
+}
+
+// etag returns the ETag S3 would assign to the object
+func (m *mockS3Client) etag(key string) string {
+	content := m.objects[key]
+	partSizes := m.partSizes[key]
+	if len(partSizes) == 0 {
+		return fmt.Sprintf("\"%s\"", calculateMD5(content))
+	}
+
+	var digests []byte
+	offset := int64(0)
+	for _, size := range partSizes {
+		digest := md5.Sum(content[offset : offset+size])
+		digests = append(digests, digest[:]...)
+		offset += size
+	}
+
+	return fmt.Sprintf("\"%x-%d\"", md5.Sum(digests), len(partSizes))
+}
+
 func (m *mockS3Client) addError(bucket, key, operation string, err error) {
 	m.errors[operation+":"+bucket+"/"+key] = err
 }
 
 func (m *mockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
-	key := *input.Bucket + "/" + *input.Key
+	key := mockKey(input.Bucket, input.Key, input.VersionId)
 
 	if err, exists := m.errors["head:"+key]; exists {
 		return nil, err
@@ -49,13 +107,49 @@ func (m *mockS3Client) HeadObject(ctx context.Context, input *s3.HeadObjectInput
 	}
 
 	contentLength := int64(len(content))
-	return &s3.HeadObjectOutput{
+	if length, exists := m.lengths[key]; exists {
+		contentLength = length
+	}
+	etag := m.etag(key)
+
+	partSizes := m.partSizes[key]
+	if input.PartNumber != nil && len(partSizes) > 0 {
+		partsCount := int32(len(partSizes))
+		contentLength = partSizes[*input.PartNumber-1]
+		return &s3.HeadObjectOutput{
+			ContentLength: &contentLength,
+			ETag:          &etag,
+			PartsCount:    &partsCount,
+		}, nil
+	}
+
+	output := &s3.HeadObjectOutput{
 		ContentLength: &contentLength,
-	}, nil
+		ETag:          &etag,
+		Metadata:      m.metadata[key],
+	}
+
+	if native, exists := m.natives[key]; exists && input.ChecksumMode == types.ChecksumModeEnabled {
+		output.ChecksumType = native.Type
+		switch native.Algorithm {
+		case types.ChecksumAlgorithmCrc32:
+			output.ChecksumCRC32 = &native.Value
+		case types.ChecksumAlgorithmCrc32c:
+			output.ChecksumCRC32C = &native.Value
+		case types.ChecksumAlgorithmCrc64nvme:
+			output.ChecksumCRC64NVME = &native.Value
+		case types.ChecksumAlgorithmSha1:
+			output.ChecksumSHA1 = &native.Value
+		case types.ChecksumAlgorithmSha256:
+			output.ChecksumSHA256 = &native.Value
+		}
+	}
+
+	return output, nil
 }
 
 func (m *mockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
-	key := *input.Bucket + "/" + *input.Key
+	key := mockKey(input.Bucket, input.Key, input.VersionId)
 
 	if err, exists := m.errors["get:"+key]; exists {
 		return nil, err
@@ -66,6 +160,19 @@ func (m *mockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput,
 		return nil, &smithy.GenericAPIError{Code: "NoSuchKey", Message: "Key not found"}
 	}
 
+	if input.IfMatch != nil && *input.IfMatch != m.etag(key) {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+	}
+
+	if input.Range != nil {
+		var start, end int64
+		if _, err := fmt.Sscanf(*input.Range, "bytes=%d-%d", &start, &end); err != nil {
+			return nil, &smithy.GenericAPIError{Code: "InvalidRange", Message: err.Error()}
+		}
+		size := int64(len(content))
+		content = content[min(start, size):min(end+1, size)]
+	}
+
 	return &s3.GetObjectOutput{
 		Body: io.NopCloser(bytes.NewReader(content)),
 	}, nil
@@ -182,6 +289,16 @@ func TestS3Calculator_ErrorHandling(t *testing.T) {
 			},
 			errorSubstring: "object not found",
 		},
+		{
+			name:   "object archived - get",
+			bucket: "test-bucket",
+			key:    "archived.txt",
+			setupError: func(m *mockS3Client) {
+				m.addObject("test-bucket", "archived.txt", []byte("test"))
+				m.addError("test-bucket", "archived.txt", "get", &smithy.GenericAPIError{Code: "InvalidObjectState"})
+			},
+			errorSubstring: "object is archived",
+		},
 		{
 			name:   "bucket not found",
 			bucket: "nonexistent-bucket",
@@ -235,6 +352,130 @@ func TestS3Calculator_LargeFile(t *testing.T) {
 	}
 }
 
+func TestS3Calculator_CalculateMultipleAlgorithms(t *testing.T) {
+	mockClient := newMockS3Client()
+	content := []byte("DuraCloud preservation content")
+	mockClient.addObject("test-bucket", "multi.txt", content)
+
+	calc, err := NewS3CalculatorWithAlgorithms(mockClient, DefaultAlgorithms...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "multi.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		AlgorithmMD5:    calculateMD5(content),
+		AlgorithmSHA256: fmt.Sprintf("%x", sha256.Sum256(content)),
+		AlgorithmSHA512: fmt.Sprintf("%x", sha512.Sum512(content)),
+	}
+
+	if len(result.Checksums) != len(expected) {
+		t.Fatalf("expected %d checksums, got %d", len(expected), len(result.Checksums))
+	}
+
+	for algorithm, value := range expected {
+		if result.Checksums[algorithm] != value {
+			t.Errorf("%s mismatch: expected %s, got %s", algorithm, value, result.Checksums[algorithm])
+		}
+	}
+
+	if result.Size != int64(len(content)) {
+		t.Errorf("size mismatch: expected %d, got %d", len(content), result.Size)
+	}
+
+	primary, err := calc.CalculateChecksum(context.Background(), files.NewS3Object("test-bucket", "multi.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if primary != expected[AlgorithmMD5] {
+		t.Errorf("primary checksum mismatch: expected %s, got %s", expected[AlgorithmMD5], primary)
+	}
+}
+
+func TestS3Calculator_MultipartETag(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 2500) // 25,000 bytes
+
+	tests := []struct {
+		name      string
+		partSizes []int64
+	}{
+		{
+			name:      "uniform parts",
+			partSizes: []int64{10000, 10000, 5000},
+		},
+		{
+			name:      "exact multiple of part size",
+			partSizes: []int64{12500, 12500},
+		},
+		{
+			name:      "non-uniform parts",
+			partSizes: []int64{8000, 12000, 5000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockS3Client()
+			mockClient.addMultipartObject("test-bucket", "multipart.bin", content, tt.partSizes)
+
+			calc, err := NewS3CalculatorWithAlgorithms(mockClient, DefaultAlgorithms...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "multipart.bin"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := NormalizeETag(mockClient.etag("test-bucket/multipart.bin"))
+			if result.ETag != expected {
+				t.Errorf("ETag mismatch: expected %s, got %s", expected, result.ETag)
+			}
+
+			if result.Parts != len(tt.partSizes) {
+				t.Errorf("parts mismatch: expected %d, got %d", len(tt.partSizes), result.Parts)
+			}
+
+			if result.Checksums[AlgorithmMD5] != calculateMD5(content) {
+				t.Errorf("md5 should be calculated over the whole object")
+			}
+		})
+	}
+}
+
+func TestS3Calculator_SinglePartETag(t *testing.T) {
+	mockClient := newMockS3Client()
+	content := []byte("single part content")
+	mockClient.addObject("test-bucket", "single.txt", content)
+
+	calc := NewS3CalculatorWithHasher(mockClient, sha256.New)
+	result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "single.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.ETag != calculateMD5(content) {
+		t.Errorf("ETag mismatch: expected %s, got %s", calculateMD5(content), result.ETag)
+	}
+}
+
+func TestNewS3CalculatorWithAlgorithms_Unsupported(t *testing.T) {
+	_, err := NewS3CalculatorWithAlgorithms(newMockS3Client(), AlgorithmMD5, "sha1")
+	if err == nil {
+		t.Fatal("expected error for unsupported algorithm, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "unsupported checksum algorithm") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestS3Calculator_GetAdaptiveBufferSize(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -295,3 +536,43 @@ func TestS3Object_URI(t *testing.T) {
 		t.Errorf("URI mismatch: expected %s, got %s", expected, obj.URI())
 	}
 }
+
+func TestS3Object_VersionURI(t *testing.T) {
+	obj := files.NewS3ObjectVersion("my-bucket", "path/to/file.txt", "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY")
+	expected := "s3://my-bucket/path/to/file.txt?versionId=3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY"
+
+	if obj.URI() != expected {
+		t.Errorf("URI mismatch: expected %s, got %s", expected, obj.URI())
+	}
+
+	if aws.ToString(obj.VersionIdInput()) != obj.VersionId {
+		t.Errorf("expected version id input %s", obj.VersionId)
+	}
+
+	if files.NewS3Object("my-bucket", "file.txt").VersionIdInput() != nil {
+		t.Error("expected nil version id input for the current version")
+	}
+}
+
+func TestS3Calculator_VersionedObject(t *testing.T) {
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "versioned.txt", []byte("current version"))
+	mockClient.addVersion("test-bucket", "versioned.txt", "v1", []byte("previous version"))
+
+	calc := NewS3Calculator(mockClient)
+	obj := files.NewS3ObjectVersion("test-bucket", "versioned.txt", "v1")
+
+	result, err := calc.CalculateChecksum(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != calculateMD5([]byte("previous version")) {
+		t.Errorf("expected checksum of the requested version, got %s", result)
+	}
+
+	_, err = calc.CalculateChecksum(context.Background(), files.NewS3ObjectVersion("test-bucket", "versioned.txt", "expired"))
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("expected object not found for an expired version, got %v", err)
+	}
+}
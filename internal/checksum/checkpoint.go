@@ -0,0 +1,217 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/files"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// CheckpointInterval is the number of bytes hashed between checkpoints
+	CheckpointInterval = 2 * 1024 * 1024 * 1024 // 2GB
+
+	// CheckpointPrefix is where checkpoints are stored in the managed bucket
+	CheckpointPrefix = "checkpoints/"
+)
+
+// Checkpointer persists calculations in progress so another invocation can resume them
+type Checkpointer interface {
+	Delete(ctx context.Context, obj files.S3Object) error
+	Load(ctx context.Context, obj files.S3Object) ([]byte, error) // nil when there is no checkpoint
+	Save(ctx context.Context, obj files.S3Object, data []byte) error
+}
+
+// S3Checkpointer stores checkpoints in the managed bucket
+type S3Checkpointer struct {
+	s3Client *s3.Client
+	bucket   string
+}
+
+func NewS3Checkpointer(s3Client *s3.Client, bucket string) *S3Checkpointer {
+	return &S3Checkpointer{
+		s3Client: s3Client,
+		bucket:   bucket,
+	}
+}
+
+// CheckpointKey returns the managed bucket key of the checkpoint for an object version
+func CheckpointKey(obj files.S3Object) string {
+	if obj.VersionId != "" {
+		return fmt.Sprintf("%s%s/%s#%s", CheckpointPrefix, obj.Bucket, obj.Key, obj.VersionId)
+	}
+	return fmt.Sprintf("%s%s/%s", CheckpointPrefix, obj.Bucket, obj.Key)
+}
+
+func (c *S3Checkpointer) Delete(ctx context.Context, obj files.S3Object) error {
+	_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(CheckpointKey(obj)),
+	})
+	return err
+}
+
+func (c *S3Checkpointer) Load(ctx context.Context, obj files.S3Object) ([]byte, error) {
+	getResp, err := c.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(CheckpointKey(obj)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close checkpoint body for %s: %v", obj.URI(), err)
+		}
+	}(getResp.Body)
+
+	return io.ReadAll(getResp.Body)
+}
+
+func (c *S3Checkpointer) Save(ctx context.Context, obj files.S3Object, data []byte) error {
+	_, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(CheckpointKey(obj)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/octet-stream"),
+	})
+	return err
+}
+
+// checkpoint is the serialized form of a Continuation
+type checkpoint struct {
+	Algorithms []string
+	ETag       string
+	Native     *NativeChecksum
+	Object     files.S3Object
+	PartSizes  []int64
+	Size       int64
+	State      []byte
+	Supplied   map[string]string
+}
+
+// MarshalBinary serializes the continuation including the state of every digest
+func (c *Continuation) MarshalBinary() ([]byte, error) {
+	state, err := c.state.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGob(checkpoint{
+		Algorithms: c.algorithms(),
+		ETag:       c.ETag,
+		Native:     c.Native,
+		Object:     c.Object,
+		PartSizes:  c.PartSizes,
+		Size:       c.Size,
+		State:      state,
+		Supplied:   c.Supplied,
+	})
+}
+
+func (c *Continuation) algorithms() []string {
+	algorithms := make([]string, 0, len(c.state.digests))
+	for algorithm := range c.state.digests {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+
+	return algorithms
+}
+
+// RestoreContinuation deserializes a continuation created by Continuation.MarshalBinary
+func (c *S3Calculator) RestoreContinuation(data []byte) (*Continuation, error) {
+	var cp checkpoint
+	if err := decodeGob(data, &cp); err != nil {
+		return nil, ErrorInvalidCheckpoint(err.Error())
+	}
+
+	for _, algorithm := range cp.Algorithms {
+		if _, ok := c.hashers[algorithm]; !ok && !IsNativeAlgorithm(algorithm) {
+			return nil, ErrorUnsupportedAlgorithm(algorithm)
+		}
+	}
+
+	state, err := c.newHashState(cp.Algorithms, cp.PartSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := state.UnmarshalBinary(cp.State); err != nil {
+		return nil, err
+	}
+
+	return &Continuation{
+		ETag:      cp.ETag,
+		Native:    cp.Native,
+		Object:    cp.Object,
+		PartSizes: cp.PartSizes,
+		Size:      cp.Size,
+		Supplied:  cp.Supplied,
+		state:     state,
+	}, nil
+}
+
+// loadCheckpoint returns the checkpointed continuation of a calculation, or nil when there is
+// none or it no longer applies (the object changed or different algorithms are required)
+func (c *S3Calculator) loadCheckpoint(ctx context.Context, fresh *Continuation) *Continuation {
+	obj := fresh.Object
+
+	data, err := c.checkpointer.Load(ctx, obj)
+	if err != nil {
+		log.Printf("Failed to load checkpoint for %s: %v", obj.URI(), err)
+		return nil
+	}
+
+	if data == nil {
+		return nil
+	}
+
+	cont, err := c.RestoreContinuation(data)
+	if err == nil && (cont.ETag != fresh.ETag || cont.Size != fresh.Size) {
+		err = ErrorInvalidCheckpoint("object has changed")
+	}
+	if err == nil && !slices.Equal(cont.algorithms(), fresh.algorithms()) {
+		err = ErrorInvalidCheckpoint("algorithms have changed")
+	}
+
+	if err != nil {
+		log.Printf("Discarding checkpoint for %s: %v", obj.URI(), err)
+		c.deleteCheckpoint(ctx, obj)
+		return nil
+	}
+
+	log.Printf("Restored checkpoint for %s at offset %d of %d bytes", obj.URI(), cont.Offset(), cont.Size)
+	return cont
+}
+
+// saveCheckpoint persists the continuation, a failure is logged as the calculation can continue
+func (c *S3Calculator) saveCheckpoint(ctx context.Context, cont *Continuation) {
+	data, err := cont.MarshalBinary()
+	if err == nil {
+		err = c.checkpointer.Save(ctx, cont.Object, data)
+	}
+
+	if err != nil {
+		log.Printf("Failed to save checkpoint for %s at offset %d: %v", cont.Object.URI(), cont.Offset(), err)
+		return
+	}
+
+	log.Printf("Saved checkpoint for %s at offset %d of %d bytes", cont.Object.URI(), cont.Offset(), cont.Size)
+}
+
+func (c *S3Calculator) deleteCheckpoint(ctx context.Context, obj files.S3Object) {
+	if err := c.checkpointer.Delete(ctx, obj); err != nil {
+		log.Printf("Failed to delete checkpoint for %s: %v", obj.URI(), err)
+	}
+}
@@ -0,0 +1,178 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"duracloud/internal/files"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// memoryCheckpointer keeps checkpoints in memory for tests
+type memoryCheckpointer struct {
+	checkpoints map[string][]byte
+	saves       int
+}
+
+func newMemoryCheckpointer() *memoryCheckpointer {
+	return &memoryCheckpointer{checkpoints: make(map[string][]byte)}
+}
+
+func (m *memoryCheckpointer) Delete(ctx context.Context, obj files.S3Object) error {
+	delete(m.checkpoints, obj.URI())
+	return nil
+}
+
+func (m *memoryCheckpointer) Load(ctx context.Context, obj files.S3Object) ([]byte, error) {
+	return m.checkpoints[obj.URI()], nil
+}
+
+func (m *memoryCheckpointer) Save(ctx context.Context, obj files.S3Object, data []byte) error {
+	m.checkpoints[obj.URI()] = data
+	m.saves++
+	return nil
+}
+
+// failingRangeClient fails every ranged GET from an offset, simulating an invocation that stops
+type failingRangeClient struct {
+	*mockS3Client
+	failFrom int64
+}
+
+func (f *failingRangeClient) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	var start int64
+	if input.Range != nil {
+		_, _ = fmt.Sscanf(*input.Range, "bytes=%d-", &start)
+	}
+
+	if start >= f.failFrom {
+		return nil, &smithy.GenericAPIError{Code: "InternalError", Message: "simulated failure"}
+	}
+
+	return f.mockS3Client.GetObject(ctx, input, opts...)
+}
+
+func newCheckpointCalculator(t *testing.T, client S3ClientInterface, checkpointer Checkpointer) *S3Calculator {
+	t.Helper()
+
+	calc, err := NewS3CalculatorWithAlgorithms(client, DefaultAlgorithms...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calc.rangeConcurrency = 1
+	calc.rangeSize = 1000
+	calc.rangeThreshold = 0
+	calc.checkpointInterval = 3000
+
+	return calc.WithCheckpointer(checkpointer)
+}
+
+func TestCompositeDigest_MarshalBinary(t *testing.T) {
+	content := bytes.Repeat([]byte("composite digest state "), 100)
+	partSizes := UniformPartSizes(int64(len(content)), 1000)
+
+	expected := newCompositeDigest(sha256.New, encodeBase64, partSizes)
+	_, _ = expected.Write(content)
+
+	// Stop partway through the second part and continue in a new digest
+	first := newCompositeDigest(sha256.New, encodeBase64, partSizes)
+	_, _ = first.Write(content[:1500])
+
+	state, err := first.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := newCompositeDigest(sha256.New, encodeBase64, partSizes)
+	if err := second.UnmarshalBinary(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = second.Write(content[1500:])
+
+	if second.Sum() != expected.Sum() {
+		t.Errorf("resumed digest mismatch: expected %s, got %s", expected.Sum(), second.Sum())
+	}
+}
+
+func TestS3Calculator_ResumeFromCheckpoint(t *testing.T) {
+	content := bytes.Repeat([]byte("DuraCloud checkpoint content. "), 400) // 12,000 bytes
+
+	mockClient := newMockS3Client()
+	mockClient.addMultipartObject("test-bucket", "checkpoint.bin", content, []int64{5000, 5000, 2000})
+	obj := files.NewS3Object("test-bucket", "checkpoint.bin")
+	checkpointer := newMemoryCheckpointer()
+
+	// The first invocation stops after a checkpoint is saved at 6,000 bytes
+	failing := &failingRangeClient{mockS3Client: mockClient, failFrom: 7000}
+	_, err := newCheckpointCalculator(t, failing, checkpointer).Calculate(context.Background(), obj)
+	if err == nil || !strings.Contains(err.Error(), "object not retrieved") {
+		t.Fatalf("expected simulated failure, got %v", err)
+	}
+
+	data := checkpointer.checkpoints[obj.URI()]
+	if data == nil {
+		t.Fatal("expected a checkpoint to be saved")
+	}
+
+	calc := newCheckpointCalculator(t, mockClient, checkpointer)
+	cont, err := calc.RestoreContinuation(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cont.Offset() != 6000 {
+		t.Errorf("expected checkpoint at offset 6000, got %d", cont.Offset())
+	}
+
+	// The next invocation resumes from the checkpoint
+	result, err := calc.Calculate(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Checksums[AlgorithmMD5] != calculateMD5(content) {
+		t.Errorf("md5 mismatch: expected %s, got %s", calculateMD5(content), result.Checksums[AlgorithmMD5])
+	}
+
+	if result.ETag != NormalizeETag(mockClient.etag("test-bucket/checkpoint.bin")) {
+		t.Errorf("ETag mismatch: expected %s, got %s", mockClient.etag("test-bucket/checkpoint.bin"), result.ETag)
+	}
+
+	if _, exists := checkpointer.checkpoints[obj.URI()]; exists {
+		t.Error("expected checkpoint to be deleted on completion")
+	}
+}
+
+func TestS3Calculator_StaleCheckpointDiscarded(t *testing.T) {
+	original := bytes.Repeat([]byte("a"), 8000)
+	replaced := bytes.Repeat([]byte("b"), 8000)
+
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "stale.bin", original)
+	obj := files.NewS3Object("test-bucket", "stale.bin")
+	checkpointer := newMemoryCheckpointer()
+
+	failing := &failingRangeClient{mockS3Client: mockClient, failFrom: 4000}
+	_, _ = newCheckpointCalculator(t, failing, checkpointer).Calculate(context.Background(), obj)
+
+	if checkpointer.checkpoints[obj.URI()] == nil {
+		t.Fatal("expected a checkpoint to be saved")
+	}
+
+	// The object is overwritten before the next invocation
+	mockClient.addObject("test-bucket", "stale.bin", replaced)
+
+	result, err := newCheckpointCalculator(t, mockClient, checkpointer).Calculate(context.Background(), obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Checksums[AlgorithmMD5] != calculateMD5(replaced) {
+		t.Errorf("md5 mismatch: expected %s, got %s", calculateMD5(replaced), result.Checksums[AlgorithmMD5])
+	}
+}
@@ -0,0 +1,372 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// digest accumulates streamed content and returns its encoded checksum, the
+// progress of a digest can be serialized to resume it later
+type digest interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	io.Writer
+	Sum() string
+}
+
+// encodeFunc converts a raw digest into its stored representation
+type encodeFunc func([]byte) string
+
+var (
+	encodeBase64 encodeFunc = base64.StdEncoding.EncodeToString
+	encodeHex    encodeFunc = hex.EncodeToString
+)
+
+// wholeDigest hashes the entire content with a single hasher
+type wholeDigest struct {
+	hasher hash.Hash
+	encode encodeFunc
+}
+
+func newWholeDigest(hasherFunc func() hash.Hash, encode encodeFunc) *wholeDigest {
+	return &wholeDigest{
+		hasher: hasherFunc(),
+		encode: encode,
+	}
+}
+
+func (d *wholeDigest) Write(p []byte) (int, error) {
+	return d.hasher.Write(p)
+}
+
+func (d *wholeDigest) Sum() string {
+	return d.encode(d.hasher.Sum(nil))
+}
+
+func (d *wholeDigest) MarshalBinary() ([]byte, error) {
+	return marshalHash(d.hasher)
+}
+
+func (d *wholeDigest) UnmarshalBinary(data []byte) error {
+	return unmarshalHash(d.hasher, data)
+}
+
+// compositeDigest hashes each part of a multipart object, then hashes the concatenated
+// part digests and suffixes the number of parts. This is how S3 builds multipart ETags
+// (md5-of-md5s-N) and COMPOSITE additional checksums.
+type compositeDigest struct {
+	hasherFunc func() hash.Hash
+	encode     encodeFunc
+	partSizes  []int64
+	part       int
+	written    int64
+	current    hash.Hash
+	digests    []byte
+}
+
+func newCompositeDigest(hasherFunc func() hash.Hash, encode encodeFunc, partSizes []int64) *compositeDigest {
+	return &compositeDigest{
+		hasherFunc: hasherFunc,
+		encode:     encode,
+		partSizes:  partSizes,
+		current:    hasherFunc(),
+	}
+}
+
+// newETagDigest returns a digest that reconstructs an S3 ETag, partSizes is nil for
+// objects that were not uploaded in parts
+func newETagDigest(partSizes []int64) digest {
+	if len(partSizes) == 0 {
+		return newWholeDigest(md5.New, encodeHex)
+	}
+	return newCompositeDigest(md5.New, encodeHex, partSizes)
+}
+
+// Write hashes p, starting a new part digest whenever a part boundary is crossed
+func (d *compositeDigest) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		if d.part >= len(d.partSizes)-1 {
+			// The final part takes everything that remains
+			d.current.Write(p)
+			d.written += int64(len(p))
+			break
+		}
+
+		remaining := d.partSizes[d.part] - d.written
+		if int64(len(p)) < remaining {
+			d.current.Write(p)
+			d.written += int64(len(p))
+			break
+		}
+
+		d.current.Write(p[:remaining])
+		p = p[remaining:]
+		d.finishPart()
+	}
+
+	return n, nil
+}
+
+// Sum returns the encoded digest of the part digests suffixed by the part count
+func (d *compositeDigest) Sum() string {
+	digests := append(append([]byte{}, d.digests...), d.current.Sum(nil)...)
+
+	composite := d.hasherFunc()
+	composite.Write(digests)
+
+	return fmt.Sprintf("%s-%d", d.encode(composite.Sum(nil)), len(d.partSizes))
+}
+
+func (d *compositeDigest) finishPart() {
+	d.digests = append(d.digests, d.current.Sum(nil)...)
+	d.current = d.hasherFunc()
+	d.written = 0
+	d.part++
+}
+
+// compositeState is the serialized progress of a compositeDigest
+type compositeState struct {
+	Current []byte
+	Digests []byte
+	Part    int
+	Written int64
+}
+
+func (d *compositeDigest) MarshalBinary() ([]byte, error) {
+	current, err := marshalHash(d.current)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeGob(compositeState{
+		Current: current,
+		Digests: d.digests,
+		Part:    d.part,
+		Written: d.written,
+	})
+}
+
+func (d *compositeDigest) UnmarshalBinary(data []byte) error {
+	var state compositeState
+	if err := decodeGob(data, &state); err != nil {
+		return err
+	}
+
+	if state.Part >= len(d.partSizes) {
+		return ErrorInvalidCheckpoint(fmt.Sprintf("part %d of %d", state.Part+1, len(d.partSizes)))
+	}
+
+	current := d.hasherFunc()
+	if err := unmarshalHash(current, state.Current); err != nil {
+		return err
+	}
+
+	d.current = current
+	d.digests = state.Digests
+	d.part = state.Part
+	d.written = state.Written
+	return nil
+}
+
+// marshalHash returns the internal state of a hasher, the standard library hashes
+// all implement encoding.BinaryMarshaler but a custom hasher may not
+func marshalHash(hasher hash.Hash) ([]byte, error) {
+	marshaler, ok := hasher.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, ErrorStateNotSerializable(fmt.Sprintf("%T", hasher))
+	}
+	return marshaler.MarshalBinary()
+}
+
+func unmarshalHash(hasher hash.Hash, data []byte) error {
+	unmarshaler, ok := hasher.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return ErrorStateNotSerializable(fmt.Sprintf("%T", hasher))
+	}
+	return unmarshaler.UnmarshalBinary(data)
+}
+
+func encodeGob(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeGob(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// NormalizeETag strips the surrounding quotes S3 returns with ETag values
+func NormalizeETag(etag string) string {
+	return strings.Trim(etag, `"`)
+}
+
+// PartsCountFromETag returns the number of parts encoded in a multipart ETag, or 0 when
+// the object was not uploaded in parts
+func PartsCountFromETag(etag string) int {
+	etag = NormalizeETag(etag)
+
+	idx := strings.LastIndex(etag, "-")
+	if idx == -1 {
+		return 0
+	}
+
+	parts, err := strconv.Atoi(etag[idx+1:])
+	if err != nil || parts < 1 {
+		return 0
+	}
+
+	return parts
+}
+
+// UniformPartSizes splits an object into parts of partSize, with the remainder in the last part
+func UniformPartSizes(fileSize, partSize int64) []int64 {
+	if partSize <= 0 || fileSize <= partSize {
+		return []int64{fileSize}
+	}
+
+	var sizes []int64
+	for remaining := fileSize; remaining > 0; remaining -= partSize {
+		sizes = append(sizes, min(partSize, remaining))
+	}
+
+	return sizes
+}
+
+// hashState holds the digests of a calculation in progress
+type hashState struct {
+	digests map[string]digest
+	etag    digest // nil when the md5 digest is also the ETag
+	parts   int
+	written int64
+}
+
+func (s *hashState) Write(p []byte) (int, error) {
+	for _, d := range s.digests {
+		_, _ = d.Write(p)
+	}
+
+	if s.etag != nil {
+		_, _ = s.etag.Write(p)
+	}
+
+	s.written += int64(len(p))
+	return len(p), nil
+}
+
+// writeConcurrent hashes p with each digest in its own goroutine, so large
+// chunks are hashed at the speed of the slowest algorithm rather than the sum
+func (s *hashState) writeConcurrent(p []byte) {
+	var wg sync.WaitGroup
+	for _, d := range s.all() {
+		wg.Add(1)
+		go func(d digest) {
+			defer wg.Done()
+			_, _ = d.Write(p)
+		}(d)
+	}
+	wg.Wait()
+
+	s.written += int64(len(p))
+}
+
+func (s *hashState) all() []digest {
+	all := make([]digest, 0, len(s.digests)+1)
+	for _, d := range s.digests {
+		all = append(all, d)
+	}
+
+	if s.etag != nil {
+		all = append(all, s.etag)
+	}
+
+	return all
+}
+
+// hashStateData is the serialized progress of a hashState
+type hashStateData struct {
+	Digests map[string][]byte
+	ETag    []byte
+	Written int64
+}
+
+func (s *hashState) MarshalBinary() ([]byte, error) {
+	data := hashStateData{
+		Digests: make(map[string][]byte, len(s.digests)),
+		Written: s.written,
+	}
+
+	for algorithm, d := range s.digests {
+		state, err := d.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data.Digests[algorithm] = state
+	}
+
+	if s.etag != nil {
+		state, err := s.etag.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data.ETag = state
+	}
+
+	return encodeGob(data)
+}
+
+// UnmarshalBinary restores the progress of every digest, the state must have been
+// created with the same algorithms and part sizes
+func (s *hashState) UnmarshalBinary(b []byte) error {
+	var data hashStateData
+	if err := decodeGob(b, &data); err != nil {
+		return err
+	}
+
+	if len(data.Digests) != len(s.digests) || (data.ETag == nil) != (s.etag == nil) {
+		return ErrorInvalidCheckpoint("digests do not match")
+	}
+
+	for algorithm, d := range s.digests {
+		state, ok := data.Digests[algorithm]
+		if !ok {
+			return ErrorInvalidCheckpoint("missing algorithm " + algorithm)
+		}
+
+		if err := d.UnmarshalBinary(state); err != nil {
+			return err
+		}
+	}
+
+	if s.etag != nil {
+		if err := s.etag.UnmarshalBinary(data.ETag); err != nil {
+			return err
+		}
+	}
+
+	s.written = data.Written
+	return nil
+}
+
+func (s *hashState) checksums() map[string]string {
+	checksums := make(map[string]string, len(s.digests))
+	for algorithm, d := range s.digests {
+		checksums[algorithm] = d.Sum()
+	}
+
+	return checksums
+}
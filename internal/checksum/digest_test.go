@@ -0,0 +1,89 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"fmt"
+	"testing"
+)
+
+func TestPartsCountFromETag(t *testing.T) {
+	tests := []struct {
+		etag     string
+		expected int
+	}{
+		{etag: "d41d8cd98f00b204e9800998ecf8427e", expected: 0},
+		{etag: `"d41d8cd98f00b204e9800998ecf8427e"`, expected: 0},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-1", expected: 1},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-5", expected: 5},
+		{etag: `"d41d8cd98f00b204e9800998ecf8427e-12"`, expected: 12},
+		{etag: "d41d8cd98f00b204e9800998ecf8427e-x", expected: 0},
+		{etag: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.etag, func(t *testing.T) {
+			if result := PartsCountFromETag(tt.etag); result != tt.expected {
+				t.Errorf("expected %d parts, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestUniformPartSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileSize int64
+		partSize int64
+		expected []int64
+	}{
+		{name: "remainder", fileSize: 25, partSize: 10, expected: []int64{10, 10, 5}},
+		{name: "exact", fileSize: 20, partSize: 10, expected: []int64{10, 10}},
+		{name: "smaller than part", fileSize: 5, partSize: 10, expected: []int64{5}},
+		{name: "no part size", fileSize: 5, partSize: 0, expected: []int64{5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := UniformPartSizes(tt.fileSize, tt.partSize)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i := range tt.expected {
+				if result[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestETagDigest_WriteAcrossBoundaries(t *testing.T) {
+	content := []byte("abcdefghijklmnopqrstuvwxyz")
+	partSizes := []int64{10, 10, 6}
+
+	whole := newETagDigest(partSizes)
+	_, _ = whole.Write(content)
+
+	// Writes that straddle part boundaries must produce the same result
+	chunked := newETagDigest(partSizes)
+	for _, chunk := range [][]byte{content[:3], content[3:15], content[15:20], content[20:]} {
+		_, _ = chunked.Write(chunk)
+	}
+
+	if whole.Sum() != chunked.Sum() {
+		t.Errorf("ETag mismatch: whole=%s chunked=%s", whole.Sum(), chunked.Sum())
+	}
+}
+
+func TestETagDigest_SinglePartUpload(t *testing.T) {
+	// A multipart upload with one part still has a composite ETag
+	content := []byte("one part")
+	d := newETagDigest([]int64{int64(len(content))})
+	_, _ = d.Write(content)
+
+	partDigest := md5.Sum(content)
+	expected := fmt.Sprintf("%x-1", md5.Sum(partDigest[:]))
+	if d.Sum() != expected {
+		t.Errorf("ETag mismatch: expected %s, got %s", expected, d.Sum())
+	}
+}
@@ -7,11 +7,19 @@ import (
 
 var (
 	ErrBytesCountDoesNotMatch = errors.New("bytes expected count does not match bytes read")
+	ErrContinuationRequired   = errors.New("checksum calculation requires continuation")
+	ErrCopyingObject          = errors.New("failed to copy object")
+	ErrInvalidCheckpoint      = errors.New("invalid checksum checkpoint")
+	ErrInvalidRepairMode      = errors.New("invalid repair mode")
 	ErrMaxFileSizeExceeded    = errors.New("max file size exceeded")
 	ErrMetadataNotRetrieved   = errors.New("metadata not retrieved")
+	ErrMismatchUnconfirmed    = errors.New("checksum mismatch not confirmed")
+	ErrObjectArchived         = errors.New("object is archived")
 	ErrObjectNotFound         = errors.New("object not found")
 	ErrObjectNotRetrieved     = errors.New("object not retrieved")
 	ErrReadingFromStream      = errors.New("failed to read from stream")
+	ErrStateNotSerializable   = errors.New("hash state is not serializable")
+	ErrUnsupportedAlgorithm   = errors.New("unsupported checksum algorithm")
 )
 
 func ErrorBytesCountDoesNotMatch(uri string, bytesExpected int64, bytesRead int64) error {
@@ -23,13 +31,29 @@ func ErrorBytesCountDoesNotMatch(uri string, bytesExpected int64, bytesRead int6
 	)
 }
 
+func ErrorContinuationRequired(uri string, offset int64, size int64) error {
+	return fmt.Errorf("%w: uri=%s offset=%d size=%d", ErrContinuationRequired, uri, offset, size)
+}
+
+func ErrorCopyingObject(src string, dst string, cause error) error {
+	return fmt.Errorf("%w: src=%s dst=%s cause=%v", ErrCopyingObject, src, dst, cause)
+}
+
+func ErrorInvalidCheckpoint(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCheckpoint, reason)
+}
+
+func ErrorInvalidRepairMode(mode string) error {
+	return fmt.Errorf("%w: mode=%s", ErrInvalidRepairMode, mode)
+}
+
 func ErrorMaxFileSizeExceeded(uri string, fileSize int64) error {
 	return fmt.Errorf("%w: %s=%d bytes (%.2f GB) max=%d bytes (%.2f GB)",
 		ErrMaxFileSizeExceeded,
 		uri,
 		fileSize,
 		float64(fileSize)/(1024*1024*1024),
-		MaxFileSize,
+		int64(MaxFileSize),
 		float64(MaxFileSize)/(1024*1024*1024),
 	)
 }
@@ -38,6 +62,14 @@ func ErrorMetadataNotRetrieved(uri string, cause error) error {
 	return fmt.Errorf("%w: uri=%s cause=%v", ErrMetadataNotRetrieved, uri, cause)
 }
 
+func ErrorMismatchUnconfirmed(uri string, cause error) error {
+	return fmt.Errorf("%w: uri=%s cause=%v", ErrMismatchUnconfirmed, uri, cause)
+}
+
+func ErrorObjectArchived(uri string, storageClass string) error {
+	return fmt.Errorf("%w: uri=%s storageClass=%s", ErrObjectArchived, uri, storageClass)
+}
+
 func ErrorObjectNotFound(uri string) error {
 	return fmt.Errorf("%w: uri=%s", ErrObjectNotFound, uri)
 }
@@ -49,3 +81,11 @@ func ErrorObjectNotRetrieved(uri string, cause error) error {
 func ErrorReadingFromStream(uri string, cause error) error {
 	return fmt.Errorf("%w: uri=%s cause=%v", ErrReadingFromStream, uri, cause)
 }
+
+func ErrorStateNotSerializable(hasher string) error {
+	return fmt.Errorf("%w: hasher=%s", ErrStateNotSerializable, hasher)
+}
+
+func ErrorUnsupportedAlgorithm(algorithm string) error {
+	return fmt.Errorf("%w: algorithm=%s", ErrUnsupportedAlgorithm, algorithm)
+}
@@ -0,0 +1,84 @@
+package checksum
+
+import (
+	"duracloud/internal/db"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LegacyChecksum returns the algorithm and lowercase hex digest of a checksum exported by a legacy
+// repository, which may name the algorithm in upper case or with a dash (i.e. SHA-256) and encode
+// the digest as hex or base64. It is false for an unsupported algorithm or a value that is not a
+// digest of it.
+func LegacyChecksum(name, value string) (string, string, bool) {
+	algorithm := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "")
+	hasherFunc, ok := hasherFuncs[algorithm]
+	if !ok {
+		return "", "", false
+	}
+
+	digest := NormalizeDigest(algorithm, value)
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != hasherFunc().Size() {
+		return "", "", false
+	}
+
+	return algorithm, strings.ToLower(digest), true
+}
+
+// claimLegacy returns the checksums imported from a legacy repository for the deposited version,
+// nil when none were imported or the store does not stage them
+func (v *Verifier) claimLegacy() (map[string]string, error) {
+	stager, ok := v.store.(db.LegacyStager)
+	if !ok {
+		return nil, nil
+	}
+
+	legacy, err := stager.ClaimLegacy(v.obj)
+	if errors.Is(err, db.ErrLegacyChecksumNotFound) || errors.Is(err, db.ErrLegacyNotConfigured) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return legacy.Checksums, nil
+}
+
+// legacyStatus returns whether a deposit arrived intact, a calculation that did not complete is
+// compared when its verification does
+func legacyStatus(legacy map[string]string, result Result, err error) string {
+	if err != nil {
+		return db.LegacyPending
+	}
+	if legacyMismatch(legacy, result) != "" {
+		return db.LegacyMismatch
+	}
+	return db.LegacyIntact
+}
+
+// legacyMismatch describes every imported legacy checksum that does not match the calculated
+// result, empty if all match. Only calculated algorithms are compared.
+func legacyMismatch(legacy map[string]string, result Result) string {
+	algorithms := make([]string, 0, len(legacy))
+	for algorithm := range legacy {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
+	var mismatches []string
+	for _, algorithm := range algorithms {
+		calculated, ok := result.Checksums[algorithm]
+		if ok && calculated != legacy[algorithm] {
+			mismatches = append(mismatches, fmt.Sprintf("%s calculated=%s legacy=%s",
+				algorithm, calculated, legacy[algorithm]))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("checksum does not match legacy checksum: %s", strings.Join(mismatches, "; "))
+}
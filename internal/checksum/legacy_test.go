@@ -0,0 +1,81 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"duracloud/internal/db"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLegacyChecksum(t *testing.T) {
+	content := []byte("legacy content")
+	sum := sha256.Sum256(content)
+	sha256Hex := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name      string
+		algorithm string
+		value     string
+		expected  string
+		ok        bool
+	}{
+		{"md5", "MD5", strings.ToUpper(calculateMD5(content)), calculateMD5(content), true},
+		{"dashed algorithm", "SHA-256", sha256Hex, sha256Hex, true},
+		{"base64", "sha256", base64.StdEncoding.EncodeToString(sum[:]), sha256Hex, true},
+		{"unsupported algorithm", "crc32", "abcd", "", false},
+		{"wrong length", "sha256", calculateMD5(content), "", false},
+		{"not a digest", "md5", "not-a-digest", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, checksum, ok := LegacyChecksum(tt.algorithm, tt.value)
+			if ok != tt.ok || checksum != tt.expected {
+				t.Errorf("expected %q (%v), got %q (%v)", tt.expected, tt.ok, checksum, ok)
+			}
+		})
+	}
+}
+
+func TestLegacyMismatch(t *testing.T) {
+	content := []byte("legacy content")
+	sum := sha256.Sum256(content)
+	result := Result{Checksums: map[string]string{
+		AlgorithmMD5:    calculateMD5(content),
+		AlgorithmSHA256: hex.EncodeToString(sum[:]),
+	}}
+
+	tests := []struct {
+		name     string
+		legacy   map[string]string
+		mismatch string
+		status   string
+	}{
+		{"matches", map[string]string{AlgorithmMD5: calculateMD5(content)}, "", db.LegacyIntact},
+		{"matches every algorithm", map[string]string{AlgorithmMD5: calculateMD5(content), AlgorithmSHA256: hex.EncodeToString(sum[:])}, "", db.LegacyIntact},
+		{"does not match", map[string]string{AlgorithmMD5: calculateMD5([]byte("other content"))}, "md5 calculated=", db.LegacyMismatch},
+		{"not calculated", map[string]string{AlgorithmSHA512: "abc"}, "", db.LegacyIntact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mismatch := legacyMismatch(tt.legacy, result)
+			if tt.mismatch == "" && mismatch != "" {
+				t.Errorf("expected no mismatch, got %s", mismatch)
+			}
+			if tt.mismatch != "" && !strings.Contains(mismatch, tt.mismatch) {
+				t.Errorf("expected mismatch containing %q, got %q", tt.mismatch, mismatch)
+			}
+			if status := legacyStatus(tt.legacy, result, nil); status != tt.status {
+				t.Errorf("expected status %s, got %s", tt.status, status)
+			}
+		})
+	}
+
+	if status := legacyStatus(map[string]string{AlgorithmMD5: "abc"}, Result{}, errors.New("read failed")); status != db.LegacyPending {
+		t.Errorf("expected a failed calculation to be pending, got %s", status)
+	}
+}
@@ -0,0 +1,118 @@
+package checksum
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// NativeAlgorithmPrefix identifies digests calculated in the S3 additional checksum format
+	NativeAlgorithmPrefix = "s3-"
+
+	compositeSuffix = "-composite"
+)
+
+// crc64NVMETable is built from the bit-reversed form crc64.MakeTable expects of the CRC-64/NVME
+// polynomial, whose normal form is 0xad93d23594c93659
+var crc64NVMETable = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// nativeHasherFuncs maps S3 additional checksum algorithms to their hash functions
+var nativeHasherFuncs = map[types.ChecksumAlgorithm]func() hash.Hash{
+	types.ChecksumAlgorithmCrc32: func() hash.Hash { return crc32.NewIEEE() },
+	types.ChecksumAlgorithmCrc32c: func() hash.Hash {
+		return crc32.New(crc32cTable)
+	},
+	types.ChecksumAlgorithmCrc64nvme: func() hash.Hash {
+		return crc64.New(crc64NVMETable)
+	},
+	types.ChecksumAlgorithmSha1:   sha1.New,
+	types.ChecksumAlgorithmSha256: sha256.New,
+}
+
+// NativeChecksum is an S3 additional checksum (x-amz-checksum-*) supplied by a client on upload
+type NativeChecksum struct {
+	Algorithm types.ChecksumAlgorithm
+	Type      types.ChecksumType
+	Value     string
+}
+
+// Key returns the algorithm key used to store the checksum alongside calculated digests
+func (n NativeChecksum) Key() string {
+	return NativeAlgorithmKey(n.Algorithm, n.Type)
+}
+
+// NativeAlgorithmKey returns the algorithm key for an S3 additional checksum (i.e. s3-crc32c)
+func NativeAlgorithmKey(algorithm types.ChecksumAlgorithm, checksumType types.ChecksumType) string {
+	key := NativeAlgorithmPrefix + strings.ToLower(string(algorithm))
+	if checksumType == types.ChecksumTypeComposite {
+		key += compositeSuffix
+	}
+	return key
+}
+
+// IsNativeAlgorithm checks if an algorithm key refers to an S3 additional checksum
+func IsNativeAlgorithm(key string) bool {
+	return strings.HasPrefix(key, NativeAlgorithmPrefix)
+}
+
+// parseNativeAlgorithmKey returns the hash function for a native algorithm key and
+// whether the checksum is a composite of part checksums
+func parseNativeAlgorithmKey(key string) (func() hash.Hash, bool, bool) {
+	if !IsNativeAlgorithm(key) {
+		return nil, false, false
+	}
+
+	name := strings.TrimPrefix(key, NativeAlgorithmPrefix)
+	composite := strings.HasSuffix(name, compositeSuffix)
+	name = strings.TrimSuffix(name, compositeSuffix)
+
+	hasherFunc, ok := nativeHasherFuncs[types.ChecksumAlgorithm(strings.ToUpper(name))]
+	return hasherFunc, composite, ok
+}
+
+// nativeChecksumFromHead returns the additional checksum from a checksum mode HeadObject
+// response, preferring the strongest algorithm when more than one is present
+func nativeChecksumFromHead(headResp *s3.HeadObjectOutput) *NativeChecksum {
+	candidates := []struct {
+		algorithm types.ChecksumAlgorithm
+		value     *string
+	}{
+		{types.ChecksumAlgorithmSha256, headResp.ChecksumSHA256},
+		{types.ChecksumAlgorithmSha1, headResp.ChecksumSHA1},
+		{types.ChecksumAlgorithmCrc64nvme, headResp.ChecksumCRC64NVME},
+		{types.ChecksumAlgorithmCrc32c, headResp.ChecksumCRC32C},
+		{types.ChecksumAlgorithmCrc32, headResp.ChecksumCRC32},
+	}
+
+	for _, candidate := range candidates {
+		if candidate.value == nil || *candidate.value == "" {
+			continue
+		}
+
+		checksumType := headResp.ChecksumType
+		if checksumType == "" {
+			// Composite checksums carry the part count like multipart ETags
+			checksumType = types.ChecksumTypeFullObject
+			if strings.Contains(*candidate.value, "-") {
+				checksumType = types.ChecksumTypeComposite
+			}
+		}
+
+		return &NativeChecksum{
+			Algorithm: candidate.algorithm,
+			Type:      checksumType,
+			Value:     *candidate.value,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package checksum
+
+import (
+	"context"
+	"crypto/sha256"
+	"duracloud/internal/files"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestNativeAlgorithmKey(t *testing.T) {
+	tests := []struct {
+		algorithm    types.ChecksumAlgorithm
+		checksumType types.ChecksumType
+		expected     string
+	}{
+		{types.ChecksumAlgorithmCrc32c, types.ChecksumTypeFullObject, "s3-crc32c"},
+		{types.ChecksumAlgorithmSha256, types.ChecksumTypeComposite, "s3-sha256-composite"},
+		{types.ChecksumAlgorithmCrc64nvme, "", "s3-crc64nvme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			key := NativeAlgorithmKey(tt.algorithm, tt.checksumType)
+			if key != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, key)
+			}
+
+			_, composite, ok := parseNativeAlgorithmKey(key)
+			if !ok {
+				t.Fatalf("expected %s to parse", key)
+			}
+
+			if composite != (tt.checksumType == types.ChecksumTypeComposite) {
+				t.Errorf("composite mismatch for %s", key)
+			}
+		})
+	}
+
+	if _, _, ok := parseNativeAlgorithmKey("s3-md5"); ok {
+		t.Error("expected s3-md5 to be unsupported")
+	}
+}
+
+func TestNativeHasherFuncs_CheckValues(t *testing.T) {
+	// Standard CRC check values for the input "123456789"
+	tests := []struct {
+		algorithm types.ChecksumAlgorithm
+		expected  string
+	}{
+		{types.ChecksumAlgorithmCrc32, "cbf43926"},
+		{types.ChecksumAlgorithmCrc32c, "e3069283"},
+		{types.ChecksumAlgorithmCrc64nvme, "ae8b14860a799888"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			d := newWholeDigest(nativeHasherFuncs[tt.algorithm], encodeHex)
+			_, _ = d.Write([]byte("123456789"))
+			if d.Sum() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, d.Sum())
+			}
+		})
+	}
+}
+
+func TestS3Calculator_NativeChecksum(t *testing.T) {
+	content := []byte("client supplied checksum content")
+
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.Checksum(content, crc32.MakeTable(crc32.Castagnoli)))
+
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "native.txt", content)
+	mockClient.addNativeChecksum("test-bucket", "native.txt", NativeChecksum{
+		Algorithm: types.ChecksumAlgorithmCrc32c,
+		Type:      types.ChecksumTypeFullObject,
+		Value:     base64.StdEncoding.EncodeToString(crc),
+	})
+
+	calc, err := NewS3CalculatorWithAlgorithms(mockClient, DefaultAlgorithms...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "native.txt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Native == nil {
+		t.Fatal("expected native checksum to be reported")
+	}
+
+	if result.Checksums[result.Native.Key()] != result.Native.Value {
+		t.Errorf("native checksum mismatch: calculated=%s s3=%s",
+			result.Checksums[result.Native.Key()], result.Native.Value)
+	}
+}
+
+func TestS3Calculator_NativeCompositeChecksum(t *testing.T) {
+	content := []byte("composite checksum content split into three parts")
+	partSizes := []int64{20, 20, int64(len(content) - 40)}
+
+	var partDigests []byte
+	offset := int64(0)
+	for _, size := range partSizes {
+		digest := sha256.Sum256(content[offset : offset+size])
+		partDigests = append(partDigests, digest[:]...)
+		offset += size
+	}
+	composite := sha256.Sum256(partDigests)
+
+	mockClient := newMockS3Client()
+	mockClient.addMultipartObject("test-bucket", "composite.bin", content, partSizes)
+	mockClient.addNativeChecksum("test-bucket", "composite.bin", NativeChecksum{
+		Algorithm: types.ChecksumAlgorithmSha256,
+		Type:      types.ChecksumTypeComposite,
+		Value:     base64.StdEncoding.EncodeToString(composite[:]) + "-3",
+	})
+
+	calc := NewS3Calculator(mockClient)
+	result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "composite.bin"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	key := NativeAlgorithmKey(types.ChecksumAlgorithmSha256, types.ChecksumTypeComposite)
+	if result.Checksums[key] != result.Native.Value {
+		t.Errorf("composite checksum mismatch: calculated=%s s3=%s", result.Checksums[key], result.Native.Value)
+	}
+}
@@ -0,0 +1,236 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/files"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	// RangedReadThreshold is the object size from which content is read with concurrent ranged GETs
+	RangedReadThreshold = 1024 * 1024 * 1024 // 1GB
+
+	// RangeConcurrency is the number of ranges fetched at once, memory is bounded
+	// by RangeConcurrency * RangeSize (128MB)
+	RangeConcurrency = 8
+	RangeSize        = 16 * 1024 * 1024 // 16MB
+
+	// ContinuationReserve is the time kept back from the context deadline to hand off a continuation
+	ContinuationReserve = 30 * time.Second
+)
+
+// Continuation holds a calculation that stopped before the context deadline, it is resumed
+// from Offset by S3Calculator.Resume or, when serialized, by S3Calculator.RestoreContinuation
+type Continuation struct {
+	ETag      string // the object must not change between calls
+	Native    *NativeChecksum
+	Object    files.S3Object
+	PartSizes []int64
+	Size      int64
+	Supplied  map[string]string
+	state     *hashState
+}
+
+// Offset returns the number of bytes hashed so far
+func (c *Continuation) Offset() int64 {
+	return c.state.written
+}
+
+// result returns the checksums once every byte has been hashed
+func (c *Continuation) result() (Result, error) {
+	if c.state.written != c.Size {
+		return Result{}, ErrorBytesCountDoesNotMatch(c.Object.URI(), c.Size, c.state.written)
+	}
+
+	result := Result{
+		Checksums: c.state.checksums(),
+		Native:    c.Native,
+		Parts:     c.state.parts,
+		Size:      c.state.written,
+		Supplied:  c.Supplied,
+	}
+
+	if c.state.etag != nil {
+		result.ETag = c.state.etag.Sum()
+	} else {
+		result.ETag = result.Checksums[AlgorithmMD5]
+	}
+
+	log.Printf("Successfully calculated checksums for %s: %d bytes, checksums: %v",
+		c.Object.URI(), result.Size, result.Checksums)
+
+	return result, nil
+}
+
+// Resume continues a calculation that was handed off with ErrContinuationRequired
+func (c *S3Calculator) Resume(ctx context.Context, cont *Continuation) (Result, error) {
+	log.Printf("Resuming checksum calculation for %s at offset %d of %d bytes",
+		cont.Object.URI(), cont.Offset(), cont.Size)
+
+	return c.hashRanges(ctx, cont)
+}
+
+// hashRanges fetches ranges concurrently and hashes them in order. When the context
+// deadline is too close to finish it returns the continuation with ErrContinuationRequired.
+func (c *S3Calculator) hashRanges(ctx context.Context, cont *Continuation) (Result, error) {
+	uri := cont.Object.URI()
+	deadline, hasDeadline := ctx.Deadline()
+
+	log.Printf("Reading %s in %d MB ranges (%d concurrent) from offset %d",
+		uri, c.rangeSize/(1024*1024), c.rangeConcurrency, cont.Offset())
+
+	ranges := newRangeReader(ctx, c.s3Client, cont, c.rangeSize, c.rangeConcurrency)
+	defer ranges.close()
+
+	checkpointed := cont.Offset()
+	for {
+		if hasDeadline && time.Until(deadline) < ContinuationReserve {
+			log.Printf("Handing off checksum calculation for %s at offset %d of %d bytes",
+				uri, cont.Offset(), cont.Size)
+			if c.checkpointer != nil && cont.Offset() > checkpointed {
+				c.saveCheckpoint(ctx, cont)
+			}
+			return Result{Continuation: cont}, ErrorContinuationRequired(uri, cont.Offset(), cont.Size)
+		}
+
+		chunk, err := ranges.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Result{}, err
+		}
+
+		cont.state.writeConcurrent(chunk)
+
+		if c.checkpointer != nil && cont.Offset()-checkpointed >= c.checkpointInterval {
+			c.saveCheckpoint(ctx, cont)
+			checkpointed = cont.Offset()
+		}
+	}
+
+	result, err := cont.result()
+	if err == nil && c.checkpointer != nil && checkpointed > 0 {
+		c.deleteCheckpoint(ctx, cont.Object)
+	}
+
+	return result, err
+}
+
+type rangeResult struct {
+	data []byte
+	err  error
+}
+
+// rangeReader fetches the remaining ranges of an object concurrently and returns them in order.
+// Each range is a future queued in order, so no more than concurrency ranges are held at once.
+type rangeReader struct {
+	cancel  context.CancelFunc
+	futures chan chan rangeResult
+	wg      sync.WaitGroup
+}
+
+func newRangeReader(
+	ctx context.Context,
+	s3Client S3ClientInterface,
+	cont *Continuation,
+	rangeSize int64,
+	concurrency int,
+) *rangeReader {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &rangeReader{
+		cancel:  cancel,
+		futures: make(chan chan rangeResult, max(concurrency-1, 0)),
+	}
+
+	offset := cont.Offset()
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer close(r.futures)
+
+		for start := offset; start < cont.Size; start += rangeSize {
+			end := min(start+rangeSize, cont.Size) - 1
+			future := make(chan rangeResult, 1)
+
+			select {
+			case r.futures <- future:
+			case <-ctx.Done():
+				return
+			}
+
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				data, err := getRange(ctx, s3Client, cont, start, end)
+				future <- rangeResult{data: data, err: err}
+			}()
+		}
+	}()
+
+	return r
+}
+
+// next returns the next range in order, io.EOF once every range has been returned
+func (r *rangeReader) next() ([]byte, error) {
+	future, ok := <-r.futures
+	if !ok {
+		return nil, io.EOF
+	}
+
+	result := <-future
+	return result.data, result.err
+}
+
+// close cancels outstanding requests and waits for them to return
+func (r *rangeReader) close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// getRange reads the inclusive byte range start-end, the ETag guards against the object changing
+func getRange(ctx context.Context, s3Client S3ClientInterface, cont *Continuation, start, end int64) ([]byte, error) {
+	uri := cont.Object.URI()
+
+	getResp, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(cont.Object.Bucket),
+		Key:       aws.String(cont.Object.Key),
+		IfMatch:   aws.String(cont.ETag),
+		Range:     aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		VersionId: cont.Object.VersionIdInput(),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, ErrorObjectNotFound(uri)
+		}
+		if isS3InvalidObjectState(err) {
+			// The restored copy expired after the calculation started
+			return nil, ErrorObjectArchived(uri, "unknown")
+		}
+		return nil, ErrorObjectNotRetrieved(uri, err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close body for %s: %v", uri, err)
+		}
+	}(getResp.Body)
+
+	data := make([]byte, end-start+1)
+	n, err := io.ReadFull(getResp.Body, data)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return nil, ErrorBytesCountDoesNotMatch(uri, int64(len(data)), int64(n))
+		}
+		return nil, ErrorReadingFromStream(uri, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,141 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/files"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+func newRangedCalculator(t *testing.T, mockClient *mockS3Client) *S3Calculator {
+	t.Helper()
+
+	calc, err := NewS3CalculatorWithAlgorithms(mockClient, DefaultAlgorithms...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	calc.rangeConcurrency = 3
+	calc.rangeSize = 1000
+	calc.rangeThreshold = 0
+
+	return calc
+}
+
+func TestS3Calculator_RangedMatchesStreamed(t *testing.T) {
+	content := bytes.Repeat([]byte("DuraCloud ranged read content. "), 500) // 15,500 bytes
+
+	tests := []struct {
+		name      string
+		partSizes []int64
+	}{
+		{name: "single part", partSizes: nil},
+		{name: "multipart", partSizes: []int64{5000, 5000, 5000, 500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockS3Client()
+			if tt.partSizes == nil {
+				mockClient.addObject("test-bucket", "ranged.bin", content)
+			} else {
+				mockClient.addMultipartObject("test-bucket", "ranged.bin", content, tt.partSizes)
+			}
+			obj := files.NewS3Object("test-bucket", "ranged.bin")
+
+			streamed, err := NewS3CalculatorWithAlgorithms(mockClient, DefaultAlgorithms...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected, err := streamed.Calculate(context.Background(), obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := newRangedCalculator(t, mockClient).Calculate(context.Background(), obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for algorithm, checksum := range expected.Checksums {
+				if result.Checksums[algorithm] != checksum {
+					t.Errorf("%s mismatch: expected %s, got %s", algorithm, checksum, result.Checksums[algorithm])
+				}
+			}
+
+			if result.ETag != NormalizeETag(mockClient.etag("test-bucket/ranged.bin")) {
+				t.Errorf("ETag mismatch: expected %s, got %s", mockClient.etag("test-bucket/ranged.bin"), result.ETag)
+			}
+		})
+	}
+}
+
+func TestS3Calculator_RangedContinuation(t *testing.T) {
+	content := bytes.Repeat([]byte("DuraCloud continuation content. "), 300)
+
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "continued.bin", content)
+	obj := files.NewS3Object("test-bucket", "continued.bin")
+	calc := newRangedCalculator(t, mockClient)
+
+	// A deadline inside the reserve hands off before reading any content
+	ctx, cancel := context.WithTimeout(context.Background(), ContinuationReserve/2)
+	defer cancel()
+
+	result, err := calc.Calculate(ctx, obj)
+	if !errors.Is(err, ErrContinuationRequired) {
+		t.Fatalf("expected continuation required, got %v", err)
+	}
+
+	if result.Continuation == nil {
+		t.Fatal("expected continuation to be returned")
+	}
+
+	resumed, err := calc.Resume(context.Background(), result.Continuation)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resumed.Checksums[AlgorithmMD5] != calculateMD5(content) {
+		t.Errorf("md5 mismatch: expected %s, got %s", calculateMD5(content), resumed.Checksums[AlgorithmMD5])
+	}
+}
+
+func TestS3Calculator_RangedObjectChanged(t *testing.T) {
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "changed.bin", bytes.Repeat([]byte("a"), 5000))
+	obj := files.NewS3Object("test-bucket", "changed.bin")
+	calc := newRangedCalculator(t, mockClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	result, err := calc.Calculate(ctx, obj)
+	if !errors.Is(err, ErrContinuationRequired) {
+		t.Fatalf("expected continuation required, got %v", err)
+	}
+
+	// The object is replaced before the continuation runs
+	mockClient.addObject("test-bucket", "changed.bin", bytes.Repeat([]byte("b"), 5000))
+
+	_, err = calc.Resume(context.Background(), result.Continuation)
+	if !errors.Is(err, ErrObjectNotRetrieved) {
+		t.Errorf("expected object not retrieved, got %v", err)
+	}
+}
+
+func TestS3Calculator_RangedReadError(t *testing.T) {
+	mockClient := newMockS3Client()
+	mockClient.addObject("test-bucket", "error.bin", bytes.Repeat([]byte("a"), 5000))
+	mockClient.addError("test-bucket", "error.bin", "get", &smithy.GenericAPIError{Code: "InternalError"})
+
+	_, err := newRangedCalculator(t, mockClient).Calculate(context.Background(), files.NewS3Object("test-bucket", "error.bin"))
+	if err == nil || !strings.Contains(err.Error(), "object not retrieved") {
+		t.Errorf("expected object not retrieved error, got %v", err)
+	}
+}
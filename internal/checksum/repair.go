@@ -0,0 +1,272 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// RepairMode sets what Verify does with a corrupted primary whose replica matches the stored checksums
+type RepairMode string
+
+const (
+	RepairModeDryRun RepairMode = "dry-run"
+	RepairModeOff    RepairMode = "off"
+	RepairModeOn     RepairMode = "on"
+)
+
+const (
+	// MaxCopySize is the largest object copied with a single CopyObject request
+	MaxCopySize = 5 * 1024 * 1024 * 1024 // 5GB
+
+	// CopyPartSize is the smallest part of a multipart copy, it grows so no copy exceeds MaxCopyParts
+	CopyPartSize = 512 * 1024 * 1024 // 512MB
+	MaxCopyParts = 10000
+)
+
+// ParseRepairMode parses a repair mode, an empty value is off
+func ParseRepairMode(value string) (RepairMode, error) {
+	mode := RepairMode(strings.ToLower(strings.TrimSpace(value)))
+	switch mode {
+	case "":
+		return RepairModeOff, nil
+	case RepairModeDryRun, RepairModeOff, RepairModeOn:
+		return mode, nil
+	}
+	return RepairModeOff, ErrorInvalidRepairMode(value)
+}
+
+// RepairResult is the outcome of a repair, VersionId is the version created from the replica
+type RepairResult struct {
+	Status    string
+	Message   string
+	VersionId string
+}
+
+// repairRefusal returns why a corrupted primary cannot be replaced by its replica, empty if it can.
+// Only a replica that was read and matched the stored checksums is ever copied.
+func repairRefusal(replica ReplicaResult) string {
+	switch {
+	case replica.Status == db.ReplicaOK:
+		return ""
+	case replica.Status == "":
+		return "replica was not checked"
+	case replica.Failed():
+		return fmt.Sprintf("replica does not match the stored checksums (%s)", replica.Status)
+	default:
+		return fmt.Sprintf("replica is %s", replica.Status)
+	}
+}
+
+// ObjectCopier is implemented by an S3 client that copies object versions, in parts when they
+// are larger than MaxCopySize
+type ObjectCopier interface {
+	HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	CopyObject(ctx context.Context, input *s3.CopyObjectInput, opts ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, input *s3.CreateMultipartUploadInput, opts ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPartCopy(ctx context.Context, input *s3.UploadPartCopyInput, opts ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(ctx context.Context, input *s3.CompleteMultipartUploadInput, opts ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, input *s3.AbortMultipartUploadInput, opts ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// repair replaces a corrupted primary with its replica, which is copied over the primary as a new
+// version and verified against the stored checksums. Only the current version is repaired, copying
+// a noncurrent version would replace the newer versions.
+func (v *Verifier) repair(stored map[string]string, algorithms []string, replica ReplicaResult) RepairResult {
+	if reason := repairRefusal(replica); reason != "" {
+		return RepairResult{Status: db.RepairRefused, Message: reason}
+	}
+
+	current, err := v.s3Client.HeadObject(v.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(v.obj.Bucket),
+		Key:    aws.String(v.obj.Key),
+	})
+	if err != nil {
+		return RepairResult{Status: db.RepairFailed, Message: ErrorMetadataNotRetrieved(v.obj.URI(), err).Error()}
+	}
+
+	if !isVersion(v.obj, aws.ToString(current.VersionId)) {
+		return RepairResult{
+			Status:  db.RepairRefused,
+			Message: fmt.Sprintf("version is not current (current=%s)", aws.ToString(current.VersionId)),
+		}
+	}
+
+	src := ReplicaObject(v.obj)
+	if v.repairMode == RepairModeDryRun {
+		return RepairResult{
+			Status:  db.RepairDryRun,
+			Message: fmt.Sprintf("would copy %s to s3://%s/%s", src.URI(), v.obj.Bucket, v.obj.Key),
+		}
+	}
+
+	log.Printf("Repairing %s from %s", v.obj.URI(), src.URI())
+
+	copier, ok := v.s3Client.(ObjectCopier)
+	if !ok {
+		return RepairResult{Status: db.RepairFailed, Message: "S3 client cannot copy objects"}
+	}
+
+	versionId, err := copyVersion(v.ctx, copier, src, files.NewS3Object(v.obj.Bucket, v.obj.Key))
+	if err != nil {
+		return RepairResult{Status: db.RepairFailed, Message: err.Error()}
+	}
+	repaired := files.NewS3ObjectVersion(v.obj.Bucket, v.obj.Key, versionId)
+
+	calc, err := NewS3CalculatorWithAlgorithms(v.s3Client, algorithms...)
+	if err != nil {
+		return RepairResult{Status: db.RepairFailed, Message: err.Error(), VersionId: versionId}
+	}
+
+	result, err := calculateWithRetry(v.ctx, calc, repaired, ReadAttempts, ReadBackoff)
+	if err != nil {
+		return RepairResult{
+			Status:    db.RepairFailed,
+			Message:   fmt.Sprintf("copied to %s but verification failed: %v", repaired.URI(), err),
+			VersionId: versionId,
+		}
+	}
+
+	if mismatch := verifyMismatch(stored, result); mismatch != "" {
+		return RepairResult{
+			Status:    db.RepairFailed,
+			Message:   fmt.Sprintf("copied to %s but verification failed: %s", repaired.URI(), mismatch),
+			VersionId: versionId,
+		}
+	}
+
+	log.Printf("Repaired %s as %s", v.obj.URI(), repaired.URI())
+
+	return RepairResult{
+		Status:    db.RepairRepaired,
+		Message:   fmt.Sprintf("copied %s to %s", src.URI(), repaired.URI()),
+		VersionId: versionId,
+	}
+}
+
+// isVersion reports whether versionId identifies the version of obj, unversioned objects are "null"
+func isVersion(obj files.S3Object, versionId string) bool {
+	if obj.VersionId == "" || obj.VersionId == files.NullVersionId {
+		return versionId == "" || versionId == files.NullVersionId
+	}
+	return obj.VersionId == versionId
+}
+
+// copyVersion copies an object version to dst as a new version and returns its version id,
+// objects larger than MaxCopySize are copied in parts
+func copyVersion(ctx context.Context, s3Client ObjectCopier, src files.S3Object, dst files.S3Object) (string, error) {
+	copySource := url.PathEscape(src.Bucket + "/" + src.Key)
+	if src.VersionId != "" {
+		copySource += "?versionId=" + url.QueryEscape(src.VersionId)
+	}
+
+	headResp, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(src.Bucket),
+		Key:       aws.String(src.Key),
+		VersionId: src.VersionIdInput(),
+	})
+	if err != nil {
+		return "", ErrorCopyingObject(src.URI(), dst.URI(), err)
+	}
+
+	size := aws.ToInt64(headResp.ContentLength)
+	if size <= MaxCopySize {
+		copyResp, err := s3Client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:     aws.String(dst.Bucket),
+			Key:        aws.String(dst.Key),
+			CopySource: aws.String(copySource),
+		})
+		if err != nil {
+			return "", ErrorCopyingObject(src.URI(), dst.URI(), err)
+		}
+		return aws.ToString(copyResp.VersionId), nil
+	}
+
+	return copyParts(ctx, s3Client, src, dst, copySource, headResp)
+}
+
+// copyParts copies a large object with UploadPartCopy, the upload is aborted on failure or when
+// the context deadline is too close to finish
+func copyParts(
+	ctx context.Context,
+	s3Client ObjectCopier,
+	src files.S3Object,
+	dst files.S3Object,
+	copySource string,
+	headResp *s3.HeadObjectOutput,
+) (string, error) {
+	size := aws.ToInt64(headResp.ContentLength)
+	partSize := max(int64(CopyPartSize), (size+MaxCopyParts-1)/MaxCopyParts)
+	deadline, hasDeadline := ctx.Deadline()
+
+	upload, err := s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(dst.Bucket),
+		Key:                aws.String(dst.Key),
+		CacheControl:       headResp.CacheControl,
+		ContentDisposition: headResp.ContentDisposition,
+		ContentEncoding:    headResp.ContentEncoding,
+		ContentType:        headResp.ContentType,
+		Metadata:           headResp.Metadata,
+	})
+	if err != nil {
+		return "", ErrorCopyingObject(src.URI(), dst.URI(), err)
+	}
+
+	abort := func(cause error) (string, error) {
+		_, abortErr := s3Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(dst.Bucket),
+			Key:      aws.String(dst.Key),
+			UploadId: upload.UploadId,
+		})
+		if abortErr != nil {
+			log.Printf("Failed to abort multipart copy to %s: %v", dst.URI(), abortErr)
+		}
+		return "", ErrorCopyingObject(src.URI(), dst.URI(), cause)
+	}
+
+	var parts []types.CompletedPart
+	for start, partNumber := int64(0), int32(1); start < size; start, partNumber = start+partSize, partNumber+1 {
+		if hasDeadline && time.Until(deadline) < ContinuationReserve {
+			return abort(fmt.Errorf("not enough time to copy %d bytes (copied %d)", size, start))
+		}
+
+		end := min(start+partSize, size) - 1
+		partResp, err := s3Client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(dst.Bucket),
+			Key:             aws.String(dst.Key),
+			CopySource:      aws.String(copySource),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			PartNumber:      aws.Int32(partNumber),
+			UploadId:        upload.UploadId,
+		})
+		if err != nil {
+			return abort(err)
+		}
+
+		parts = append(parts, types.CompletedPart{
+			ETag:       partResp.CopyPartResult.ETag,
+			PartNumber: aws.Int32(partNumber),
+		})
+	}
+
+	completeResp, err := s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(dst.Bucket),
+		Key:             aws.String(dst.Key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return abort(err)
+	}
+
+	return aws.ToString(completeResp.VersionId), nil
+}
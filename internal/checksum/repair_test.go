@@ -0,0 +1,65 @@
+package checksum
+
+import (
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"testing"
+)
+
+func TestParseRepairMode(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected RepairMode
+		wantErr  bool
+	}{
+		{"", RepairModeOff, false},
+		{"off", RepairModeOff, false},
+		{" Dry-Run ", RepairModeDryRun, false},
+		{"on", RepairModeOn, false},
+		{"always", RepairModeOff, true},
+	}
+
+	for _, tt := range tests {
+		mode, err := ParseRepairMode(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRepairMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if mode != tt.expected {
+			t.Errorf("ParseRepairMode(%q) = %s, expected %s", tt.value, mode, tt.expected)
+		}
+	}
+}
+
+func TestRepairRefusal(t *testing.T) {
+	tests := []struct {
+		status  string
+		refused bool
+	}{
+		{db.ReplicaOK, false},
+		{"", true},
+		{db.ReplicaArchived, true},
+		{db.ReplicaPending, true},
+		{db.FailureCorrupted, true},
+		{db.FailureMissing, true},
+		{db.FailureUnreadable, true},
+	}
+
+	for _, tt := range tests {
+		reason := repairRefusal(ReplicaResult{Status: tt.status})
+		if (reason != "") != tt.refused {
+			t.Errorf("repairRefusal(%q) = %q, expected refused=%v", tt.status, reason, tt.refused)
+		}
+	}
+}
+
+func TestIsVersion(t *testing.T) {
+	versioned := files.NewS3ObjectVersion("test-bucket", "file.txt", "v1")
+	unversioned := files.NewS3Object("test-bucket", "file.txt")
+
+	if !isVersion(versioned, "v1") || isVersion(versioned, "v2") {
+		t.Error("expected only v1 to be the version of a v1 object")
+	}
+	if !isVersion(unversioned, files.NullVersionId) || !isVersion(unversioned, "") || isVersion(unversioned, "v1") {
+		t.Error("expected only the null version to be the version of an unversioned object")
+	}
+}
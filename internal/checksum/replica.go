@@ -0,0 +1,160 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// DefaultReplicaInterval is how often the replica of an object version is read during
+// verification, reading it doubles the bytes of a verification so it is checked less often
+var DefaultReplicaInterval = db.Period{Years: 1}
+
+// ReplicaObject returns the copy of an object version in the replication bucket, replication
+// keeps the version id so the replica of a version is the same version in the replica bucket
+func ReplicaObject(obj files.S3Object) files.S3Object {
+	return files.NewS3ObjectVersion(obj.Bucket+buckets.ReplicationSuffix, obj.Key, obj.VersionId)
+}
+
+// ReplicaResult is the outcome of checking the replica of an object version, Status is empty
+// when the replica could not be checked and the previous status of the record is kept
+type ReplicaResult struct {
+	Status  string
+	Message string
+}
+
+// Failed reports whether the replica did not match the stored checksums or could not be read
+func (r ReplicaResult) Failed() bool {
+	return db.IsReplicaFailure(r.Status)
+}
+
+// ReplicaDue reports whether the replica of a record is checked by a verification at now, a
+// replica is checked again the interval after its last check (every verification for a zero
+// interval) and until S3 has copied it
+func ReplicaDue(record db.ChecksumRecord, interval db.Period, now time.Time) bool {
+	if record.ReplicaCheckDate.IsZero() || record.ReplicaStatus == db.ReplicaPending {
+		return true
+	}
+	return !interval.After(record.ReplicaCheckDate).After(now)
+}
+
+// CheckReplica calculates the checksums of the replica of an object version and compares them
+// with the stored checksums. A replica that S3 has not copied yet is pending and a replica in
+// an archive storage class (that is not restored) cannot be read so it is archived, or
+// restoring while a restore is in progress.
+func CheckReplica(
+	ctx context.Context,
+	s3Client S3ClientInterface,
+	obj files.S3Object,
+	stored map[string]string,
+	algorithms []string,
+) ReplicaResult {
+	replica := ReplicaObject(obj)
+
+	headResp, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(replica.Bucket),
+		Key:       aws.String(replica.Key),
+		VersionId: replica.VersionIdInput(),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchBucket" {
+			log.Printf("Skipping replica check for %s: no replication bucket", obj.URI())
+			return ReplicaResult{}
+		}
+		if isS3NotFound(err) {
+			return missingReplica(ctx, s3Client, obj)
+		}
+		return ReplicaResult{
+			Status:  db.FailureUnreadable,
+			Message: ErrorMetadataNotRetrieved(replica.URI(), err).Error(),
+		}
+	}
+
+	calc, err := NewS3CalculatorWithAlgorithms(s3Client, algorithms...)
+	if err != nil {
+		return ReplicaResult{Status: db.FailureUnreadable, Message: err.Error()}
+	}
+
+	result, err := calculateWithRetry(ctx, calc, replica, ReadAttempts, ReadBackoff)
+	if errors.Is(err, ErrContinuationRequired) {
+		log.Printf("Skipping replica check for %s: %v", obj.URI(), err)
+		return ReplicaResult{}
+	}
+	if errors.Is(err, ErrObjectArchived) {
+		return archivedReplica(headResp)
+	}
+	if err != nil {
+		return ReplicaResult{Status: FailureCategory(err), Message: err.Error()}
+	}
+
+	mismatch := verifyMismatch(stored, result)
+	if mismatch != "" {
+		_, mismatch, err = confirmMismatch(ctx, s3Client, replica, algorithms, mismatch, func(r Result) string {
+			return verifyMismatch(stored, r)
+		})
+	}
+	if err != nil {
+		// The replica is checked again by the next verification
+		log.Printf("Skipping replica check for %s: %v", obj.URI(), err)
+		return ReplicaResult{}
+	}
+	if mismatch != "" {
+		return ReplicaResult{Status: db.FailureCorrupted, Message: mismatch}
+	}
+
+	return ReplicaResult{Status: db.ReplicaOK, Message: "ok"}
+}
+
+// missingReplica distinguishes a replica S3 has not copied yet from one that is missing
+func missingReplica(ctx context.Context, s3Client S3ClientInterface, obj files.S3Object) ReplicaResult {
+	headResp, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(obj.Bucket),
+		Key:       aws.String(obj.Key),
+		VersionId: obj.VersionIdInput(),
+	})
+	if err == nil && headResp.ReplicationStatus == types.ReplicationStatusPending {
+		return ReplicaResult{
+			Status:  db.ReplicaPending,
+			Message: "replication is pending",
+		}
+	}
+
+	message := ErrorObjectNotFound(ReplicaObject(obj).URI()).Error()
+	if err == nil && headResp.ReplicationStatus != "" {
+		message = fmt.Sprintf("%s replication=%s", message, headResp.ReplicationStatus)
+	}
+
+	return ReplicaResult{Status: db.FailureMissing, Message: message}
+}
+
+// archivedReplica distinguishes a replica that is being restored from one that is archived
+func archivedReplica(headResp *s3.HeadObjectOutput) ReplicaResult {
+	if isRestoring(aws.ToString(headResp.Restore)) {
+		return ReplicaResult{
+			Status:  db.ReplicaRestoring,
+			Message: fmt.Sprintf("replica is being restored from %s", headResp.StorageClass),
+		}
+	}
+
+	return ReplicaResult{
+		Status:  db.ReplicaArchived,
+		Message: fmt.Sprintf("replica is archived in %s", headResp.StorageClass),
+	}
+}
+
+// isRestoring reports whether the restore header of an object shows a restore in progress
+func isRestoring(restore string) bool {
+	return strings.Contains(restore, `ongoing-request="true"`)
+}
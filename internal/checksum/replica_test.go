@@ -0,0 +1,191 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// replicationClient reports a replication status for primaries and a storage class (and restore
+// status) for replicas
+type replicationClient struct {
+	*mockS3Client
+	replicationStatus types.ReplicationStatus
+	restore           string
+	storageClass      types.StorageClass
+}
+
+func (r *replicationClient) HeadObject(ctx context.Context, input *s3.HeadObjectInput, opts ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	output, err := r.mockS3Client.HeadObject(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if *input.Bucket == "test-bucket" {
+		output.ReplicationStatus = r.replicationStatus
+	} else {
+		output.StorageClass = r.storageClass
+		if r.restore != "" {
+			output.Restore = aws.String(r.restore)
+		}
+	}
+
+	return output, nil
+}
+
+func TestCheckReplica(t *testing.T) {
+	content := []byte("replicated content")
+	obj := files.NewS3ObjectVersion("test-bucket", "file.txt", "v1")
+	stored := map[string]string{AlgorithmMD5: calculateMD5(content)}
+
+	tests := []struct {
+		name              string
+		replica           []byte
+		replicationStatus types.ReplicationStatus
+		storageClass      types.StorageClass
+		restore           string
+		expected          string
+	}{
+		{"matches", content, types.ReplicationStatusCompleted, "", "", db.ReplicaOK},
+		{"does not match", []byte("corrupted content"), types.ReplicationStatusCompleted, "", "", db.FailureCorrupted},
+		{"not replicated yet", nil, types.ReplicationStatusPending, "", "", db.ReplicaPending},
+		{"missing", nil, types.ReplicationStatusCompleted, "", "", db.FailureMissing},
+		{"archived", content, types.ReplicationStatusCompleted, types.StorageClassDeepArchive, "", db.ReplicaArchived},
+		{"restoring", content, types.ReplicationStatusCompleted, types.StorageClassDeepArchive,
+			`ongoing-request="true"`, db.ReplicaRestoring},
+		{"restored", content, types.ReplicationStatusCompleted, types.StorageClassDeepArchive,
+			`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`, db.ReplicaOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockS3Client()
+			mock.addVersion("test-bucket", "file.txt", "v1", content)
+			if tt.replica != nil {
+				mock.addVersion("test-bucket-repl", "file.txt", "v1", tt.replica)
+			}
+			client := &replicationClient{
+				mockS3Client:      mock,
+				replicationStatus: tt.replicationStatus,
+				restore:           tt.restore,
+				storageClass:      tt.storageClass,
+			}
+
+			result := CheckReplica(context.Background(), client, obj, stored, []string{AlgorithmMD5})
+			if result.Status != tt.expected {
+				t.Errorf("expected status %s, got %s (%s)", tt.expected, result.Status, result.Message)
+			}
+			if result.Failed() != db.IsReplicaFailure(tt.expected) {
+				t.Errorf("unexpected Failed() for status %s", result.Status)
+			}
+		})
+	}
+}
+
+func TestReplicaObject(t *testing.T) {
+	replica := ReplicaObject(files.NewS3ObjectVersion("test-bucket", "dir/file.txt", "v1"))
+
+	if replica.URI() != "s3://test-bucket-repl/dir/file.txt?versionId=v1" {
+		t.Errorf("unexpected replica %s", replica.URI())
+	}
+}
+
+func TestReplicaDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	year := db.Period{Years: 1}
+
+	tests := []struct {
+		name     string
+		record   db.ChecksumRecord
+		interval db.Period
+		expected bool
+	}{
+		{"never checked", db.ChecksumRecord{}, year, true},
+		{"checked recently", db.ChecksumRecord{ReplicaStatus: db.ReplicaOK, ReplicaCheckDate: now.AddDate(0, -6, 0)}, year, false},
+		{"interval elapsed", db.ChecksumRecord{ReplicaStatus: db.ReplicaOK, ReplicaCheckDate: now.AddDate(-1, 0, 0)}, year, true},
+		{"not replicated yet", db.ChecksumRecord{ReplicaStatus: db.ReplicaPending, ReplicaCheckDate: now.AddDate(0, 0, -1)}, year, true},
+		{"every verification", db.ChecksumRecord{ReplicaStatus: db.ReplicaOK, ReplicaCheckDate: now.AddDate(0, 0, -1)}, db.Period{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReplicaDue(tt.record, tt.interval, now); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestVerifierReplicaInterval(t *testing.T) {
+	content := []byte("replicated content")
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", content)
+	client.addObject("bucket-repl", "file.txt", content)
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	if err := NewVerifier(context.Background(), store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	verify := func() db.ChecksumRecord {
+		ok, err := NewVerifier(context.Background(), store, client, obj).WithReplica().Verify()
+		if !ok || err != nil {
+			t.Fatalf("Expected the verification to pass, got %v (%v)", ok, err)
+		}
+		record, _ := store.Get(obj)
+		return record
+	}
+
+	record := verify()
+	if record.ReplicaStatus != db.ReplicaOK || record.ReplicaCheckDate.IsZero() {
+		t.Fatalf("Expected the replica to be checked, got %+v", record)
+	}
+	checked := record.ReplicaCheckDate
+
+	// The replica is not read again until the interval has passed
+	client.addObject("bucket-repl", "file.txt", []byte("corrupted content"))
+	record = verify()
+	if record.ReplicaStatus != db.ReplicaOK || !record.ReplicaCheckDate.Equal(checked) {
+		t.Errorf("Expected the replica check to be skipped, got %+v", record)
+	}
+}
+
+func TestVerifierReplicaOfCorruptedPrimary(t *testing.T) {
+	content := []byte("replicated content")
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", content)
+	client.addObject("bucket-repl", "file.txt", content)
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	if err := NewVerifier(context.Background(), store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := NewVerifier(context.Background(), store, client, obj).WithReplica().Verify(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record, _ := store.Get(obj)
+	checked := record.ReplicaCheckDate
+
+	// The replica is read before its interval to decide the repair of the primary
+	client.addObject("bucket", "file.txt", []byte("corrupted content"))
+	verifier := NewVerifier(context.Background(), store, client, obj).WithReplica().WithRepair(RepairModeDryRun)
+	if _, err := verifier.Verify(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	record, _ = store.Get(obj)
+	if !record.ReplicaCheckDate.After(checked) || record.ReplicaStatus != db.ReplicaOK {
+		t.Errorf("Expected the replica to be checked, got %+v", record)
+	}
+	if verifier.Repair().Status != db.RepairDryRun {
+		t.Errorf("Expected a dry-run repair, got %+v", verifier.Repair())
+	}
+}
@@ -0,0 +1,92 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// RestoreDays is how long a restored replica stays readable, the check runs as soon as
+	// the restore completes so this only needs to cover a retry or a repair
+	RestoreDays = 2
+
+	// RestoreTier is the cheapest retrieval, Deep Archive restores complete within 48 hours
+	RestoreTier = types.TierBulk
+)
+
+// ObjectRestorer is implemented by an S3 client that restores archived objects
+type ObjectRestorer interface {
+	RestoreObject(ctx context.Context, input *s3.RestoreObjectInput, opts ...func(*s3.Options)) (*s3.RestoreObjectOutput, error)
+}
+
+// requestRestore restores an archived replica so its check can finish when the restore
+// completes, the request is tracked in the restore table. A replica that is already being
+// restored is tracked again so a request made outside of verification is not lost.
+func (v *Verifier) requestRestore(replica ReplicaResult) ReplicaResult {
+	src := ReplicaObject(v.obj)
+
+	restores, ok := v.store.(db.RestoreTracker)
+	if !ok {
+		// A restore that cannot be tracked would never finish the check
+		replica.Message = fmt.Sprintf("%s (restore not requested: %v)", replica.Message,
+			db.ErrorRestoresNotConfigured(db.ObjectId(v.obj)))
+		return replica
+	}
+
+	restorer, ok := v.s3Client.(ObjectRestorer)
+	if !ok {
+		replica.Message = fmt.Sprintf("%s (restore not requested: S3 client cannot restore objects)", replica.Message)
+		return replica
+	}
+
+	_, err := restorer.RestoreObject(v.ctx, &s3.RestoreObjectInput{
+		Bucket:    aws.String(src.Bucket),
+		Key:       aws.String(src.Key),
+		VersionId: src.VersionIdInput(),
+		RestoreRequest: &types.RestoreRequest{
+			Days: aws.Int32(RestoreDays),
+			GlacierJobParameters: &types.GlacierJobParameters{
+				Tier: RestoreTier,
+			},
+		},
+	})
+	if err != nil && !isRestoreInProgress(err) {
+		log.Printf("Failed to request restore of %s: %v", src.URI(), err)
+		replica.Message = fmt.Sprintf("%s (restore not requested: %v)", replica.Message, err)
+		return replica
+	}
+
+	err = restores.PutRestore(db.RestoreRecord{
+		BucketName:  v.obj.Bucket,
+		ObjectKey:   v.obj.Key,
+		VersionId:   v.obj.VersionId,
+		RequestDate: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record restore of %s: %v", src.URI(), err)
+		replica.Message = fmt.Sprintf("%s (restore not recorded: %v)", replica.Message, err)
+		return replica
+	}
+
+	log.Printf("Requested %s restore of %s for %d days", RestoreTier, src.URI(), RestoreDays)
+
+	return ReplicaResult{
+		Status:  db.ReplicaRestoring,
+		Message: fmt.Sprintf("restore requested (%s tier), the replica is checked when it completes", RestoreTier),
+	}
+}
+
+// isRestoreInProgress checks if an error is S3 rejecting a restore that was already requested
+func isRestoreInProgress(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress"
+}
@@ -0,0 +1,68 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// ReadAttempts is the number of times a calculation is attempted when the object cannot be read
+	ReadAttempts = 3
+
+	// ReadBackoff is the delay before the first retry, it doubles for each retry after that
+	ReadBackoff = 2 * time.Second
+)
+
+// IsTransient reports whether a calculation failed reading the object rather than comparing
+// its content, those errors (throttling, timeouts, dropped connections) may succeed on retry
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrBytesCountDoesNotMatch) ||
+		errors.Is(err, ErrMetadataNotRetrieved) ||
+		errors.Is(err, ErrObjectNotRetrieved) ||
+		errors.Is(err, ErrReadingFromStream)
+}
+
+// FailureCategory returns the failure category recorded for a calculation that returned err
+func FailureCategory(err error) string {
+	if errors.Is(err, ErrObjectNotFound) {
+		return db.FailureMissing
+	}
+	return db.FailureUnreadable
+}
+
+// calculateWithRetry calculates the checksums of an object, retrying transient errors with
+// exponential backoff. It stops retrying when waiting would run into the continuation reserve.
+func calculateWithRetry(
+	ctx context.Context,
+	calc *S3Calculator,
+	obj files.S3Object,
+	attempts int,
+	backoff time.Duration,
+) (Result, error) {
+	deadline, hasDeadline := ctx.Deadline()
+
+	for attempt := 1; ; attempt++ {
+		result, err := calc.Calculate(ctx, obj)
+		if err == nil || !IsTransient(err) || attempt >= attempts {
+			return result, err
+		}
+
+		if hasDeadline && time.Until(deadline) < backoff+ContinuationReserve {
+			return result, err
+		}
+
+		log.Printf("Retrying checksum calculation for %s in %s (attempt %d of %d): %v",
+			obj.URI(), backoff, attempt+1, attempts, err)
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,104 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// flakyClient fails the first failures GETs, simulating a transient S3 error
+type flakyClient struct {
+	*mockS3Client
+	failures int
+	gets     int
+}
+
+func (f *flakyClient) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.gets++
+	if f.gets <= f.failures {
+		return nil, &smithy.GenericAPIError{Code: "SlowDown", Message: "simulated throttling"}
+	}
+
+	return f.mockS3Client.GetObject(ctx, input, opts...)
+}
+
+func TestCalculateWithRetry(t *testing.T) {
+	content := []byte("retry content")
+	obj := files.NewS3Object("test-bucket", "retry.txt")
+
+	tests := []struct {
+		name     string
+		failures int
+		attempts int
+		wantErr  error
+		wantGets int
+	}{
+		{"succeeds first time", 0, 3, nil, 1},
+		{"succeeds after transient errors", 2, 3, nil, 3},
+		{"gives up after attempts", 3, 3, ErrObjectNotRetrieved, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockS3Client()
+			mock.addObject("test-bucket", "retry.txt", content)
+			client := &flakyClient{mockS3Client: mock, failures: tt.failures}
+
+			calc := NewS3Calculator(client)
+			result, err := calculateWithRetry(context.Background(), calc, obj, tt.attempts, time.Millisecond)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if client.gets != tt.wantGets {
+				t.Errorf("expected %d GETs, got %d", tt.wantGets, client.gets)
+			}
+			if err == nil && result.Checksums[AlgorithmMD5] != calculateMD5(content) {
+				t.Errorf("unexpected checksum %s", result.Checksums[AlgorithmMD5])
+			}
+		})
+	}
+}
+
+func TestCalculateWithRetry_NotTransient(t *testing.T) {
+	client := &flakyClient{mockS3Client: newMockS3Client()}
+	calc := NewS3Calculator(client)
+
+	_, err := calculateWithRetry(context.Background(), calc, files.NewS3Object("test-bucket", "missing.txt"), 3, time.Millisecond)
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+	if client.gets != 0 {
+		t.Errorf("expected a missing object not to be retried, got %d GETs", client.gets)
+	}
+}
+
+func TestFailureCategory(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+		category  string
+	}{
+		{ErrorObjectNotFound("s3://b/k"), false, db.FailureMissing},
+		{ErrorObjectNotRetrieved("s3://b/k", errors.New("timeout")), true, db.FailureUnreadable},
+		{ErrorReadingFromStream("s3://b/k", errors.New("connection reset")), true, db.FailureUnreadable},
+		{ErrorBytesCountDoesNotMatch("s3://b/k", 10, 5), true, db.FailureUnreadable},
+		{ErrorMaxFileSizeExceeded("s3://b/k", MaxFileSize+1), false, db.FailureUnreadable},
+		{ErrorObjectArchived("s3://b/k", "DEEP_ARCHIVE"), false, db.FailureUnreadable},
+	}
+
+	for _, tt := range tests {
+		if IsTransient(tt.err) != tt.transient {
+			t.Errorf("IsTransient(%v) = %v, expected %v", tt.err, !tt.transient, tt.transient)
+		}
+		if category := FailureCategory(tt.err); category != tt.category {
+			t.Errorf("FailureCategory(%v) = %s, expected %s", tt.err, category, tt.category)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package checksum
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// UserMetadataPrefix is the header prefix of S3 user metadata, the SDK returns metadata keys without it
+const UserMetadataPrefix = "x-amz-meta-"
+
+// suppliedMetadataKeys maps the user metadata keys a depositor may set to the algorithm of their
+// value, content-md5 is the base64 form of the Content-MD5 header (which S3 does not store)
+var suppliedMetadataKeys = map[string]string{
+	"content-md5": AlgorithmMD5,
+	"md5":         AlgorithmMD5,
+	"sha256":      AlgorithmSHA256,
+}
+
+// SuppliedAlgorithm returns the algorithm of a supplied checksum source (i.e. x-amz-meta-sha256)
+func SuppliedAlgorithm(source string) (string, bool) {
+	algorithm, ok := suppliedMetadataKeys[strings.TrimPrefix(strings.ToLower(source), UserMetadataPrefix)]
+	return algorithm, ok
+}
+
+// suppliedChecksumsFromMetadata returns the checksums a depositor supplied in user metadata keyed
+// by their source header, hex or base64 values are stored as hex like the calculated digests
+func suppliedChecksumsFromMetadata(metadata map[string]string) map[string]string {
+	var supplied map[string]string
+	for key, value := range metadata {
+		algorithm, ok := suppliedMetadataKeys[strings.ToLower(key)]
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		if supplied == nil {
+			supplied = make(map[string]string)
+		}
+		supplied[UserMetadataPrefix+strings.ToLower(key)] = NormalizeDigest(algorithm, value)
+	}
+
+	return supplied
+}
+
+// NormalizeDigest returns a hex or base64 digest of a known algorithm as lowercase hex, a value
+// that is neither is returned trimmed so it is reported as supplied
+func NormalizeDigest(algorithm string, value string) string {
+	value = strings.TrimSpace(value)
+	size := hasherFuncs[algorithm]().Size()
+
+	if decoded, err := hex.DecodeString(value); err == nil && len(decoded) == size {
+		return hex.EncodeToString(decoded)
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(value); err == nil && len(decoded) == size {
+		return hex.EncodeToString(decoded)
+	}
+
+	return value
+}
+
+// suppliedMismatch describes every supplied checksum that does not match the calculated result,
+// empty if all match. Only calculated algorithms are compared, deposits calculate all of them.
+func suppliedMismatch(supplied map[string]string, result Result) string {
+	sources := make([]string, 0, len(supplied))
+	for source := range supplied {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var mismatches []string
+	for _, source := range sources {
+		algorithm, _ := SuppliedAlgorithm(source)
+		calculated, ok := result.Checksums[algorithm]
+		if ok && calculated != supplied[source] {
+			mismatches = append(mismatches, fmt.Sprintf("%s calculated=%s supplied=%s",
+				source, calculated, supplied[source]))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("checksum does not match supplied checksum: %s", strings.Join(mismatches, "; "))
+}
@@ -0,0 +1,99 @@
+package checksum
+
+import (
+	"context"
+	"crypto/sha256"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestSuppliedChecksumsFromMetadata(t *testing.T) {
+	content := []byte("supplied content")
+	sum := sha256.Sum256(content)
+	md5Hex := calculateMD5(content)
+	md5Raw, _ := hex.DecodeString(md5Hex)
+
+	supplied := suppliedChecksumsFromMetadata(map[string]string{
+		"sha256":      strings.ToUpper(hex.EncodeToString(sum[:])),
+		"content-md5": base64.StdEncoding.EncodeToString(md5Raw),
+		"md5":         " ",
+		"author":      "someone",
+	})
+
+	expected := map[string]string{
+		"x-amz-meta-sha256":      hex.EncodeToString(sum[:]),
+		"x-amz-meta-content-md5": md5Hex,
+	}
+	if len(supplied) != len(expected) {
+		t.Fatalf("expected %d supplied checksums, got %v", len(expected), supplied)
+	}
+	for source, value := range expected {
+		if supplied[source] != value {
+			t.Errorf("expected %s=%s, got %s", source, value, supplied[source])
+		}
+	}
+}
+
+func TestDepositMismatch_Supplied(t *testing.T) {
+	content := []byte("supplied content")
+	sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		mismatch string
+	}{
+		{"no supplied checksum", nil, ""},
+		{"matches", map[string]string{"sha256": hex.EncodeToString(sum[:]), "md5": calculateMD5(content)}, ""},
+		{"does not match", map[string]string{"md5": calculateMD5([]byte("other content"))}, "x-amz-meta-md5"},
+		{"not a digest", map[string]string{"sha256": "not-a-digest"}, "supplied=not-a-digest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockS3Client()
+			mock.addObject("test-bucket", "file.txt", content)
+			mock.addMetadata("test-bucket", "file.txt", tt.metadata)
+
+			calc, err := NewS3CalculatorWithAlgorithms(mock, DefaultAlgorithms...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := calc.Calculate(context.Background(), files.NewS3Object("test-bucket", "file.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mismatch := depositMismatch(result, calculateMD5(content))
+			if tt.mismatch == "" && mismatch != "" {
+				t.Errorf("expected no mismatch, got %s", mismatch)
+			}
+			if tt.mismatch != "" && !strings.Contains(mismatch, tt.mismatch) {
+				t.Errorf("expected mismatch containing %q, got %q", tt.mismatch, mismatch)
+			}
+		})
+	}
+}
+
+func TestStoredChecksums_Supplied(t *testing.T) {
+	// A deposit handed off before its checksums were calculated
+	record := db.ChecksumRecord{
+		SuppliedChecksums: map[string]string{"x-amz-meta-sha256": "supplied"},
+	}
+
+	stored := StoredChecksums(record)
+	if stored[AlgorithmSHA256] != "supplied" {
+		t.Errorf("expected the supplied checksum to be the baseline, got %s", stored[AlgorithmSHA256])
+	}
+
+	record.Checksums = map[string]string{AlgorithmSHA256: "calculated"}
+	stored = StoredChecksums(record)
+	if stored[AlgorithmSHA256] != "calculated" {
+		t.Errorf("expected the calculated checksum to be kept, got %s", stored[AlgorithmSHA256])
+	}
+}
@@ -4,69 +4,226 @@ import (
 	"context"
 	"duracloud/internal/db"
 	"duracloud/internal/files"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
 )
 
 type Verifier struct {
-	ctx      context.Context
-	db       *db.DB
-	s3Client *s3.Client
-	obj      files.S3Object
+	ctx             context.Context
+	budget          *db.ByteBudget
+	checkpointer    Checkpointer
+	deferred        bool
+	failureCategory string
+	s3Client        S3ClientInterface
+	store           db.ChecksumStore
+	obj             files.S3Object
+	policy          db.FixityPolicy
+	repairMode      RepairMode
+	repairResult    RepairResult
+	replica         bool
+	replicaInterval db.Period
+	replicaStatus   string
+	restore         bool
+	sequencer       string
 }
 
-func NewVerifier(ctx context.Context, db *db.DB, s3Client *s3.Client, obj files.S3Object) *Verifier {
+// NewVerifier deposits and verifies an object version, restores and repairs need an S3 client
+// that is also an ObjectRestorer and an ObjectCopier (s3.Client is both)
+func NewVerifier(ctx context.Context, store db.ChecksumStore, s3Client S3ClientInterface, obj files.S3Object) *Verifier {
 	return &Verifier{
-		ctx:      ctx,
-		db:       db,
-		s3Client: s3Client,
-		obj:      obj,
+		ctx:             ctx,
+		s3Client:        s3Client,
+		store:           store,
+		obj:             obj,
+		policy:          db.DefaultFixityPolicy,
+		repairMode:      RepairModeOff,
+		replicaInterval: DefaultReplicaInterval,
 	}
 }
 
+// WithPolicy schedules the next verification under the fixity policy of the bucket
+func (v *Verifier) WithPolicy(policy db.FixityPolicy) *Verifier {
+	v.policy = policy
+	return v
+}
+
+// WithReplica cross-checks the copy of the object in the replication bucket during Verify,
+// once every replica interval (DefaultReplicaInterval unless set by WithReplicaInterval) or
+// whenever the primary is corrupted and could be repaired
+func (v *Verifier) WithReplica() *Verifier {
+	v.replica = true
+	return v
+}
+
+// WithReplicaInterval sets how often Verify checks the replica, a zero interval checks it on
+// every verification
+func (v *Verifier) WithReplicaInterval(interval db.Period) *Verifier {
+	v.replicaInterval = interval
+	return v
+}
+
+// WithRestore requests a restore of an archived replica during Verify, VerifyReplica finishes
+// the check when the restore completes. It requires WithReplica and a store that is a
+// db.RestoreTracker (a DB with restores).
+func (v *Verifier) WithRestore() *Verifier {
+	v.restore = true
+	return v
+}
+
+// WithRepair replaces a corrupted primary with its replica when the replica matches the stored
+// checksums (or records what it would do in dry-run mode), it requires WithReplica. A verifier
+// does not repair unless it is given a mode other than off.
+func (v *Verifier) WithRepair(mode RepairMode) *Verifier {
+	v.repairMode = mode
+	return v
+}
+
+// WithSequencer records the sequencer of the S3 event that deposits the object version, the
+// deposit does not replace a record written by a later event
+func (v *Verifier) WithSequencer(sequencer string) *Verifier {
+	v.sequencer = sequencer
+	return v
+}
+
+// WithCheckpointer resumes large calculations that were handed off by a previous invocation
+func (v *Verifier) WithCheckpointer(checkpointer Checkpointer) *Verifier {
+	v.checkpointer = checkpointer
+	return v
+}
+
+// FailureCategory returns the failure category of the last deposit or verification, empty if it passed
+func (v *Verifier) FailureCategory() string {
+	return v.failureCategory
+}
+
+// Repair returns the repair of the last verification, Status is empty if there was none
+func (v *Verifier) Repair() RepairResult {
+	return v.repairResult
+}
+
+// ReplicaStatus returns the replica status of the last verification, empty if it was not checked
+func (v *Verifier) ReplicaStatus() string {
+	return v.replicaStatus
+}
+
 func (v *Verifier) Deposit(etag string) error {
-	nextScheduledTime, err := db.GetNextScheduledTime()
+	nextScheduledTime, err := v.policy.NextScheduledTime(time.Now())
 	if err != nil {
 		return err
 	}
 
-	calc := NewS3Calculator(v.s3Client)
-	hash, err := calc.CalculateChecksum(v.ctx, v.obj)
+	calc, err := v.newCalculator(DefaultAlgorithms)
+	if err != nil {
+		return err
+	}
+
+	result, err := calculateWithRetry(v.ctx, calc, v.obj, ReadAttempts, ReadBackoff)
+	if errors.Is(err, ErrObjectNotFound) && v.sequencer != "" {
+		// Its delete event was (or will be) processed, a record would outlive the version
+		log.Printf("Skipping deposit of %s: deleted before its deposit", v.obj.URI())
+		return nil
+	}
+
+	legacy, claimErr := v.claimLegacy()
+	if claimErr != nil {
+		return claimErr
+	}
+
+	if errors.Is(err, ErrContinuationRequired) {
+		cont := result.Continuation
+		err = v.handOff(depositRecord(v.obj, v.sequencer, cont.Size, cont.Native, cont.Supplied, legacy), err)
+		if v.superseded(err) {
+			return nil
+		}
+		return err
+	}
+
+	compare := func(r Result) string {
+		if mismatch := depositMismatch(r, etag); mismatch != "" {
+			return mismatch
+		}
+		return legacyMismatch(legacy, r)
+	}
+
+	var mismatch string
+	if err == nil {
+		mismatch = compare(result)
+	}
+	if mismatch != "" {
+		confirmed, confirmedMismatch, confirmErr := confirmMismatch(v.ctx, v.s3Client, v.obj, DefaultAlgorithms, mismatch, compare)
+		if confirmErr != nil {
+			// Its verification reads the version again to confirm the mismatch
+			checksumRecord := depositRecord(v.obj, v.sequencer, result.Size, result.Native, result.Supplied, legacy)
+			checksumRecord.UnconfirmedMismatch = mismatch
+			err = v.handOff(checksumRecord, confirmErr)
+			if v.superseded(err) {
+				return nil
+			}
+			return err
+		}
+		result, mismatch = confirmed, confirmedMismatch
+	}
 
 	// Optimistic outlook for our adventurer checksum record
 	checksumRecord := db.ChecksumRecord{
 		BucketName:          v.obj.Bucket,
 		ObjectKey:           v.obj.Key,
-		Checksum:            hash, // May be empty if failed
+		Checksum:            result.Checksums[AlgorithmMD5], // May be empty if failed
+		Checksums:           result.Checksums,
 		LastChecksumDate:    time.Now(),
 		LastChecksumMessage: "ok",
 		LastChecksumSuccess: true,
 		NextChecksumDate:    nextScheduledTime,
+		Sequencer:           v.sequencer,
+		Size:                result.Size,
+		SuppliedChecksums:   result.Supplied,
+		VersionId:           v.obj.VersionId,
 	}
 
 	if err != nil {
 		// Checksum calculation failed
+		checksumRecord.FailureCategory = FailureCategory(err)
 		checksumRecord.LastChecksumMessage = err.Error()
 		checksumRecord.LastChecksumSuccess = false
-	} else if !strings.Contains(etag, "-") && hash != etag {
-		// ETag validation failed
-		msg := fmt.Sprintf("checksum does not match etag: calculated=%s etag=%s", hash, etag)
-		log.Println(msg)
-		checksumRecord.LastChecksumMessage = msg
+	} else if mismatch != "" {
+		checksumRecord.FailureCategory = db.FailureCorrupted
+		checksumRecord.LastChecksumMessage = mismatch
 		checksumRecord.LastChecksumSuccess = false
 	}
 
-	err = v.db.Put(checksumRecord)
+	if result.Native != nil {
+		// The client supplied checksum becomes the baseline for this algorithm
+		checksumRecord.NativeChecksum = result.Native.Value
+		checksumRecord.NativeChecksumAlgorithm = result.Native.Key()
+	}
+
+	if len(legacy) > 0 {
+		checksumRecord.LegacyChecksums = legacy
+		checksumRecord.LegacyStatus = legacyStatus(legacy, result, err)
+	}
+	v.failureCategory = checksumRecord.FailureCategory
+
+	err = v.store.Put(checksumRecord)
+	if v.superseded(err) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
+	v.appendEvent(db.FixityEventDeposit, checksumRecord, result.Checksums)
+
 	if checksumRecord.LastChecksumSuccess {
-		err = v.db.Schedule(checksumRecord)
+		err = v.store.Schedule(checksumRecord)
 		if err != nil {
 			return err
 		}
@@ -80,46 +237,140 @@ func (v *Verifier) Verify() (bool, error) {
 	ok := true
 
 	currentTime := time.Now()
-	nextScheduledTime, err := db.GetNextScheduledTime()
+	nextScheduledTime, err := v.policy.NextScheduledTime(currentTime)
 	if err != nil {
 		return false, err
 	}
 
-	checksumRecord, err := v.db.Get(v.obj)
+	checksumRecord, err := v.store.Get(v.obj)
 	if err != nil {
 		return false, err
 	}
 
+	// Records handed off for an immediate check were counted when their verification started
+	if v.budget != nil && !checksumRecord.HandedOff {
+		deferred, err := v.deferOverBudget(checksumRecord)
+		if err != nil || deferred {
+			return true, err
+		}
+	}
+
+	checksumRecord.HandedOff = false
 	checksumRecord.LastChecksumDate = currentTime
 	checksumRecord.NextChecksumDate = nextScheduledTime
 
-	calc := NewS3Calculator(v.s3Client)
-	checksumResult, err := calc.CalculateChecksum(v.ctx, v.obj)
+	stored := StoredChecksums(checksumRecord)
+	algorithms := verifyAlgorithms(stored)
+	calc, err := v.newCalculator(algorithms)
 	if err != nil {
+		return false, err
+	}
+
+	result, err := calculateWithRetry(v.ctx, calc, v.obj, ReadAttempts, ReadBackoff)
+	if errors.Is(err, ErrContinuationRequired) {
+		err = v.handOff(checksumRecord, err)
+		if v.superseded(err) {
+			return ok, nil
+		}
+		return ok, err
+	}
+	if errors.Is(err, ErrObjectNotFound) && v.expired() {
+		return ok, v.removeExpired()
+	}
+
+	// A mismatch a previous read could not confirm is confirmed by this (independent) read
+	unconfirmed := checksumRecord.UnconfirmedMismatch
+	checksumRecord.UnconfirmedMismatch = ""
+
+	// Deposits handed off before they were compared with their legacy checksums are compared now
+	pending := checksumRecord.LegacyStatus == db.LegacyPending
+	compare := func(r Result) string {
+		if mismatch := verifyMismatch(stored, r); mismatch != "" || !pending {
+			return mismatch
+		}
+		return legacyMismatch(checksumRecord.LegacyChecksums, r)
+	}
+
+	var mismatch string
+	if err == nil {
+		mismatch = compare(result)
+		if mismatch != "" && unconfirmed == "" {
+			confirmed, confirmedMismatch, confirmErr := confirmMismatch(v.ctx, v.s3Client, v.obj, algorithms, mismatch, compare)
+			if confirmErr != nil {
+				checksumRecord.UnconfirmedMismatch = mismatch
+				err = v.handOff(checksumRecord, confirmErr)
+				if v.superseded(err) {
+					return ok, nil
+				}
+				return ok, err
+			}
+			result, mismatch = confirmed, confirmedMismatch
+		} else if mismatch != "" {
+			log.Printf("Checksum mismatch for %s confirmed by a second read: %s", v.obj.URI(), unconfirmed)
+		}
+
+		if pending {
+			checksumRecord.LegacyStatus = legacyStatus(checksumRecord.LegacyChecksums, result, nil)
+		}
+	}
+
+	if err != nil {
+		checksumRecord.FailureCategory = FailureCategory(err)
 		checksumRecord.LastChecksumMessage = err.Error()
 		checksumRecord.LastChecksumSuccess = false
 		ok = false
-	} else if checksumResult != checksumRecord.Checksum {
-		msg := fmt.Sprintf("Checksum mismatch: calculated=%s, stored=%s", checksumResult, checksumRecord.Checksum)
-		log.Println(msg)
-		checksumRecord.LastChecksumMessage = msg
+	} else if mismatch != "" {
+		checksumRecord.FailureCategory = db.FailureCorrupted
+		checksumRecord.LastChecksumMessage = mismatch
 		checksumRecord.LastChecksumSuccess = false
 		ok = false
 	} else {
 		// Technically this is redundant but included for clarity
+		checksumRecord.FailureCategory = ""
 		checksumRecord.LastChecksumMessage = "ok"
 		checksumRecord.LastChecksumSuccess = true
+
+		// Records deposited before multiple algorithms were supported gain the missing digests
+		checksumRecord.Checksums = result.Checksums
+
+		// Records handed off during deposit gain the primary checksum on completion
+		if checksumRecord.Checksum == "" {
+			checksumRecord.Checksum = result.Checksums[AlgorithmMD5]
+		}
+
+		// Records deposited before sizes were stored gain the size of the version
+		if checksumRecord.Size == 0 {
+			checksumRecord.Size = result.Size
+		}
 	}
 
-	err = v.db.Put(checksumRecord)
+	// A corrupted primary is only repaired from a replica read by this verification
+	repairable := v.repairMode != RepairModeOff && checksumRecord.FailureCategory == db.FailureCorrupted
+
+	var replica ReplicaResult
+	if v.replica && (repairable || ReplicaDue(checksumRecord, v.replicaInterval, currentTime)) {
+		replica = v.checkReplica(&checksumRecord, stored, algorithms)
+		ok = ok && !replica.Failed()
+	}
+
+	v.repairCorrupted(&checksumRecord, stored, algorithms, replica)
+	v.failureCategory = checksumRecord.FailureCategory
+	v.replicaStatus = checksumRecord.ReplicaStatus
+
+	err = v.store.Put(checksumRecord)
+	if v.superseded(err) {
+		return ok, nil
+	}
 	if err != nil {
 		log.Printf("Failed to update checksum record due to : %v", err)
 		return ok, err
 	}
 
+	v.appendEvent(db.FixityEventVerification, checksumRecord, result.Checksums)
+
 	if checksumRecord.LastChecksumSuccess {
 		log.Printf("Checksum verification succeeded for: %s/%s", v.obj.Bucket, v.obj.Key)
-		err = v.db.Schedule(checksumRecord)
+		err = v.store.Schedule(checksumRecord)
 		if err != nil {
 			return ok, err
 		}
@@ -127,3 +378,382 @@ func (v *Verifier) Verify() (bool, error) {
 
 	return ok, nil
 }
+
+// VerifyReplica finishes the replica check of an object version once its replica has been
+// restored, the primary is not read again. A corrupted primary is repaired when the restored
+// replica matches the stored checksums.
+func (v *Verifier) VerifyReplica() (bool, error) {
+	log.Printf("Starting replica verification for: %s/%s", v.obj.Bucket, v.obj.Key)
+
+	checksumRecord, err := v.store.Get(v.obj)
+	if err != nil {
+		return false, err
+	}
+
+	stored := StoredChecksums(checksumRecord)
+	algorithms := verifyAlgorithms(stored)
+
+	replica := v.checkReplica(&checksumRecord, stored, algorithms)
+	v.repairCorrupted(&checksumRecord, stored, algorithms, replica)
+	v.failureCategory = checksumRecord.FailureCategory
+	v.replicaStatus = checksumRecord.ReplicaStatus
+
+	err = v.store.Put(checksumRecord)
+	if err != nil {
+		log.Printf("Failed to update checksum record due to : %v", err)
+		return !replica.Failed(), err
+	}
+
+	if replica.Status == "" {
+		return true, nil
+	}
+
+	event := db.FixityEvent{
+		BucketName: v.obj.Bucket,
+		ObjectKey:  v.obj.Key,
+		VersionId:  v.obj.VersionId,
+		EventDate:  time.Now(),
+		EventType:  db.FixityEventVerification,
+		Message:    "replica: " + replica.Message,
+		Success:    !replica.Failed(),
+	}
+	if replica.Failed() {
+		event.FailureCategory = replica.Status
+	}
+
+	return !replica.Failed(), db.RecordEvent(v.store, event)
+}
+
+// checkReplica checks the replica of the object version and records the outcome on the record,
+// an archived replica is restored when restores are enabled. A replica that does not match
+// fails the record even when the primary matches.
+func (v *Verifier) checkReplica(checksumRecord *db.ChecksumRecord, stored map[string]string, algorithms []string) ReplicaResult {
+	replica := CheckReplica(v.ctx, v.s3Client, v.obj, stored, algorithms)
+	if v.restore && (replica.Status == db.ReplicaArchived || replica.Status == db.ReplicaRestoring) {
+		replica = v.requestRestore(replica)
+	}
+
+	if replica.Status != "" {
+		checksumRecord.ReplicaStatus = replica.Status
+		checksumRecord.ReplicaMessage = replica.Message
+	}
+	if replica.Status != "" && replica.Status != db.ReplicaPending {
+		checksumRecord.ReplicaCheckDate = time.Now()
+	}
+
+	if replica.Failed() && checksumRecord.LastChecksumSuccess {
+		log.Printf("Replica verification failed for %s: %s", v.obj.URI(), replica.Message)
+		checksumRecord.LastChecksumMessage = "replica: " + replica.Message
+		checksumRecord.LastChecksumSuccess = false
+	}
+
+	return replica
+}
+
+// repairCorrupted repairs a corrupted primary when repairs are enabled and records the outcome on
+// the record. The corrupted version stays failed, a repair adds a new version with its own record.
+// A replica that is being restored is repaired from when the restore completes.
+func (v *Verifier) repairCorrupted(
+	checksumRecord *db.ChecksumRecord,
+	stored map[string]string,
+	algorithms []string,
+	replica ReplicaResult,
+) {
+	if v.repairMode == RepairModeOff || checksumRecord.FailureCategory != db.FailureCorrupted {
+		return
+	}
+
+	if replica.Status == db.ReplicaRestoring {
+		log.Printf("Deferring repair of %s until its replica is restored", v.obj.URI())
+		return
+	}
+
+	v.repairResult = v.repair(stored, algorithms, replica)
+	log.Printf("Repair of %s %s: %s", v.obj.URI(), v.repairResult.Status, v.repairResult.Message)
+
+	checksumRecord.RepairDate = time.Now()
+	checksumRecord.RepairMessage = v.repairResult.Message
+	checksumRecord.RepairStatus = v.repairResult.Status
+	checksumRecord.RepairVersionId = v.repairResult.VersionId
+}
+
+func (v *Verifier) newCalculator(algorithms []string) (*S3Calculator, error) {
+	calc, err := NewS3CalculatorWithAlgorithms(v.s3Client, algorithms...)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.checkpointer != nil {
+		calc.WithCheckpointer(v.checkpointer)
+	}
+
+	return calc, nil
+}
+
+// confirmMismatch reads an object again with a calculator that does not resume from a checkpoint,
+// so a mismatch is only recorded when an independent read agrees. It returns the result and the
+// mismatch (if any) of the second read, or ErrMismatchUnconfirmed when the second read cannot
+// complete (a large object may not be read twice before the deadline).
+func confirmMismatch(
+	ctx context.Context,
+	s3Client S3ClientInterface,
+	obj files.S3Object,
+	algorithms []string,
+	mismatch string,
+	compare func(Result) string,
+) (Result, string, error) {
+	log.Printf("Confirming checksum mismatch for %s: %s", obj.URI(), mismatch)
+
+	calc, err := NewS3CalculatorWithAlgorithms(s3Client, algorithms...)
+	if err != nil {
+		return Result{}, mismatch, ErrorMismatchUnconfirmed(obj.URI(), err)
+	}
+
+	result, err := calculateWithRetry(ctx, calc, obj, ReadAttempts, ReadBackoff)
+	if err != nil {
+		log.Printf("Unable to confirm checksum mismatch for %s: %v", obj.URI(), err)
+		return Result{}, mismatch, ErrorMismatchUnconfirmed(obj.URI(), err)
+	}
+
+	confirmed := compare(result)
+	if confirmed == "" {
+		log.Printf("Checksum mismatch for %s not confirmed by a second read", obj.URI())
+	} else {
+		log.Println(confirmed)
+	}
+
+	return result, confirmed, nil
+}
+
+// appendEvent adds the outcome of a deposit or verification to the fixity history of
+// the object, checksums are the values calculated by this run. The record is already written
+// so a history that cannot be appended is logged rather than leave the record unscheduled.
+func (v *Verifier) appendEvent(eventType db.FixityEventType, checksumRecord db.ChecksumRecord, checksums map[string]string) {
+	err := db.RecordEvent(v.store, db.FixityEvent{
+		BucketName:      v.obj.Bucket,
+		ObjectKey:       v.obj.Key,
+		VersionId:       v.obj.VersionId,
+		EventDate:       checksumRecord.LastChecksumDate,
+		EventType:       eventType,
+		Checksums:       checksums,
+		Message:         checksumRecord.LastChecksumMessage,
+		Success:         checksumRecord.LastChecksumSuccess,
+		FailureCategory: checksumRecord.FailureCategory,
+	})
+	if err != nil {
+		log.Printf("Failed to append %s event of %s to the fixity history: %v", eventType, v.obj.URI(), err)
+	}
+}
+
+// expired reports whether a version that cannot be found was expired by the lifecycle rule,
+// which only expires noncurrent versions: the object has a later version or was deleted. The
+// current version is never expired, so its absence remains a fixity failure.
+func (v *Verifier) expired() bool {
+	if v.obj.VersionId == "" {
+		return false
+	}
+
+	resp, err := v.s3Client.HeadObject(v.ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(v.obj.Bucket),
+		Key:    aws.String(v.obj.Key),
+	})
+	if err != nil {
+		// A delete marker (or no version at all) is current, the object was deleted
+		var apiErr smithy.APIError
+		return errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NoSuchKey" || apiErr.ErrorCode() == "NotFound")
+	}
+
+	return aws.ToString(resp.VersionId) != v.obj.VersionId
+}
+
+// removeExpired deletes the records of a version expired by the lifecycle rule (its expiry
+// event was missed or is not processed yet) and records the deletion in its fixity history
+func (v *Verifier) removeExpired() error {
+	log.Printf("Removing checksum records of %s: expired by the lifecycle rule", v.obj.URI())
+
+	if err := v.store.Delete(v.obj, ""); err != nil {
+		return err
+	}
+
+	err := db.RecordEvent(v.store, db.FixityEvent{
+		BucketName: v.obj.Bucket,
+		ObjectKey:  v.obj.Key,
+		VersionId:  v.obj.VersionId,
+		EventType:  db.FixityEventDeletion,
+		Message:    "object version expired by the lifecycle rule",
+		Success:    true,
+	})
+	if err != nil {
+		log.Printf("Failed to record expiry of %s in history: %v", v.obj.URI(), err)
+	}
+
+	return nil
+}
+
+// superseded reports whether a write was refused because a later S3 event has already
+// updated the record (the object was uploaded again), the later event is left to stand
+func (v *Verifier) superseded(err error) bool {
+	if !errors.Is(err, db.ErrStaleSequencer) {
+		return false
+	}
+
+	log.Printf("Skipping update of %s superseded by a later event: %v", v.obj.URI(), err)
+	return true
+}
+
+// depositRecord returns the record of a deposit that is handed off to its verification, which
+// compares the client supplied checksums (and the legacy checksums) on completion
+func depositRecord(
+	obj files.S3Object,
+	sequencer string,
+	size int64,
+	native *NativeChecksum,
+	supplied map[string]string,
+	legacy map[string]string,
+) db.ChecksumRecord {
+	checksumRecord := db.ChecksumRecord{
+		BucketName:          obj.Bucket,
+		ObjectKey:           obj.Key,
+		LastChecksumSuccess: true,
+		Sequencer:           sequencer,
+		Size:                size,
+		SuppliedChecksums:   supplied,
+		VersionId:           obj.VersionId,
+	}
+
+	if native != nil {
+		checksumRecord.NativeChecksum = native.Value
+		checksumRecord.NativeChecksumAlgorithm = native.Key()
+	}
+
+	if len(legacy) > 0 {
+		checksumRecord.LegacyChecksums = legacy
+		checksumRecord.LegacyStatus = db.LegacyPending
+	}
+
+	return checksumRecord
+}
+
+// handOffRequestId marks the verification request of a calculation that was handed off
+const handOffRequestId = "handoff"
+
+// handOff records a calculation that could not finish before the Lambda deadline and requests
+// its verification now (resuming from its checkpoint), it is not a fixity failure. A store that
+// cannot request verifications schedules it as due now instead.
+func (v *Verifier) handOff(checksumRecord db.ChecksumRecord, cause error) error {
+	log.Printf("Handing off checksum calculation for %s/%s: %v", v.obj.Bucket, v.obj.Key, cause)
+
+	now := time.Now()
+	checksumRecord.HandedOff = true
+	checksumRecord.LastChecksumDate = now
+	checksumRecord.LastChecksumMessage = cause.Error()
+	checksumRecord.NextChecksumDate = now
+
+	err := v.store.Put(checksumRecord)
+	if err != nil {
+		return err
+	}
+
+	// TTL deletion can lag the expiry by up to 48 hours, a removed entry triggers it at once
+	if requester, ok := v.store.(db.VerificationRequester); ok {
+		return requester.RequestVerification(v.obj, handOffRequestId)
+	}
+
+	return v.store.Schedule(checksumRecord)
+}
+
+// depositMismatch describes a calculated checksum that does not match the ETag or a client
+// supplied checksum, which take precedence (user metadata first, then the S3 checksum), empty
+// if all match
+func depositMismatch(result Result, etag string) string {
+	if mismatch := suppliedMismatch(result.Supplied, result); mismatch != "" {
+		return mismatch
+	}
+
+	if result.Native != nil {
+		key := result.Native.Key()
+		if result.Checksums[key] != result.Native.Value {
+			return fmt.Sprintf("checksum does not match s3 %s checksum: calculated=%s s3=%s",
+				key, result.Checksums[key], result.Native.Value)
+		}
+	}
+
+	// Multipart ETags are reconstructed from each part
+	if result.ETag != NormalizeETag(etag) {
+		return fmt.Sprintf("checksum does not match etag: calculated=%s etag=%s", result.ETag, etag)
+	}
+
+	return ""
+}
+
+// verifyMismatch describes every stored checksum that does not match the calculated result
+func verifyMismatch(stored map[string]string, result Result) string {
+	mismatches := CompareChecksums(stored, result.Checksums)
+	if len(mismatches) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Checksum mismatch: %s", strings.Join(mismatches, "; "))
+}
+
+// StoredChecksums returns the stored digests by algorithm, the primary checksum is md5
+// and a client supplied S3 checksum takes precedence over the one calculated at deposit.
+// Checksums supplied in user metadata are the baseline of a deposit that was handed off.
+func StoredChecksums(record db.ChecksumRecord) map[string]string {
+	stored := make(map[string]string, len(record.Checksums)+2)
+	for algorithm, value := range record.Checksums {
+		stored[algorithm] = value
+	}
+
+	sources := make([]string, 0, len(record.SuppliedChecksums))
+	for source := range record.SuppliedChecksums {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		if algorithm, ok := SuppliedAlgorithm(source); ok && stored[algorithm] == "" {
+			stored[algorithm] = record.SuppliedChecksums[source]
+		}
+	}
+
+	if record.Checksum != "" {
+		stored[AlgorithmMD5] = record.Checksum
+	}
+
+	if record.NativeChecksum != "" && record.NativeChecksumAlgorithm != "" {
+		stored[record.NativeChecksumAlgorithm] = record.NativeChecksum
+	}
+
+	return stored
+}
+
+// verifyAlgorithms returns the default algorithms plus any other stored algorithm
+func verifyAlgorithms(stored map[string]string) []string {
+	algorithms := slices.Clone(DefaultAlgorithms)
+	for algorithm := range stored {
+		if !slices.Contains(algorithms, algorithm) {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	sort.Strings(algorithms[len(DefaultAlgorithms):])
+
+	return algorithms
+}
+
+// CompareChecksums returns a description of every stored algorithm that does not match
+func CompareChecksums(stored, calculated map[string]string) []string {
+	algorithms := make([]string, 0, len(stored))
+	for algorithm := range stored {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+
+	var mismatches []string
+	for _, algorithm := range algorithms {
+		if calculated[algorithm] != stored[algorithm] {
+			mismatches = append(mismatches, fmt.Sprintf("algorithm=%s calculated=%s, stored=%s",
+				algorithm, calculated[algorithm], stored[algorithm]))
+		}
+	}
+
+	return mismatches
+}
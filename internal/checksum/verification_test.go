@@ -0,0 +1,454 @@
+package checksum
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func TestStoredChecksums(t *testing.T) {
+	record := db.ChecksumRecord{
+		Checksum: "abc123",
+		Checksums: map[string]string{
+			AlgorithmMD5:    "stale",
+			AlgorithmSHA256: "def456",
+		},
+	}
+
+	stored := StoredChecksums(record)
+
+	if stored[AlgorithmMD5] != "abc123" {
+		t.Errorf("expected primary checksum to take precedence, got %s", stored[AlgorithmMD5])
+	}
+
+	if stored[AlgorithmSHA256] != "def456" {
+		t.Errorf("expected sha256 def456, got %s", stored[AlgorithmSHA256])
+	}
+
+	legacy := StoredChecksums(db.ChecksumRecord{Checksum: "abc123"})
+	if len(legacy) != 1 || legacy[AlgorithmMD5] != "abc123" {
+		t.Errorf("expected only md5 for legacy record, got %v", legacy)
+	}
+}
+
+func TestCompareChecksums(t *testing.T) {
+	calculated := map[string]string{
+		AlgorithmMD5:    "abc123",
+		AlgorithmSHA256: "def456",
+		AlgorithmSHA512: "ghi789",
+	}
+
+	tests := []struct {
+		name       string
+		stored     map[string]string
+		mismatches int
+	}{
+		{
+			name:       "all match",
+			stored:     map[string]string{AlgorithmMD5: "abc123", AlgorithmSHA256: "def456"},
+			mismatches: 0,
+		},
+		{
+			name:       "legacy md5 only",
+			stored:     map[string]string{AlgorithmMD5: "abc123"},
+			mismatches: 0,
+		},
+		{
+			name:       "sha256 mismatch",
+			stored:     map[string]string{AlgorithmMD5: "abc123", AlgorithmSHA256: "000000"},
+			mismatches: 1,
+		},
+		{
+			name:       "all mismatch",
+			stored:     map[string]string{AlgorithmMD5: "0", AlgorithmSHA256: "0", AlgorithmSHA512: "0"},
+			mismatches: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CompareChecksums(tt.stored, calculated)
+			if len(result) != tt.mismatches {
+				t.Errorf("expected %d mismatches, got %d: %v", tt.mismatches, len(result), result)
+			}
+		})
+	}
+}
+
+func TestStoredChecksums_Native(t *testing.T) {
+	record := db.ChecksumRecord{
+		Checksum: "abc123",
+		Checksums: map[string]string{
+			"s3-crc32c": "calculated==",
+		},
+		NativeChecksum:          "client==",
+		NativeChecksumAlgorithm: "s3-crc32c",
+	}
+
+	stored := StoredChecksums(record)
+	if stored["s3-crc32c"] != "client==" {
+		t.Errorf("expected client supplied checksum to be the baseline, got %s", stored["s3-crc32c"])
+	}
+}
+
+func TestVerifyAlgorithms(t *testing.T) {
+	stored := map[string]string{
+		AlgorithmMD5:          "abc123",
+		"s3-sha256-composite": "def456-2",
+		"s3-crc32c":           "ghi789",
+	}
+
+	algorithms := verifyAlgorithms(stored)
+	expected := []string{AlgorithmMD5, AlgorithmSHA256, AlgorithmSHA512, "s3-crc32c", "s3-sha256-composite"}
+	if len(algorithms) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, algorithms)
+	}
+
+	for i := range expected {
+		if algorithms[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, algorithms)
+			break
+		}
+	}
+}
+
+var (
+	_ ObjectCopier   = (*s3.Client)(nil)
+	_ ObjectRestorer = (*s3.Client)(nil)
+)
+
+// historyFailingStore is a MemoryStore whose fixity history cannot be appended
+type historyFailingStore struct {
+	*db.MemoryStore
+}
+
+func (s historyFailingStore) AppendEvent(event db.FixityEvent) error {
+	return errors.New("history unavailable")
+}
+
+// requestingStore is a MemoryStore that records the verifications requested of it
+type requestingStore struct {
+	*db.MemoryStore
+	requests []string
+}
+
+func (s *requestingStore) RequestVerification(obj files.S3Object, requestId string) error {
+	s.requests = append(s.requests, obj.URI()+" "+requestId)
+	return nil
+}
+
+// failingReads is a mockS3Client whose GetObject fails once its successful reads are used up
+type failingReads struct {
+	*mockS3Client
+	reads int
+}
+
+func (f *failingReads) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if f.reads == 0 {
+		return nil, &smithy.GenericAPIError{Code: "SlowDown", Message: "Please reduce your request rate"}
+	}
+	f.reads--
+	return f.mockS3Client.GetObject(ctx, input, opts...)
+}
+
+func newTestVerifier(store db.ChecksumStore, client *mockS3Client, obj files.S3Object) *Verifier {
+	return NewVerifier(context.Background(), store, client, obj)
+}
+
+func TestVerifierDeposit(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", []byte("DuraCloud deposit content"))
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	if err := newTestVerifier(store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	record, err := store.Get(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !record.LastChecksumSuccess || record.Checksum != calculateMD5([]byte("DuraCloud deposit content")) {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if _, err := store.Next(obj); err != nil {
+		t.Errorf("Expected the deposit to be scheduled, got %v", err)
+	}
+
+	history, _ := store.History(obj)
+	if len(history) != 1 || history[0].EventType != db.FixityEventDeposit || !history[0].Success {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestVerifierDepositETagMismatch(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", []byte("DuraCloud deposit content"))
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	verifier := newTestVerifier(store, client, obj)
+	if err := verifier.Deposit(`"00000000000000000000000000000000"`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	record, _ := store.Get(obj)
+	if record.LastChecksumSuccess || record.FailureCategory != db.FailureCorrupted ||
+		!strings.Contains(record.LastChecksumMessage, "etag") {
+		t.Errorf("Expected an etag mismatch, got %+v", record)
+	}
+	if verifier.FailureCategory() != db.FailureCorrupted {
+		t.Errorf("Expected a corrupted deposit, got %q", verifier.FailureCategory())
+	}
+	if _, err := store.Next(obj); !errors.Is(err, db.ErrChecksumRecordNotFound) {
+		t.Errorf("Expected a failed deposit not to be scheduled, got %v", err)
+	}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", []byte("DuraCloud verified content"))
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	if err := newTestVerifier(store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ok, err := newTestVerifier(store, client, obj).Verify()
+	if !ok || err != nil {
+		t.Fatalf("Expected the verification to pass, got %v (%v)", ok, err)
+	}
+	record, _ := store.Get(obj)
+	if !record.LastChecksumSuccess || record.LastChecksumMessage != "ok" {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	// The content changes under the stored checksums
+	client.addObject("bucket", "file.txt", []byte("DuraCloud corrupted content"))
+	verifier := newTestVerifier(store, client, obj)
+	ok, err = verifier.Verify()
+	if ok || err != nil {
+		t.Fatalf("Expected the verification to fail, got %v (%v)", ok, err)
+	}
+
+	record, _ = store.Get(obj)
+	if record.LastChecksumSuccess || record.FailureCategory != db.FailureCorrupted {
+		t.Errorf("Expected a corrupted record, got %+v", record)
+	}
+
+	history, _ := store.History(obj)
+	if len(history) != 3 || history[2].EventType != db.FixityEventVerification || history[2].Success {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestVerifierUnconfirmedMismatch(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", []byte("DuraCloud verified content"))
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := db.NewMemoryStore()
+
+	if err := newTestVerifier(store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first read mismatches and the confirming read fails too close to the deadline to retry
+	client.addObject("bucket", "file.txt", []byte("DuraCloud corrupted content"))
+	ctx, cancel := context.WithTimeout(context.Background(), ContinuationReserve+time.Second)
+	defer cancel()
+
+	ok, err := NewVerifier(ctx, store, &failingReads{mockS3Client: client, reads: 1}, obj).Verify()
+	if !ok || err != nil {
+		t.Fatalf("Expected a handoff, got %v (%v)", ok, err)
+	}
+
+	record, _ := store.Get(obj)
+	if !record.LastChecksumSuccess || record.UnconfirmedMismatch == "" ||
+		!strings.Contains(record.LastChecksumMessage, "not confirmed") {
+		t.Errorf("Expected the unconfirmed mismatch to be handed off, got %+v", record)
+	}
+	scheduled, err := store.Next(obj)
+	if err != nil || scheduled.NextChecksumDate.After(time.Now()) {
+		t.Errorf("Expected the verification to be due now, got %+v (%v)", scheduled, err)
+	}
+
+	// The next verification reads the version once, which confirms the mismatch
+	ok, err = NewVerifier(context.Background(), store, &failingReads{mockS3Client: client, reads: 1}, obj).Verify()
+	if ok || err != nil {
+		t.Fatalf("Expected the verification to fail, got %v (%v)", ok, err)
+	}
+
+	record, _ = store.Get(obj)
+	if record.LastChecksumSuccess || record.FailureCategory != db.FailureCorrupted || record.UnconfirmedMismatch != "" {
+		t.Errorf("Expected a corrupted record, got %+v", record)
+	}
+}
+
+func TestVerifierHandOff(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "large.bin", []byte("DuraCloud large content"))
+	client.lengths["bucket/large.bin"] = RangedReadThreshold
+	obj := files.NewS3Object("bucket", "large.bin")
+	store := db.NewMemoryStore()
+	record := db.ChecksumRecord{
+		BucketName:          obj.Bucket,
+		ObjectKey:           obj.Key,
+		Checksum:            calculateMD5([]byte("DuraCloud large content")),
+		LastChecksumSuccess: true,
+		NextChecksumDate:    time.Now().AddDate(1, 0, 0),
+	}
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A deadline inside the reserve hands off before reading any content
+	ctx, cancel := context.WithTimeout(context.Background(), ContinuationReserve/2)
+	defer cancel()
+
+	ok, err := NewVerifier(ctx, store, client, obj).Verify()
+	if !ok || err != nil {
+		t.Fatalf("Expected a handoff, got %v (%v)", ok, err)
+	}
+
+	handedOff, _ := store.Get(obj)
+	if !handedOff.LastChecksumSuccess || !handedOff.HandedOff ||
+		!strings.Contains(handedOff.LastChecksumMessage, "continuation") {
+		t.Errorf("Expected the handoff to be recorded, got %+v", handedOff)
+	}
+	scheduled, err := store.Next(obj)
+	if err != nil || scheduled.NextChecksumDate.After(time.Now()) {
+		t.Errorf("Expected the verification to be due now, got %+v (%v)", scheduled, err)
+	}
+
+	// The next invocation completes the calculation (the content is read whole) and clears the handoff
+	delete(client.lengths, "bucket/large.bin")
+	ok, err = newTestVerifier(store, client, obj).Verify()
+	if !ok || err != nil {
+		t.Fatalf("Expected the verification to pass, got %v (%v)", ok, err)
+	}
+	verified, _ := store.Get(obj)
+	if verified.HandedOff || !verified.NextChecksumDate.After(verified.LastChecksumDate) {
+		t.Errorf("Expected the handoff to be cleared, got %+v", verified)
+	}
+}
+
+func TestVerifierHandOffRequest(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "large.bin", []byte("DuraCloud large content"))
+	client.lengths["bucket/large.bin"] = RangedReadThreshold
+	obj := files.NewS3Object("bucket", "large.bin")
+	store := &requestingStore{MemoryStore: db.NewMemoryStore()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ContinuationReserve/2)
+	defer cancel()
+
+	if err := NewVerifier(ctx, store, client, obj).Deposit(client.etag("bucket/large.bin")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The continuation is triggered at once rather than left to TTL deletion
+	if len(store.requests) != 1 || store.requests[0] != obj.URI()+" "+handOffRequestId {
+		t.Errorf("Expected the handoff to request a verification, got %v", store.requests)
+	}
+	if _, err := store.Next(obj); !errors.Is(err, db.ErrChecksumRecordNotFound) {
+		t.Errorf("Expected no scheduled verification, got %v", err)
+	}
+}
+
+func TestVerifierDeleted(t *testing.T) {
+	client := newMockS3Client()
+	obj := files.NewS3Object("bucket", "deleted.txt")
+	store := db.NewMemoryStore()
+
+	// The delete event of the version is (or will be) processed
+	err := newTestVerifier(store, client, obj).WithSequencer("0055AED6DCD90281E5").Deposit(`"etag"`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := store.Get(obj); !errors.Is(err, db.ErrChecksumRecordNotFound) {
+		t.Errorf("Expected no record for a version deleted before its deposit, got %v", err)
+	}
+
+	record := db.ChecksumRecord{
+		BucketName:          obj.Bucket,
+		ObjectKey:           obj.Key,
+		Checksum:            "abc123",
+		LastChecksumSuccess: true,
+	}
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ok, err := newTestVerifier(store, client, obj).Verify()
+	if ok || err != nil {
+		t.Fatalf("Expected the verification to fail, got %v (%v)", ok, err)
+	}
+	missing, _ := store.Get(obj)
+	if missing.LastChecksumSuccess || missing.FailureCategory != db.FailureMissing {
+		t.Errorf("Expected a missing record, got %+v", missing)
+	}
+}
+
+func TestVerifierHistoryFailure(t *testing.T) {
+	client := newMockS3Client()
+	client.addObject("bucket", "file.txt", []byte("DuraCloud deposit content"))
+	obj := files.NewS3Object("bucket", "file.txt")
+	store := historyFailingStore{db.NewMemoryStore()}
+
+	if err := newTestVerifier(store, client, obj).Deposit(client.etag("bucket/file.txt")); err != nil {
+		t.Fatalf("Expected the history failure to be logged, got %v", err)
+	}
+	if _, err := store.Next(obj); err != nil {
+		t.Errorf("Expected the deposit to be scheduled, got %v", err)
+	}
+}
+
+func TestVerifierExpired(t *testing.T) {
+	tests := []struct {
+		name   string
+		record db.ChecksumRecord
+	}{
+		{"scheduled", db.ChecksumRecord{Checksum: "abc123", LastChecksumSuccess: true}},
+		// A version reported missing before it expired is rechecked by the sweeper, not alerted again
+		{"rechecked", db.ChecksumRecord{Checksum: "abc123", FailureCategory: db.FailureMissing}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newMockS3Client()
+			client.addObject("bucket", "file.txt", []byte("DuraCloud current content"))
+			obj := files.NewS3ObjectVersion("bucket", "file.txt", "v1")
+			store := db.NewMemoryStore()
+
+			record := tt.record
+			record.BucketName = obj.Bucket
+			record.ObjectKey = obj.Key
+			record.VersionId = obj.VersionId
+			if err := store.Put(record); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The overwritten version was expired by the lifecycle rule before its event was processed
+			ok, err := newTestVerifier(store, client, obj).Verify()
+			if !ok || err != nil {
+				t.Fatalf("Expected the expiry not to fail the verification, got %v (%v)", ok, err)
+			}
+			if _, err := store.Get(obj); !errors.Is(err, db.ErrChecksumRecordNotFound) {
+				t.Errorf("Expected the record of the expired version to be deleted, got %v", err)
+			}
+			history, _ := store.History(obj)
+			if len(history) != 1 || history[0].EventType != db.FixityEventDeletion {
+				t.Errorf("Unexpected history: %+v", history)
+			}
+		})
+	}
+}
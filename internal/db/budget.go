@@ -0,0 +1,245 @@
+package db
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BudgetPeriod is the window a byte budget applies to
+type BudgetPeriod string
+
+const (
+	BudgetDaily  BudgetPeriod = "daily"
+	BudgetHourly BudgetPeriod = "hourly"
+)
+
+// BudgetExpiry is how long the usage of a budget window is kept for reporting
+const BudgetExpiry = 90 * 24 * time.Hour
+
+var byteSizePattern = regexp.MustCompile(`^(\d+)\s*([kmgtp]?)(i?b)?$`)
+
+// ByteBudget bounds the bytes read by verifications in each window of a period, a budget
+// of zero bytes is unlimited
+type ByteBudget struct {
+	Bytes  int64
+	Period BudgetPeriod
+}
+
+// BudgetUsage is the bytes read by verifications in a budget window, and the verifications
+// deferred to a later window because the budget was spent
+type BudgetUsage struct {
+	Period        string    `dynamodbav:"Period"`
+	Start         time.Time `dynamodbav:"Start"`
+	Bytes         int64     `dynamodbav:"Bytes"`
+	Verifications int64     `dynamodbav:"Verifications"`
+	Deferred      int64     `dynamodbav:"Deferred"`
+}
+
+// ParseByteBudget parses a budget such as "500GB" or "2TB" (binary units, 1KB is 1024 bytes)
+// and its period, an empty size is unlimited and an empty period is daily
+func ParseByteBudget(size, period string) (ByteBudget, error) {
+	budget := ByteBudget{Period: BudgetDaily}
+
+	switch BudgetPeriod(strings.ToLower(strings.TrimSpace(period))) {
+	case "", BudgetDaily:
+	case BudgetHourly:
+		budget.Period = BudgetHourly
+	default:
+		return budget, ErrorInvalidByteBudget(period)
+	}
+
+	size = strings.ToLower(strings.TrimSpace(size))
+	if size == "" {
+		return budget, nil
+	}
+
+	match := byteSizePattern.FindStringSubmatch(size)
+	if match == nil {
+		return budget, ErrorInvalidByteBudget(size)
+	}
+
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return budget, ErrorInvalidByteBudget(size)
+	}
+
+	shift := strings.Index("kmgtp", match[2]) + 1
+	if match[2] == "" {
+		shift = 0
+	}
+	if n > (1<<62)>>(10*shift) {
+		return budget, ErrorInvalidByteBudget(size)
+	}
+	budget.Bytes = n << (10 * shift)
+
+	return budget, nil
+}
+
+// IsUnlimited reports whether the budget does not bound verification
+func (b ByteBudget) IsUnlimited() bool {
+	return b.Bytes <= 0
+}
+
+// Window returns the start of the budget window that t falls in (UTC)
+func (b ByteBudget) Window(t time.Time) time.Time {
+	if b.Period == BudgetHourly {
+		return t.UTC().Truncate(time.Hour)
+	}
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns the start of the budget window after the one that t falls in
+func (b ByteBudget) Next(t time.Time) time.Time {
+	if b.Period == BudgetHourly {
+		return b.Window(t).Add(time.Hour)
+	}
+	return b.Window(t).AddDate(0, 0, 1)
+}
+
+// DeferUntil returns a random time (to the minute) within the budget window after t, so work
+// deferred together does not arrive at the start of the window at once
+func (b ByteBudget) DeferUntil(t time.Time) (time.Time, error) {
+	start := b.Next(t)
+	window := int64(b.Next(start).Sub(start) / time.Minute)
+
+	minutes, err := rand.Int(rand.Reader, big.NewInt(window))
+	if err != nil {
+		return start, ErrorGeneratingJitter("minute", err)
+	}
+
+	return start.Add(time.Duration(minutes.Int64()) * time.Minute), nil
+}
+
+func (b ByteBudget) String() string {
+	if b.IsUnlimited() {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d bytes %s", b.Bytes, b.Period)
+}
+
+// WithBudget keeps the verification byte budget counters in the budget table
+func (d *DB) WithBudget(budgetTable string) *DB {
+	d.budgetTable = budgetTable
+	return d
+}
+
+// ReserveBytes counts size bytes against the current window of a budget, it reports false
+// without counting them when they would exceed the budget. The first verification of a window
+// is always allowed so an object larger than the budget is still verified, and an unlimited
+// budget only counts the bytes.
+func (d *DB) ReserveBytes(budget ByteBudget, size int64) (bool, error) {
+	if d.budgetTable == "" {
+		return false, ErrorBudgetNotConfigured()
+	}
+
+	start := budget.Window(time.Now())
+	input := &dynamodb.UpdateItemInput{
+		TableName:        aws.String(d.budgetTable),
+		Key:              budgetKey(budget.Period, start),
+		UpdateExpression: aws.String("ADD #bytes :size, Verifications :one SET #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]string{
+			"#bytes": "Bytes",
+			"#ttl":   "TTL",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":size": &types.AttributeValueMemberN{Value: strconv.FormatInt(size, 10)},
+			":one":  &types.AttributeValueMemberN{Value: "1"},
+			":ttl":  &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", start.Add(BudgetExpiry).Unix())},
+		},
+	}
+	if !budget.IsUnlimited() {
+		input.ConditionExpression = aws.String("attribute_not_exists(#bytes) OR #bytes <= :remaining")
+		input.ExpressionAttributeValues[":remaining"] = &types.AttributeValueMemberN{
+			Value: strconv.FormatInt(budget.Bytes-size, 10),
+		}
+	}
+
+	_, err := d.client.UpdateItem(d.ctx, input)
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RecordDeferred counts a verification deferred from the current window of a budget
+func (d *DB) RecordDeferred(budget ByteBudget) error {
+	if d.budgetTable == "" {
+		return ErrorBudgetNotConfigured()
+	}
+
+	start := budget.Window(time.Now())
+	_, err := d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(d.budgetTable),
+		Key:              budgetKey(budget.Period, start),
+		UpdateExpression: aws.String("ADD Deferred :one SET #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]string{
+			"#ttl": "TTL",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+			":ttl": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", start.Add(BudgetExpiry).Unix())},
+		},
+	})
+	return err
+}
+
+// BudgetUsage returns the usage of the windows of a budget period that started at or after since, oldest first
+func (d *DB) BudgetUsage(period BudgetPeriod, since time.Time) ([]BudgetUsage, error) {
+	if d.budgetTable == "" {
+		return nil, ErrorBudgetNotConfigured()
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(d.budgetTable),
+		KeyConditionExpression: aws.String("#period = :period AND #start >= :since"),
+		ExpressionAttributeNames: map[string]string{
+			"#period": "Period",
+			"#start":  "Start",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":period": &types.AttributeValueMemberS{Value: string(period)},
+			":since":  &types.AttributeValueMemberS{Value: since.UTC().Format(time.RFC3339)},
+		},
+	}
+
+	var usage []BudgetUsage
+	paginator := dynamodb.NewQueryPaginator(d.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(d.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var items []BudgetUsage
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &items); err != nil {
+			return nil, ErrorUnmarshallingBudget(err)
+		}
+		usage = append(usage, items...)
+	}
+
+	return usage, nil
+}
+
+func budgetKey(period BudgetPeriod, start time.Time) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"Period": &types.AttributeValueMemberS{Value: string(period)},
+		"Start":  &types.AttributeValueMemberS{Value: start.UTC().Format(time.RFC3339)},
+	}
+}
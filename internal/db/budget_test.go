@@ -0,0 +1,91 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseByteBudget(t *testing.T) {
+	tests := []struct {
+		size     string
+		period   string
+		expected ByteBudget
+		wantErr  bool
+	}{
+		{"", "", ByteBudget{Period: BudgetDaily}, false},
+		{"500GB", "", ByteBudget{Bytes: 500 << 30, Period: BudgetDaily}, false},
+		{"2TB", "daily", ByteBudget{Bytes: 2 << 40, Period: BudgetDaily}, false},
+		{" 10 GiB ", "Hourly", ByteBudget{Bytes: 10 << 30, Period: BudgetHourly}, false},
+		{"1024", "hourly", ByteBudget{Bytes: 1024, Period: BudgetHourly}, false},
+		{"1k", "", ByteBudget{Bytes: 1024, Period: BudgetDaily}, false},
+		{"2TB", "weekly", ByteBudget{}, true},
+		{"TB", "", ByteBudget{}, true},
+		{"1.5TB", "", ByteBudget{}, true},
+		{"-1GB", "", ByteBudget{}, true},
+		{"9999999PB", "", ByteBudget{}, true},
+	}
+
+	for _, tt := range tests {
+		budget, err := ParseByteBudget(tt.size, tt.period)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseByteBudget(%q, %q) error = %v, wantErr %v", tt.size, tt.period, err, tt.wantErr)
+			continue
+		}
+
+		if !tt.wantErr && budget != tt.expected {
+			t.Errorf("ParseByteBudget(%q, %q) = %+v, expected %+v", tt.size, tt.period, budget, tt.expected)
+		}
+	}
+}
+
+func TestByteBudgetWindow(t *testing.T) {
+	at := time.Date(2025, 1, 31, 23, 45, 10, 0, time.UTC)
+
+	tests := []struct {
+		period BudgetPeriod
+		window time.Time
+		next   time.Time
+	}{
+		{BudgetDaily, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{BudgetHourly, time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		budget := ByteBudget{Bytes: 1, Period: tt.period}
+		if window := budget.Window(at); !window.Equal(tt.window) {
+			t.Errorf("%s window = %v, expected %v", tt.period, window, tt.window)
+		}
+		if next := budget.Next(at); !next.Equal(tt.next) {
+			t.Errorf("%s next = %v, expected %v", tt.period, next, tt.next)
+		}
+	}
+}
+
+func TestByteBudgetDeferUntil(t *testing.T) {
+	at := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+
+	for _, period := range []BudgetPeriod{BudgetDaily, BudgetHourly} {
+		budget := ByteBudget{Bytes: 1, Period: period}
+		start := budget.Next(at)
+		end := budget.Next(start)
+
+		for range 50 {
+			next, err := budget.DeferUntil(at)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if next.Before(start) || !next.Before(end) {
+				t.Errorf("%s deferral %v outside the next window [%v, %v)", period, next, start, end)
+			}
+		}
+	}
+}
+
+func TestByteBudgetIsUnlimited(t *testing.T) {
+	if !(ByteBudget{Period: BudgetDaily}).IsUnlimited() {
+		t.Error("Expected a zero byte budget to be unlimited")
+	}
+	if (ByteBudget{Bytes: 1, Period: BudgetDaily}).IsUnlimited() {
+		t.Error("Expected a one byte budget to be limited")
+	}
+}
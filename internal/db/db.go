@@ -2,10 +2,10 @@ package db
 
 import (
 	"context"
-	"crypto/rand"
 	"duracloud/internal/files"
+	"errors"
 	"fmt"
-	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -18,30 +18,163 @@ import (
 type ChecksumTableId string
 
 const (
-	ChecksumTableBucketNameId ChecksumTableId = "BucketName"
-	ChecksumTableObjectKeyId  ChecksumTableId = "ObjectKey"
-	ChecksumTableMessageId    ChecksumTableId = "LastChecksumMessage"
-	ChecksumTableStatusId     ChecksumTableId = "LastChecksumSuccess"
+	ChecksumTableBucketNameId     ChecksumTableId = "BucketName"
+	ChecksumTableCategoryId       ChecksumTableId = "FailureCategory"
+	ChecksumTableChecksumsId      ChecksumTableId = "Checksums"
+	ChecksumTableDateId           ChecksumTableId = "LastChecksumDate"
+	ChecksumTableObjectKeyId      ChecksumTableId = "ObjectKey"
+	ChecksumTableMessageId        ChecksumTableId = "LastChecksumMessage"
+	ChecksumTableMigratedId       ChecksumTableId = "MigratedFrom"
+	ChecksumTableNativeId         ChecksumTableId = "NativeChecksum"
+	ChecksumTableReplicaId        ChecksumTableId = "ReplicaStatus"
+	ChecksumTableReplicaMessageId ChecksumTableId = "ReplicaMessage"
+	ChecksumTableSequencerId      ChecksumTableId = "Sequencer"
+	ChecksumTableStatusId         ChecksumTableId = "LastChecksumSuccess"
+	ChecksumTableVersionIdId      ChecksumTableId = "VersionId"
+	ChecksumTableVersionKeyId     ChecksumTableId = "VersionKey"
 )
 
+// Failure categories of a record whose last check failed, corrupted means the content was
+// read and does not match, unreadable means the content could not be read after retrying
+const (
+	FailureCorrupted  = "corrupted"
+	FailureMissing    = "missing"
+	FailureUnreadable = "unreadable"
+)
+
+// Replica statuses of a record that are not failures, a replica that failed its check
+// has the failure category as its status
+const (
+	ReplicaArchived  = "archived"
+	ReplicaOK        = "ok"
+	ReplicaPending   = "pending"
+	ReplicaRestoring = "restoring"
+)
+
+// Repair statuses of a record whose corrupted primary was (or would be) replaced by its replica
+const (
+	RepairDryRun   = "dry-run"
+	RepairFailed   = "failed"
+	RepairRefused  = "refused"
+	RepairRepaired = "repaired"
+)
+
+// The copy (or copies) of an object version that did not match when a check failed
+const (
+	DivergedBoth    = "primary and replica"
+	DivergedPrimary = "primary"
+	DivergedReplica = "replica"
+)
+
+// ChecksumRecord holds the fixity state of an object, Checksum is the primary (md5)
+// value and Checksums maps each calculated algorithm to its digest. NativeChecksum is
+// the S3 additional checksum supplied by the client on upload (if any) and is the
+// baseline for NativeChecksumAlgorithm. SuppliedChecksums maps the user metadata header of
+// each checksum the depositor supplied (i.e. x-amz-meta-sha256) to its value. FailureCategory is set while the last check of the
+// primary copy failed, ReplicaStatus is the outcome of the last check of the replica copy
+// (at ReplicaCheckDate, replicas are checked less often than the primary)
+// and RepairStatus the outcome of replacing a corrupted primary with the replica, where
+// RepairVersionId is the new version. Sequencer is the (normalized) sequencer of the S3 event
+// that deposited the version, only later events change the record. Size is the length of the
+// version in bytes (zero for records deposited before it was stored). LegacyChecksums are the
+// checksums imported from a legacy repository the deposit was compared with and LegacyStatus
+// whether it arrived intact. Each version of an object has its own record.
 type ChecksumRecord struct {
-	BucketName          string    `dynamodbav:"BucketName"`
-	ObjectKey           string    `dynamodbav:"ObjectKey"`
-	Checksum            string    `dynamodbav:"Checksum"`
-	LastChecksumDate    time.Time `dynamodbav:"LastChecksumDate"`
-	LastChecksumMessage string    `dynamodbav:"LastChecksumMessage"`
-	LastChecksumSuccess bool      `dynamodbav:"LastChecksumSuccess"`
-	NextChecksumDate    time.Time `dynamodbav:"NextChecksumDate"`
+	BucketName              string            `dynamodbav:"BucketName"`
+	ObjectKey               string            `dynamodbav:"ObjectKey"`
+	Checksum                string            `dynamodbav:"Checksum"`
+	Checksums               map[string]string `dynamodbav:"Checksums"`
+	FailureCategory         string            `dynamodbav:"FailureCategory"`
+	HandedOff               bool              `dynamodbav:"HandedOff"`
+	LastChecksumDate        time.Time         `dynamodbav:"LastChecksumDate"`
+	LastChecksumMessage     string            `dynamodbav:"LastChecksumMessage"`
+	LastChecksumSuccess     bool              `dynamodbav:"LastChecksumSuccess"`
+	LegacyChecksums         map[string]string `dynamodbav:"LegacyChecksums"`
+	LegacyStatus            string            `dynamodbav:"LegacyStatus"`
+	NativeChecksum          string            `dynamodbav:"NativeChecksum"`
+	NativeChecksumAlgorithm string            `dynamodbav:"NativeChecksumAlgorithm"`
+	NextChecksumDate        time.Time         `dynamodbav:"NextChecksumDate"`
+	RepairDate              time.Time         `dynamodbav:"RepairDate"`
+	RepairMessage           string            `dynamodbav:"RepairMessage"`
+	RepairStatus            string            `dynamodbav:"RepairStatus"`
+	RepairVersionId         string            `dynamodbav:"RepairVersionId"`
+	ReplicaCheckDate        time.Time         `dynamodbav:"ReplicaCheckDate"`
+	ReplicaMessage          string            `dynamodbav:"ReplicaMessage"`
+	ReplicaStatus           string            `dynamodbav:"ReplicaStatus"`
+	Sequencer               string            `dynamodbav:"Sequencer"`
+	Size                    int64             `dynamodbav:"Size"`
+	SuppliedChecksums       map[string]string `dynamodbav:"SuppliedChecksums"`
+	UnconfirmedMismatch     string            `dynamodbav:"UnconfirmedMismatch"`
+	VersionId               string            `dynamodbav:"VersionId"`
+}
+
+// Object returns the object version the record belongs to
+func (r ChecksumRecord) Object() files.S3Object {
+	return files.NewS3ObjectVersion(r.BucketName, r.ObjectKey, r.VersionId)
+}
+
+// IsReplicaFailure reports whether a replica status is a failure category
+func IsReplicaFailure(status string) bool {
+	switch status {
+	case "", ReplicaArchived, ReplicaOK, ReplicaPending, ReplicaRestoring:
+		return false
+	}
+	return true
+}
+
+// Diverged returns the copy (or copies) of a failed record that did not match, a record that
+// failed without a replica failure is a primary failure
+func Diverged(failureCategory, replicaStatus string) string {
+	replicaFailed := IsReplicaFailure(replicaStatus)
+	switch {
+	case replicaFailed && failureCategory != "":
+		return DivergedBoth
+	case replicaFailed:
+		return DivergedReplica
+	default:
+		return DivergedPrimary
+	}
+}
+
+// VersionKey returns the sort key of an object version, the object key suffixed by its
+// version id so every version of an object shares a prefix
+func VersionKey(obj files.S3Object) string {
+	versionId := obj.VersionId
+	if versionId == "" {
+		versionId = files.NullVersionId
+	}
+	return obj.Key + "#" + versionId
+}
+
+func key(obj files.S3Object) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"BucketName": &types.AttributeValueMemberS{Value: obj.Bucket},
+		"VersionKey": &types.AttributeValueMemberS{Value: VersionKey(obj)},
+	}
+}
+
+// DynamoDBClientInterface defines the DynamoDB operations required by the DB
+type DynamoDBClientInterface interface {
+	DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	GetItem(ctx context.Context, input *dynamodb.GetItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, input *dynamodb.PutItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, input *dynamodb.ScanInput, opts ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 }
 
 type DB struct {
 	ctx            context.Context
-	client         *dynamodb.Client
+	client         DynamoDBClientInterface
+	budgetTable    string
 	checksumTable  string
+	historyTable   string
+	legacyTable    string
+	restoreTable   string
 	schedulerTable string
 }
 
-func NewDB(ctx context.Context, client *dynamodb.Client, checksumTable, schedulerTable string) *DB {
+func NewDB(ctx context.Context, client DynamoDBClientInterface, checksumTable, schedulerTable string) *DB {
 	return &DB{
 		ctx:            ctx,
 		client:         client,
@@ -50,9 +183,27 @@ func NewDB(ctx context.Context, client *dynamodb.Client, checksumTable, schedule
 	}
 }
 
-func (d *DB) Delete(obj files.S3Object) error {
-	err := d.delete(d.checksumTable, obj)
+// Delete removes the checksum record and scheduled verification of an object version. The
+// sequencer of the delete event (if any) must be after the sequencer of the record, a record
+// deposited by a later event is kept and ErrStaleSequencer returned.
+func (d *DB) Delete(obj files.S3Object, sequencer string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.checksumTable),
+		Key:       key(obj),
+	}
+	if sequencer = NormalizeSequencer(sequencer); sequencer != "" {
+		input.ConditionExpression = aws.String("attribute_not_exists(Sequencer) OR Sequencer < :sequencer")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":sequencer": &types.AttributeValueMemberS{Value: sequencer},
+		}
+	}
+
+	_, err := d.client.DeleteItem(d.ctx, input)
 	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrorStaleSequencer(obj.Bucket, obj.Key, sequencer)
+		}
 		return err
 	}
 
@@ -62,10 +213,7 @@ func (d *DB) Delete(obj files.S3Object) error {
 func (d *DB) delete(table string, obj files.S3Object) error {
 	_, err := d.client.DeleteItem(d.ctx, &dynamodb.DeleteItemInput{
 		TableName: aws.String(table),
-		Key: map[string]types.AttributeValue{
-			"BucketName": &types.AttributeValueMemberS{Value: obj.Bucket},
-			"ObjectKey":  &types.AttributeValueMemberS{Value: obj.Key},
-		},
+		Key:       key(obj),
 	})
 	return err
 }
@@ -77,10 +225,7 @@ func (d *DB) Get(obj files.S3Object) (ChecksumRecord, error) {
 func (d *DB) get(table string, obj files.S3Object) (ChecksumRecord, error) {
 	result, err := d.client.GetItem(d.ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(table),
-		Key: map[string]types.AttributeValue{
-			"BucketName": &types.AttributeValueMemberS{Value: obj.Bucket},
-			"ObjectKey":  &types.AttributeValueMemberS{Value: obj.Key},
-		},
+		Key:       key(obj),
 	})
 	if err != nil {
 		return ChecksumRecord{}, err
@@ -103,57 +248,172 @@ func (d *DB) Next(obj files.S3Object) (ChecksumRecord, error) {
 	return d.get(d.schedulerTable, obj)
 }
 
-func (d *DB) Put(record ChecksumRecord) error {
-	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(d.checksumTable),
-		Item: map[string]types.AttributeValue{
-			"BucketName":          &types.AttributeValueMemberS{Value: record.BucketName},
-			"ObjectKey":           &types.AttributeValueMemberS{Value: record.ObjectKey},
-			"Checksum":            &types.AttributeValueMemberS{Value: record.Checksum},
-			"LastChecksumDate":    &types.AttributeValueMemberS{Value: record.LastChecksumDate.Format(time.RFC3339)},
-			"LastChecksumMessage": &types.AttributeValueMemberS{Value: record.LastChecksumMessage},
-			"LastChecksumSuccess": &types.AttributeValueMemberBOOL{Value: record.LastChecksumSuccess},
-			"NextChecksumDate":    &types.AttributeValueMemberS{Value: record.NextChecksumDate.Format(time.RFC3339)},
+// Records calls fn with every checksum record of a bucket in key order, it stops at the
+// first error
+func (d *DB) Records(bucket string, fn func(ChecksumRecord) error) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(d.checksumTable),
+		KeyConditionExpression: aws.String("BucketName = :bucket"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bucket": &types.AttributeValueMemberS{Value: bucket},
 		},
-	})
-	return err
-}
+	}
 
-func (d *DB) Schedule(record ChecksumRecord) error {
-	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(d.schedulerTable),
-		Item: map[string]types.AttributeValue{
-			"BucketName":       &types.AttributeValueMemberS{Value: record.BucketName},
-			"ObjectKey":        &types.AttributeValueMemberS{Value: record.ObjectKey},
-			"NextChecksumDate": &types.AttributeValueMemberS{Value: record.NextChecksumDate.Format(time.RFC3339)},
-			"TTL":              &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", record.NextChecksumDate.Unix())},
-		},
-	})
-	return err
+	for {
+		page, err := d.client.Query(d.ctx, input)
+		if err != nil {
+			return err
+		}
+
+		var records []ChecksumRecord
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			return ErrorUnmarshallingChecksum(err)
+		}
+
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		if page.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
 }
 
-func GetNextScheduledTime() (time.Time, error) {
-	baseTime := time.Now().AddDate(0, 5, 14)
+// Put writes the checksum record of an object version. A record with a sequencer is only
+// written when the stored record has none or the same or an earlier one, otherwise the
+// record was deposited by a later event and ErrStaleSequencer is returned.
+func (d *DB) Put(record ChecksumRecord) error {
+	item := map[string]types.AttributeValue{
+		"BucketName":          &types.AttributeValueMemberS{Value: record.BucketName},
+		"ObjectKey":           &types.AttributeValueMemberS{Value: record.ObjectKey},
+		"VersionKey":          &types.AttributeValueMemberS{Value: VersionKey(record.Object())},
+		"Checksum":            &types.AttributeValueMemberS{Value: record.Checksum},
+		"LastChecksumDate":    &types.AttributeValueMemberS{Value: record.LastChecksumDate.Format(time.RFC3339)},
+		"LastChecksumMessage": &types.AttributeValueMemberS{Value: record.LastChecksumMessage},
+		"LastChecksumSuccess": &types.AttributeValueMemberBOOL{Value: record.LastChecksumSuccess},
+		"NextChecksumDate":    &types.AttributeValueMemberS{Value: record.NextChecksumDate.Format(time.RFC3339)},
+	}
 
-	jitterDays, err := rand.Int(rand.Reader, big.NewInt(30))
-	if err != nil {
-		return baseTime, ErrorGeneratingJitter("day", err)
+	if len(record.Checksums) > 0 {
+		checksums := make(map[string]types.AttributeValue, len(record.Checksums))
+		for algorithm, value := range record.Checksums {
+			checksums[algorithm] = &types.AttributeValueMemberS{Value: value}
+		}
+		item["Checksums"] = &types.AttributeValueMemberM{Value: checksums}
 	}
 
-	jitterHours, err := rand.Int(rand.Reader, big.NewInt(24))
-	if err != nil {
-		return baseTime, ErrorGeneratingJitter("hour", err)
+	if record.VersionId != "" {
+		item["VersionId"] = &types.AttributeValueMemberS{Value: record.VersionId}
+	}
+
+	if record.Size > 0 {
+		item["Size"] = &types.AttributeValueMemberN{Value: strconv.FormatInt(record.Size, 10)}
+	}
+
+	if len(record.SuppliedChecksums) > 0 {
+		supplied := make(map[string]types.AttributeValue, len(record.SuppliedChecksums))
+		for source, value := range record.SuppliedChecksums {
+			supplied[source] = &types.AttributeValueMemberS{Value: value}
+		}
+		item["SuppliedChecksums"] = &types.AttributeValueMemberM{Value: supplied}
+	}
+
+	if len(record.LegacyChecksums) > 0 {
+		legacy := make(map[string]types.AttributeValue, len(record.LegacyChecksums))
+		for algorithm, value := range record.LegacyChecksums {
+			legacy[algorithm] = &types.AttributeValueMemberS{Value: value}
+		}
+		item["LegacyChecksums"] = &types.AttributeValueMemberM{Value: legacy}
+		item["LegacyStatus"] = &types.AttributeValueMemberS{Value: record.LegacyStatus}
+	}
+
+	if record.NativeChecksum != "" {
+		item["NativeChecksum"] = &types.AttributeValueMemberS{Value: record.NativeChecksum}
+		item["NativeChecksumAlgorithm"] = &types.AttributeValueMemberS{Value: record.NativeChecksumAlgorithm}
+	}
+
+	if !record.LastChecksumSuccess && record.FailureCategory != "" {
+		item["FailureCategory"] = &types.AttributeValueMemberS{Value: record.FailureCategory}
+	}
+
+	if !record.LastChecksumSuccess {
+		// Failing records (of the primary or the replica) are listed by the failures index
+		item["FailureKey"] = &types.AttributeValueMemberS{Value: VersionKey(record.Object())}
+	}
+
+	if record.ReplicaStatus != "" {
+		item["ReplicaStatus"] = &types.AttributeValueMemberS{Value: record.ReplicaStatus}
+		item["ReplicaMessage"] = &types.AttributeValueMemberS{Value: record.ReplicaMessage}
 	}
 
-	jitterMinutes, err := rand.Int(rand.Reader, big.NewInt(60))
+	if !record.ReplicaCheckDate.IsZero() {
+		item["ReplicaCheckDate"] = &types.AttributeValueMemberS{Value: record.ReplicaCheckDate.Format(time.RFC3339)}
+	}
+
+	if record.RepairStatus != "" {
+		item["RepairDate"] = &types.AttributeValueMemberS{Value: record.RepairDate.Format(time.RFC3339)}
+		item["RepairMessage"] = &types.AttributeValueMemberS{Value: record.RepairMessage}
+		item["RepairStatus"] = &types.AttributeValueMemberS{Value: record.RepairStatus}
+	}
+
+	if record.RepairVersionId != "" {
+		item["RepairVersionId"] = &types.AttributeValueMemberS{Value: record.RepairVersionId}
+	}
+
+	if record.HandedOff {
+		item["HandedOff"] = &types.AttributeValueMemberBOOL{Value: true}
+	}
+
+	if record.UnconfirmedMismatch != "" {
+		item["UnconfirmedMismatch"] = &types.AttributeValueMemberS{Value: record.UnconfirmedMismatch}
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(d.checksumTable),
+		Item:      item,
+	}
+
+	sequencer := NormalizeSequencer(record.Sequencer)
+	if sequencer != "" {
+		item["Sequencer"] = &types.AttributeValueMemberS{Value: sequencer}
+		input.ConditionExpression = aws.String("attribute_not_exists(Sequencer) OR Sequencer <= :sequencer")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":sequencer": &types.AttributeValueMemberS{Value: sequencer},
+		}
+	}
+
+	_, err := d.client.PutItem(d.ctx, input)
 	if err != nil {
-		return baseTime, ErrorGeneratingJitter("minute", err)
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return ErrorStaleSequencer(record.BucketName, record.ObjectKey, sequencer)
+		}
+		return err
 	}
 
-	scheduledTime := baseTime.
-		AddDate(0, 0, int(jitterDays.Int64())).
-		Add(time.Duration(jitterHours.Int64()) * time.Hour).
-		Add(time.Duration(jitterMinutes.Int64()) * time.Minute)
+	return nil
+}
+
+func (d *DB) Schedule(record ChecksumRecord) error {
+	item := map[string]types.AttributeValue{
+		"BucketName":       &types.AttributeValueMemberS{Value: record.BucketName},
+		"ObjectKey":        &types.AttributeValueMemberS{Value: record.ObjectKey},
+		"VersionKey":       &types.AttributeValueMemberS{Value: VersionKey(record.Object())},
+		"NextChecksumDate": &types.AttributeValueMemberS{Value: record.NextChecksumDate.Format(time.RFC3339)},
+		"TTL":              &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", record.NextChecksumDate.Unix())},
+	}
 
-	return scheduledTime, nil
+	if record.VersionId != "" {
+		item["VersionId"] = &types.AttributeValueMemberS{Value: record.VersionId}
+	}
+
+	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.schedulerTable),
+		Item:      item,
+	})
+	return err
 }
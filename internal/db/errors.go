@@ -6,11 +6,35 @@ import (
 )
 
 var (
+	ErrAppendingEvent         = errors.New("failed to append fixity event")
+	ErrBudgetNotConfigured    = errors.New("budget table is not configured")
 	ErrChecksumRecordNotFound = errors.New("checksum record not found")
+	ErrHistoryNotConfigured   = errors.New("history table is not configured")
+	ErrInvalidByteBudget      = errors.New("invalid byte budget")
+	ErrInvalidPeriod          = errors.New("invalid period")
+	ErrInvalidRecheckPolicy   = errors.New("invalid recheck policy")
 	ErrJitterGeneration       = errors.New("jitter generation failed")
+	ErrLegacyChecksumClaimed  = errors.New("legacy checksum already compared with a deposit")
+	ErrLegacyChecksumNotFound = errors.New("legacy checksum not found")
+	ErrLegacyNotConfigured    = errors.New("legacy table is not configured")
+	ErrRestoreNotFound        = errors.New("restore request not found")
+	ErrRestoresNotConfigured  = errors.New("restore table is not configured")
+	ErrStaleSequencer         = errors.New("a later event has already updated the checksum record")
+	ErrUnmarshallingBudget    = errors.New("failed to unmarshal budget usage")
 	ErrUnmarshallingChecksum  = errors.New("failed to unmarshal checksum record")
+	ErrUnmarshallingEvent     = errors.New("failed to unmarshal fixity event")
+	ErrUnmarshallingLegacy    = errors.New("failed to unmarshal legacy checksum")
+	ErrUnmarshallingRestore   = errors.New("failed to unmarshal restore request")
 )
 
+func ErrorAppendingEvent(objectId string, cause error) error {
+	return fmt.Errorf("%w: object=%s cause=%v", ErrAppendingEvent, objectId, cause)
+}
+
+func ErrorBudgetNotConfigured() error {
+	return ErrBudgetNotConfigured
+}
+
 func ErrorChecksumRecordNotFound(bucket, key string) error {
 	return fmt.Errorf("%w: bucket=%s key=%s", ErrChecksumRecordNotFound, bucket, key)
 }
@@ -19,6 +43,62 @@ func ErrorGeneratingJitter(jitterType string, cause error) error {
 	return fmt.Errorf("%w: type=%s cause=%v", ErrJitterGeneration, jitterType, cause)
 }
 
+func ErrorHistoryNotConfigured(objectId string) error {
+	return fmt.Errorf("%w: object=%s", ErrHistoryNotConfigured, objectId)
+}
+
+func ErrorInvalidByteBudget(value string) error {
+	return fmt.Errorf("%w: value=%q (expected a size such as 500GB or 2TB, daily or hourly)", ErrInvalidByteBudget, value)
+}
+
+func ErrorInvalidPeriod(value string) error {
+	return fmt.Errorf("%w: value=%q (expected a period such as 3m, 1y or 5m14d)", ErrInvalidPeriod, value)
+}
+
+func ErrorInvalidRecheckPolicy(value string) error {
+	return fmt.Errorf("%w: value=%q (expected all, none or transient)", ErrInvalidRecheckPolicy, value)
+}
+
+func ErrorLegacyChecksumClaimed(bucket, key string) error {
+	return fmt.Errorf("%w: bucket=%s key=%s", ErrLegacyChecksumClaimed, bucket, key)
+}
+
+func ErrorLegacyChecksumNotFound(bucket, key string) error {
+	return fmt.Errorf("%w: bucket=%s key=%s", ErrLegacyChecksumNotFound, bucket, key)
+}
+
+func ErrorLegacyNotConfigured(objectId string) error {
+	return fmt.Errorf("%w: object=%s", ErrLegacyNotConfigured, objectId)
+}
+
+func ErrorRestoreNotFound(bucket, key string) error {
+	return fmt.Errorf("%w: bucket=%s key=%s", ErrRestoreNotFound, bucket, key)
+}
+
+func ErrorRestoresNotConfigured(objectId string) error {
+	return fmt.Errorf("%w: object=%s", ErrRestoresNotConfigured, objectId)
+}
+
+func ErrorStaleSequencer(bucket, key, sequencer string) error {
+	return fmt.Errorf("%w: bucket=%s key=%s sequencer=%s", ErrStaleSequencer, bucket, key, sequencer)
+}
+
+func ErrorUnmarshallingBudget(cause error) error {
+	return fmt.Errorf("%w: cause=%v", ErrUnmarshallingBudget, cause)
+}
+
 func ErrorUnmarshallingChecksum(cause error) error {
 	return fmt.Errorf("%w: cause=%v", ErrUnmarshallingChecksum, cause)
 }
+
+func ErrorUnmarshallingEvent(cause error) error {
+	return fmt.Errorf("%w: cause=%v", ErrUnmarshallingEvent, cause)
+}
+
+func ErrorUnmarshallingLegacy(cause error) error {
+	return fmt.Errorf("%w: cause=%v", ErrUnmarshallingLegacy, cause)
+}
+
+func ErrorUnmarshallingRestore(cause error) error {
+	return fmt.Errorf("%w: cause=%v", ErrUnmarshallingRestore, cause)
+}
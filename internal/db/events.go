@@ -1,6 +1,7 @@
 package db
 
 import (
+	"bytes"
 	"duracloud/internal/files"
 	"fmt"
 
@@ -18,7 +19,12 @@ func ExtractBucketAndObject(record events.DynamoDBEventRecord) (files.S3Object,
 		return files.S3Object{}, fmt.Errorf("missing object key attribute")
 	}
 
-	return files.S3Object{Bucket: bucket.String(), Key: object.String()}, nil
+	var versionId string
+	if version, exists := record.Change.OldImage[string(ChecksumTableVersionIdId)]; exists {
+		versionId = version.String()
+	}
+
+	return files.NewS3ObjectVersion(bucket.String(), object.String(), versionId), nil
 }
 
 func IsTTLExpiry(record events.DynamoDBEventRecord) bool {
@@ -27,3 +33,41 @@ func IsTTLExpiry(record events.DynamoDBEventRecord) bool {
 		record.UserIdentity.Type == "Service" &&
 		record.UserIdentity.PrincipalID == "dynamodb.amazonaws.com"
 }
+
+// IsMigration checks if a checksum table event is the copy of a record written before records
+// were version-aware (see MigrateObjectKeyTables), not a deposit
+func IsMigration(record events.DynamoDBEventRecord) bool {
+	if record.EventName != "INSERT" {
+		return false
+	}
+
+	_, exists := record.Change.NewImage[string(ChecksumTableMigratedId)]
+	return exists
+}
+
+// IsNewCheck checks if a checksum table event records a deposit or verification, a change that
+// leaves the outcome and date of the last check as they were (such as indexing a failure or
+// moving the next check) is not one
+func IsNewCheck(record events.DynamoDBEventRecord) bool {
+	if record.EventName != "MODIFY" {
+		return record.EventName == "INSERT"
+	}
+
+	return changed(record, ChecksumTableStatusId) || changed(record, ChecksumTableDateId)
+}
+
+// changed checks if an attribute differs between the old and new image of an event
+func changed(record events.DynamoDBEventRecord, id ChecksumTableId) bool {
+	oldValue, oldExists := record.Change.OldImage[string(id)]
+	newValue, newExists := record.Change.NewImage[string(id)]
+	if oldExists != newExists {
+		return true
+	}
+	if !oldExists {
+		return false
+	}
+
+	oldJSON, oldErr := oldValue.MarshalJSON()
+	newJSON, newErr := newValue.MarshalJSON()
+	return oldErr != nil || newErr != nil || !bytes.Equal(oldJSON, newJSON)
+}
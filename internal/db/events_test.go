@@ -0,0 +1,47 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestIsNewCheck(t *testing.T) {
+	failed := func(date string) map[string]events.DynamoDBAttributeValue {
+		return map[string]events.DynamoDBAttributeValue{
+			string(ChecksumTableStatusId): events.NewBooleanAttribute(false),
+			string(ChecksumTableDateId):   events.NewStringAttribute(date),
+		}
+	}
+	indexed := failed("2025-06-01T00:00:00Z")
+	indexed["FailureKey"] = events.NewStringAttribute("file.txt#null")
+	passed := failed("2025-06-01T00:00:00Z")
+	passed[string(ChecksumTableStatusId)] = events.NewBooleanAttribute(true)
+
+	tests := []struct {
+		name     string
+		event    string
+		old      map[string]events.DynamoDBAttributeValue
+		new      map[string]events.DynamoDBAttributeValue
+		expected bool
+	}{
+		{"deposit", "INSERT", nil, failed("2025-06-01T00:00:00Z"), true},
+		{"verification", "MODIFY", failed("2025-06-01T00:00:00Z"), failed("2025-07-01T00:00:00Z"), true},
+		{"outcome changed", "MODIFY", passed, failed("2025-06-01T00:00:00Z"), true},
+		{"failure indexed", "MODIFY", failed("2025-06-01T00:00:00Z"), indexed, false},
+		{"next check moved", "MODIFY", failed("2025-06-01T00:00:00Z"), failed("2025-06-01T00:00:00Z"), false},
+		{"deleted", "REMOVE", failed("2025-06-01T00:00:00Z"), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := events.DynamoDBEventRecord{
+				EventName: tt.event,
+				Change:    events.DynamoDBStreamRecord{OldImage: tt.old, NewImage: tt.new},
+			}
+			if got := IsNewCheck(record); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
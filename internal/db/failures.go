@@ -0,0 +1,134 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// FailuresIndex is the sparse index of the checksum table over the records whose last check
+// failed, it is keyed by BucketName and FailureKey (the VersionKey of the record) which is
+// only present while the record is failing
+const FailuresIndex = "FailuresIndex"
+
+// DefaultPageLimit is the number of records in a page when a Page has no limit
+const DefaultPageLimit = 100
+
+// Page selects part of a listing, StartKey is the NextKey of the previous page (empty for the first)
+type Page struct {
+	Limit    int
+	StartKey string
+}
+
+// FailurePage is a page of failing records ordered by key, NextKey is empty on the last page
+type FailurePage struct {
+	Records []ChecksumRecord
+	NextKey string
+}
+
+// IndexResult summarizes an IndexFailures
+type IndexResult struct {
+	Pass
+	Indexed int
+}
+
+// ListFailures returns a page of the records of a bucket whose last check failed, with the
+// failure category and messages of the last check
+func (d *DB) ListFailures(bucket string, page Page) (FailurePage, error) {
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(d.checksumTable),
+		IndexName:              aws.String(FailuresIndex),
+		KeyConditionExpression: aws.String("BucketName = :bucket"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bucket": &types.AttributeValueMemberS{Value: bucket},
+		},
+	}
+	if page.StartKey != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"BucketName": &types.AttributeValueMemberS{Value: bucket},
+			"VersionKey": &types.AttributeValueMemberS{Value: page.StartKey},
+			"FailureKey": &types.AttributeValueMemberS{Value: page.StartKey},
+		}
+	}
+
+	var result FailurePage
+	for {
+		input.Limit = aws.Int32(int32(limit - len(result.Records)))
+		resp, err := d.client.Query(d.ctx, input)
+		if err != nil {
+			return result, err
+		}
+
+		var records []ChecksumRecord
+		if err := attributevalue.UnmarshalListOfMaps(resp.Items, &records); err != nil {
+			return result, ErrorUnmarshallingChecksum(err)
+		}
+		result.Records = append(result.Records, records...)
+
+		if resp.LastEvaluatedKey == nil {
+			return result, nil
+		}
+		if len(result.Records) >= limit {
+			result.NextKey = VersionKey(result.Records[len(result.Records)-1].Object())
+			return result, nil
+		}
+		input.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+}
+
+// IndexFailures adds the failing records of a bucket written before the failures index existed
+// to the index. It is a pass over the bucket (see Pass).
+func (d *DB) IndexFailures(bucket, startKey string) (IndexResult, error) {
+	var result IndexResult
+	filter := queryFilter{
+		Expression: "LastChecksumSuccess = :success AND attribute_not_exists(FailureKey)",
+		Values: map[string]types.AttributeValue{
+			":success": &types.AttributeValueMemberBOOL{Value: false},
+		},
+	}
+
+	var err error
+	result.Pass, err = d.queryBucket(d.checksumTable, bucket, startKey, filter, func(record ChecksumRecord) error {
+		indexed, err := d.indexFailure(record)
+		if indexed {
+			result.Indexed++
+		}
+		return err
+	})
+	return result, err
+}
+
+// indexFailure sets the failure key of a record, a record that has been checked again since
+// it was read is left as it is
+func (d *DB) indexFailure(record ChecksumRecord) (bool, error) {
+	versionKey := VersionKey(record.Object())
+	_, err := d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(d.checksumTable),
+		Key:                 key(record.Object()),
+		UpdateExpression:    aws.String("SET FailureKey = :key"),
+		ConditionExpression: aws.String("LastChecksumSuccess = :success AND LastChecksumDate = :last"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":key":     &types.AttributeValueMemberS{Value: versionKey},
+			":success": &types.AttributeValueMemberBOOL{Value: false},
+			":last":    &types.AttributeValueMemberS{Value: record.LastChecksumDate.Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// EventDateFormat is RFC3339 with fixed width nanoseconds so event dates sort lexically
+const EventDateFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// FixityEventType identifies what happened to an object version
+type FixityEventType string
+
+const (
+	FixityEventDeletion     FixityEventType = "deletion"
+	FixityEventDeposit      FixityEventType = "deposit"
+	FixityEventVerification FixityEventType = "verification"
+)
+
+// FixityEvent is an immutable entry in the fixity history of an object version. A failed
+// deposit or verification is recorded with Success false, the reason in Message and its
+// FailureCategory, Checksums maps each algorithm to the value calculated by that event (if any).
+type FixityEvent struct {
+	BucketName      string            `dynamodbav:"BucketName"`
+	ObjectKey       string            `dynamodbav:"ObjectKey"`
+	VersionId       string            `dynamodbav:"VersionId"`
+	EventDate       time.Time         `dynamodbav:"EventDate"`
+	EventType       FixityEventType   `dynamodbav:"EventType"`
+	Checksums       map[string]string `dynamodbav:"Checksums"`
+	Message         string            `dynamodbav:"Message"`
+	Success         bool              `dynamodbav:"Success"`
+	FailureCategory string            `dynamodbav:"FailureCategory"`
+}
+
+// Object returns the object version the event belongs to
+func (e FixityEvent) Object() files.S3Object {
+	return files.NewS3ObjectVersion(e.BucketName, e.ObjectKey, e.VersionId)
+}
+
+// ObjectId returns the partition key of the history table, every event of an
+// object version shares it
+func ObjectId(obj files.S3Object) string {
+	return obj.Bucket + "/" + VersionKey(obj)
+}
+
+// WithHistory appends fixity events to the history table, without it AppendEvent is a no-op
+func (d *DB) WithHistory(historyTable string) *DB {
+	d.historyTable = historyTable
+	return d
+}
+
+// AppendEvent records an event in the history table, an existing event is never overwritten
+func (d *DB) AppendEvent(event FixityEvent) error {
+	if d.historyTable == "" {
+		return nil
+	}
+
+	if event.EventDate.IsZero() {
+		event.EventDate = time.Now()
+	}
+
+	item := map[string]types.AttributeValue{
+		"ObjectId":   &types.AttributeValueMemberS{Value: ObjectId(event.Object())},
+		"EventDate":  &types.AttributeValueMemberS{Value: event.EventDate.UTC().Format(EventDateFormat)},
+		"BucketName": &types.AttributeValueMemberS{Value: event.BucketName},
+		"ObjectKey":  &types.AttributeValueMemberS{Value: event.ObjectKey},
+		"EventType":  &types.AttributeValueMemberS{Value: string(event.EventType)},
+		"Message":    &types.AttributeValueMemberS{Value: event.Message},
+		"Success":    &types.AttributeValueMemberBOOL{Value: event.Success},
+	}
+
+	if len(event.Checksums) > 0 {
+		checksums := make(map[string]types.AttributeValue, len(event.Checksums))
+		for algorithm, value := range event.Checksums {
+			checksums[algorithm] = &types.AttributeValueMemberS{Value: value}
+		}
+		item["Checksums"] = &types.AttributeValueMemberM{Value: checksums}
+	}
+
+	if !event.Success && event.FailureCategory != "" {
+		item["FailureCategory"] = &types.AttributeValueMemberS{Value: event.FailureCategory}
+	}
+
+	if event.VersionId != "" {
+		item["VersionId"] = &types.AttributeValueMemberS{Value: event.VersionId}
+	}
+
+	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(d.historyTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ObjectId)"),
+	})
+	if err != nil {
+		return ErrorAppendingEvent(ObjectId(event.Object()), err)
+	}
+
+	return nil
+}
+
+// History returns every event of an object version, oldest first
+func (d *DB) History(obj files.S3Object) ([]FixityEvent, error) {
+	return d.queryHistory(obj, "ObjectId = :id", map[string]types.AttributeValue{
+		":id": &types.AttributeValueMemberS{Value: ObjectId(obj)},
+	})
+}
+
+// HistoryBetween returns the events of an object version from (inclusive) to (exclusive),
+// oldest first. For example the last verification before a date is the last verification
+// event of HistoryBetween(obj, time.Time{}, date).
+func (d *DB) HistoryBetween(obj files.S3Object, from, to time.Time) ([]FixityEvent, error) {
+	return d.queryHistory(obj, "ObjectId = :id AND EventDate BETWEEN :from AND :to", map[string]types.AttributeValue{
+		":id":   &types.AttributeValueMemberS{Value: ObjectId(obj)},
+		":from": &types.AttributeValueMemberS{Value: from.UTC().Format(EventDateFormat)},
+		":to":   &types.AttributeValueMemberS{Value: to.Add(-time.Nanosecond).UTC().Format(EventDateFormat)},
+	})
+}
+
+func (d *DB) queryHistory(
+	obj files.S3Object,
+	condition string,
+	values map[string]types.AttributeValue,
+) ([]FixityEvent, error) {
+	if d.historyTable == "" {
+		return nil, ErrorHistoryNotConfigured(ObjectId(obj))
+	}
+
+	paginator := dynamodb.NewQueryPaginator(d.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(d.historyTable),
+		KeyConditionExpression:    aws.String(condition),
+		ExpressionAttributeValues: values,
+		ConsistentRead:            aws.Bool(true),
+		ScanIndexForward:          aws.Bool(true),
+	})
+
+	var history []FixityEvent
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(d.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var events []FixityEvent
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &events); err != nil {
+			return nil, ErrorUnmarshallingEvent(err)
+		}
+		history = append(history, events...)
+	}
+
+	return history, nil
+}
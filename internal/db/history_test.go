@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"duracloud/internal/files"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeHistoryTable keeps the items of a history table and answers the key conditions of
+// queryHistory the way DynamoDB does, comparing EventDate as a string, a page at a time
+type fakeHistoryTable struct {
+	DynamoDBClientInterface
+	items    []map[string]types.AttributeValue
+	pageSize int
+	queries  []*dynamodb.QueryInput
+}
+
+func attributeString(item map[string]types.AttributeValue, name string) string {
+	if value, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+func (f *fakeHistoryTable) PutItem(ctx context.Context, input *dynamodb.PutItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	for _, item := range f.items {
+		if attributeString(item, "ObjectId") == attributeString(input.Item, "ObjectId") &&
+			attributeString(item, "EventDate") == attributeString(input.Item, "EventDate") {
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("exists")}
+		}
+	}
+	f.items = append(f.items, input.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeHistoryTable) Query(ctx context.Context, input *dynamodb.QueryInput, opts ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.queries = append(f.queries, input)
+	id := attributeString(input.ExpressionAttributeValues, ":id")
+	from := attributeString(input.ExpressionAttributeValues, ":from")
+	to, bounded := input.ExpressionAttributeValues[":to"].(*types.AttributeValueMemberS)
+
+	var matched []map[string]types.AttributeValue
+	for _, item := range f.items {
+		date := attributeString(item, "EventDate")
+		if attributeString(item, "ObjectId") != id || date < from || (bounded && date > to.Value) {
+			continue
+		}
+		matched = append(matched, item)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		less := attributeString(matched[i], "EventDate") < attributeString(matched[j], "EventDate")
+		return less == aws.ToBool(input.ScanIndexForward)
+	})
+
+	start := 0
+	if input.ExclusiveStartKey != nil {
+		for start < len(matched) && attributeString(matched[start], "EventDate") != attributeString(input.ExclusiveStartKey, "EventDate") {
+			start++
+		}
+		start++
+	}
+	end := min(start+f.pageSize, len(matched))
+
+	output := &dynamodb.QueryOutput{Items: matched[start:end]}
+	if end < len(matched) {
+		output.LastEvaluatedKey = map[string]types.AttributeValue{
+			"ObjectId":  matched[end-1]["ObjectId"],
+			"EventDate": matched[end-1]["EventDate"],
+		}
+	}
+	return output, nil
+}
+
+func newHistoryDB(table *fakeHistoryTable) *DB {
+	return NewDB(context.Background(), table, "", "").WithHistory("history")
+}
+
+func eventDates(events []FixityEvent) []time.Time {
+	dates := make([]time.Time, len(events))
+	for i, event := range events {
+		dates[i] = event.EventDate
+	}
+	return dates
+}
+
+func TestEventDateFormatSortsLexically(t *testing.T) {
+	// RFC3339Nano drops trailing zeros, which puts 12:00:00.5 before 12:00:00
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	dates := []time.Time{
+		base,
+		base.Add(time.Nanosecond),
+		base.Add(500 * time.Millisecond),
+		base.Add(time.Second),
+		base.Add(10 * time.Hour),
+	}
+
+	for i := 1; i < len(dates); i++ {
+		before := dates[i-1].Format(EventDateFormat)
+		after := dates[i].Format(EventDateFormat)
+		if before >= after {
+			t.Errorf("Expected %s to sort before %s", before, after)
+		}
+	}
+}
+
+func TestAppendEvent(t *testing.T) {
+	table := &fakeHistoryTable{pageSize: 10}
+	ddb := newHistoryDB(table)
+	obj := files.NewS3ObjectVersion("bucket", "a/file.txt", "v1")
+	date := time.Date(2025, 6, 1, 8, 0, 0, 0, time.FixedZone("EDT", -4*60*60))
+
+	event := FixityEvent{
+		BucketName:      obj.Bucket,
+		ObjectKey:       obj.Key,
+		VersionId:       obj.VersionId,
+		EventDate:       date,
+		EventType:       FixityEventVerification,
+		Checksums:       map[string]string{"md5": "abc123"},
+		Message:         "checksum mismatch",
+		FailureCategory: FailureCorrupted,
+	}
+	if err := ddb.AppendEvent(event); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	item := table.items[0]
+	if id := attributeString(item, "ObjectId"); id != "bucket/a/file.txt#v1" {
+		t.Errorf("Unexpected ObjectId: %s", id)
+	}
+	if eventDate := attributeString(item, "EventDate"); eventDate != "2025-06-01T12:00:00.000000000Z" {
+		t.Errorf("Expected the event date in UTC, got %s", eventDate)
+	}
+	if category := attributeString(item, "FailureCategory"); category != FailureCorrupted {
+		t.Errorf("Unexpected failure category: %s", category)
+	}
+
+	if err := ddb.AppendEvent(event); !errors.Is(err, ErrAppendingEvent) {
+		t.Errorf("Expected an existing event to be kept, got %v", err)
+	}
+
+	if err := NewDB(context.Background(), table, "", "").AppendEvent(event); err != nil {
+		t.Errorf("Expected a no-op without a history table, got %v", err)
+	}
+	if len(table.items) != 1 {
+		t.Errorf("Expected 1 event, got %d", len(table.items))
+	}
+}
+
+func TestHistory(t *testing.T) {
+	table := &fakeHistoryTable{pageSize: 2}
+	ddb := newHistoryDB(table)
+	obj := files.NewS3ObjectVersion("bucket", "a/file.txt", "v1")
+	other := files.NewS3ObjectVersion("bucket", "a/file.txt", "v2")
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Appended out of order, across pages and with dates RFC3339Nano would misorder
+	offsets := []time.Duration{time.Second, 0, 500 * time.Millisecond, time.Hour, time.Nanosecond}
+	for _, offset := range offsets {
+		for _, o := range []files.S3Object{obj, other} {
+			err := ddb.AppendEvent(FixityEvent{
+				BucketName: o.Bucket,
+				ObjectKey:  o.Key,
+				VersionId:  o.VersionId,
+				EventDate:  base.Add(offset),
+				EventType:  FixityEventVerification,
+				Success:    true,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+
+	history, err := ddb.History(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []time.Time{
+		base,
+		base.Add(time.Nanosecond),
+		base.Add(500 * time.Millisecond),
+		base.Add(time.Second),
+		base.Add(time.Hour),
+	}
+	dates := eventDates(history)
+	if len(dates) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(dates))
+	}
+	for i := range expected {
+		if !dates[i].Equal(expected[i]) {
+			t.Errorf("Expected event %d at %v, got %v", i, expected[i], dates[i])
+		}
+		if history[i].Object() != obj {
+			t.Errorf("Unexpected object of event %d: %v", i, history[i].Object())
+		}
+	}
+
+	query := table.queries[0]
+	if !aws.ToBool(query.ConsistentRead) || !aws.ToBool(query.ScanIndexForward) {
+		t.Errorf("Expected a consistent query oldest first, got %v", query)
+	}
+	if len(table.queries) != 3 {
+		t.Errorf("Expected 3 pages, got %d", len(table.queries))
+	}
+}
+
+func TestHistoryBetween(t *testing.T) {
+	table := &fakeHistoryTable{pageSize: 10}
+	ddb := newHistoryDB(table)
+	obj := files.NewS3Object("bucket", "a/file.txt")
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for day := range 5 {
+		err := ddb.AppendEvent(FixityEvent{
+			BucketName: obj.Bucket,
+			ObjectKey:  obj.Key,
+			EventDate:  base.AddDate(0, 0, day),
+			EventType:  FixityEventVerification,
+			Success:    true,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		expected []time.Time
+	}{
+		{
+			name:     "from inclusive to exclusive",
+			from:     base.AddDate(0, 0, 1),
+			to:       base.AddDate(0, 0, 3),
+			expected: []time.Time{base.AddDate(0, 0, 1), base.AddDate(0, 0, 2)},
+		},
+		{
+			name:     "to a nanosecond after",
+			from:     base.AddDate(0, 0, 3),
+			to:       base.AddDate(0, 0, 3).Add(time.Nanosecond),
+			expected: []time.Time{base.AddDate(0, 0, 3)},
+		},
+		{
+			name:     "from the start",
+			to:       base.AddDate(0, 0, 1),
+			expected: []time.Time{base},
+		},
+		{
+			name:     "other time zone",
+			from:     base.AddDate(0, 0, 4).In(time.FixedZone("EDT", -4*60*60)),
+			to:       base.AddDate(0, 0, 5).In(time.FixedZone("JST", 9*60*60)),
+			expected: []time.Time{base.AddDate(0, 0, 4)},
+		},
+		{
+			name: "empty",
+			from: base.AddDate(0, 0, 2).Add(time.Nanosecond),
+			to:   base.AddDate(0, 0, 3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := ddb.HistoryBetween(obj, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			dates := eventDates(history)
+			if len(dates) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, dates)
+			}
+			for i := range tt.expected {
+				if !dates[i].Equal(tt.expected[i]) {
+					t.Errorf("Expected %v, got %v", tt.expected, dates)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryNotConfigured(t *testing.T) {
+	ddb := NewDB(context.Background(), &fakeHistoryTable{}, "", "")
+	obj := files.NewS3Object("bucket", "a/file.txt")
+
+	if _, err := ddb.History(obj); !errors.Is(err, ErrHistoryNotConfigured) {
+		t.Errorf("Expected history not configured, got %v", err)
+	}
+	if _, err := ddb.HistoryBetween(obj, time.Time{}, time.Now()); !errors.Is(err, ErrHistoryNotConfigured) {
+		t.Errorf("Expected history not configured, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Legacy statuses of a record deposited with checksums imported from a legacy repository
+const (
+	LegacyIntact   = "intact"   // the deposited version matches every imported checksum
+	LegacyMismatch = "mismatch" // the deposited version does not match an imported checksum
+	LegacyPending  = "pending"  // compared when the verification of a handed off deposit completes
+)
+
+// LegacyRecord holds the checksums imported from a legacy repository for an object, keyed by
+// algorithm, until it is deposited. VersionId is the version that claimed them at deposit, only
+// that version is compared with them.
+type LegacyRecord struct {
+	BucketName string            `dynamodbav:"BucketName"`
+	ObjectKey  string            `dynamodbav:"ObjectKey"`
+	Checksums  map[string]string `dynamodbav:"Checksums"`
+	ImportDate time.Time         `dynamodbav:"ImportDate"`
+	Source     string            `dynamodbav:"Source"`
+	VersionId  string            `dynamodbav:"VersionId"`
+}
+
+// WithLegacy stages the checksums imported from a legacy repository in the legacy table
+func (d *DB) WithLegacy(legacyTable string) *DB {
+	d.legacyTable = legacyTable
+	return d
+}
+
+// ClaimLegacy returns the imported checksums of an object for the deposit of a version, once a
+// version has claimed them the deposits of other versions get ErrLegacyChecksumNotFound
+func (d *DB) ClaimLegacy(obj files.S3Object) (LegacyRecord, error) {
+	if d.legacyTable == "" {
+		return LegacyRecord{}, ErrorLegacyNotConfigured(ObjectId(obj))
+	}
+
+	versionId := obj.VersionId
+	if versionId == "" {
+		versionId = files.NullVersionId
+	}
+
+	result, err := d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(d.legacyTable),
+		Key:                 legacyKey(obj.Bucket, obj.Key),
+		UpdateExpression:    aws.String("SET VersionId = :version"),
+		ConditionExpression: aws.String("attribute_exists(Checksums) AND (attribute_not_exists(VersionId) OR VersionId = :version)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberS{Value: versionId},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return LegacyRecord{}, ErrorLegacyChecksumNotFound(obj.Bucket, obj.Key)
+		}
+		return LegacyRecord{}, err
+	}
+
+	legacyRecord := LegacyRecord{}
+	if err := attributevalue.UnmarshalMap(result.Attributes, &legacyRecord); err != nil {
+		return LegacyRecord{}, ErrorUnmarshallingLegacy(err)
+	}
+
+	return legacyRecord, nil
+}
+
+// StageLegacy adds an imported checksum to the legacy record of an object, checksums of other
+// algorithms imported before are kept. An object that was already deposited is not compared
+// with it and ErrLegacyChecksumClaimed is returned.
+func (d *DB) StageLegacy(bucket, objectKey, algorithm, checksum, source string) error {
+	if d.legacyTable == "" {
+		return ErrorLegacyNotConfigured(bucket + "/" + objectKey)
+	}
+
+	date := &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)}
+	value := &types.AttributeValueMemberS{Value: checksum}
+
+	// The checksums map is created by the first checksum of an object then added to
+	_, err := d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(d.legacyTable),
+		Key:                      legacyKey(bucket, objectKey),
+		UpdateExpression:         aws.String("SET Checksums = :checksums, ImportDate = :date, #source = :source"),
+		ConditionExpression:      aws.String("attribute_not_exists(Checksums)"),
+		ExpressionAttributeNames: map[string]string{"#source": "Source"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":checksums": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{algorithm: value}},
+			":date":      date,
+			":source":    &types.AttributeValueMemberS{Value: source},
+		},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionFailed) {
+		return err
+	}
+
+	_, err = d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(d.legacyTable),
+		Key:                      legacyKey(bucket, objectKey),
+		UpdateExpression:         aws.String("SET Checksums.#algorithm = :checksum, ImportDate = :date, #source = :source"),
+		ConditionExpression:      aws.String("attribute_not_exists(VersionId)"),
+		ExpressionAttributeNames: map[string]string{"#algorithm": algorithm, "#source": "Source"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":checksum": value,
+			":date":     date,
+			":source":   &types.AttributeValueMemberS{Value: source},
+		},
+	})
+	if errors.As(err, &conditionFailed) {
+		return ErrorLegacyChecksumClaimed(bucket, objectKey)
+	}
+	return err
+}
+
+func legacyKey(bucket, objectKey string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"BucketName": &types.AttributeValueMemberS{Value: bucket},
+		"ObjectKey":  &types.AttributeValueMemberS{Value: objectKey},
+	}
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a ChecksumStore (with fixity history) held in memory, for tests and for running
+// the pipeline without DynamoDB. It is safe for concurrent use and records are copied in and out.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	checksums map[string]ChecksumRecord
+	history   map[string][]FixityEvent
+	legacy    map[string]LegacyRecord
+	scheduled map[string]ChecksumRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		checksums: make(map[string]ChecksumRecord),
+		history:   make(map[string][]FixityEvent),
+		legacy:    make(map[string]LegacyRecord),
+		scheduled: make(map[string]ChecksumRecord),
+	}
+}
+
+func (m *MemoryStore) Delete(obj files.S3Object, sequencer string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if record, ok := m.checksums[ObjectId(obj)]; ok && !sequencerApplies(record.Sequencer, sequencer, false) {
+		return ErrorStaleSequencer(obj.Bucket, obj.Key, NormalizeSequencer(sequencer))
+	}
+
+	delete(m.checksums, ObjectId(obj))
+	delete(m.scheduled, ObjectId(obj))
+	return nil
+}
+
+func (m *MemoryStore) Get(obj files.S3Object) (ChecksumRecord, error) {
+	return m.get(m.checksums, obj)
+}
+
+func (m *MemoryStore) get(records map[string]ChecksumRecord, obj files.S3Object) (ChecksumRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := records[ObjectId(obj)]
+	if !ok {
+		return ChecksumRecord{}, ErrorChecksumRecordNotFound(obj.Bucket, obj.Key)
+	}
+	return copyRecord(record), nil
+}
+
+// Next returns the scheduled verification of an object version, as the scheduler table it only
+// holds the object version and NextChecksumDate
+func (m *MemoryStore) Next(obj files.S3Object) (ChecksumRecord, error) {
+	return m.get(m.scheduled, obj)
+}
+
+func (m *MemoryStore) Put(record ChecksumRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.checksums[ObjectId(record.Object())]
+	if ok && !sequencerApplies(stored.Sequencer, record.Sequencer, true) {
+		return ErrorStaleSequencer(record.BucketName, record.ObjectKey, NormalizeSequencer(record.Sequencer))
+	}
+
+	record.Sequencer = NormalizeSequencer(record.Sequencer)
+
+	m.checksums[ObjectId(record.Object())] = copyRecord(record)
+	return nil
+}
+
+func (m *MemoryStore) Reschedule(record ChecksumRecord, next time.Time) (bool, error) {
+	m.mu.Lock()
+	stored, ok := m.checksums[ObjectId(record.Object())]
+	if !ok || !stored.LastChecksumDate.Equal(record.LastChecksumDate) {
+		m.mu.Unlock()
+		return false, nil
+	}
+	stored.NextChecksumDate = next
+	m.checksums[ObjectId(record.Object())] = stored
+	m.mu.Unlock()
+
+	return true, m.Schedule(stored)
+}
+
+func (m *MemoryStore) Schedule(record ChecksumRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.scheduled[ObjectId(record.Object())] = ChecksumRecord{
+		BucketName:       record.BucketName,
+		ObjectKey:        record.ObjectKey,
+		NextChecksumDate: record.NextChecksumDate,
+		VersionId:        record.VersionId,
+	}
+	return nil
+}
+
+// Due returns the scheduled verifications that are due at t, earliest first
+func (m *MemoryStore) Due(t time.Time) []ChecksumRecord {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var due []ChecksumRecord
+	for _, record := range m.scheduled {
+		if !record.NextChecksumDate.After(t) {
+			due = append(due, record)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextChecksumDate.Equal(due[j].NextChecksumDate) {
+			return ObjectId(due[i].Object()) < ObjectId(due[j].Object())
+		}
+		return due[i].NextChecksumDate.Before(due[j].NextChecksumDate)
+	})
+
+	return due
+}
+
+// Records calls fn with every record of a bucket in key order, it stops at the first error
+func (m *MemoryStore) Records(bucket string, fn func(ChecksumRecord) error) error {
+	m.mu.RLock()
+	var records []ChecksumRecord
+	for _, record := range m.checksums {
+		if record.BucketName == bucket {
+			records = append(records, copyRecord(record))
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return VersionKey(records[i].Object()) < VersionKey(records[j].Object())
+	})
+
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListFailures returns a page of the records of a bucket whose last check failed, ordered by key
+func (m *MemoryStore) ListFailures(bucket string, page Page) (FailurePage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+
+	var failures []ChecksumRecord
+	for _, record := range m.checksums {
+		if record.BucketName == bucket && !record.LastChecksumSuccess && VersionKey(record.Object()) > page.StartKey {
+			failures = append(failures, copyRecord(record))
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return VersionKey(failures[i].Object()) < VersionKey(failures[j].Object())
+	})
+
+	var result FailurePage
+	if len(failures) > limit {
+		failures = failures[:limit]
+		result.NextKey = VersionKey(failures[limit-1].Object())
+	}
+	result.Records = failures
+
+	return result, nil
+}
+
+// AppendEvent records an event in the fixity history of its object version
+func (m *MemoryStore) AppendEvent(event FixityEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if event.EventDate.IsZero() {
+		event.EventDate = time.Now()
+	}
+	event.Checksums = maps.Clone(event.Checksums)
+
+	id := ObjectId(event.Object())
+	m.history[id] = append(m.history[id], event)
+	return nil
+}
+
+// History returns every event of an object version, oldest first
+func (m *MemoryStore) History(obj files.S3Object) ([]FixityEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := make([]FixityEvent, len(m.history[ObjectId(obj)]))
+	copy(events, m.history[ObjectId(obj)])
+	for i := range events {
+		events[i].Checksums = maps.Clone(events[i].Checksums)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].EventDate.Before(events[j].EventDate)
+	})
+
+	return events, nil
+}
+
+// ClaimLegacy returns the imported checksums of an object for the deposit of a version, once a
+// version has claimed them the deposits of other versions get ErrLegacyChecksumNotFound
+func (m *MemoryStore) ClaimLegacy(obj files.S3Object) (LegacyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	versionId := obj.VersionId
+	if versionId == "" {
+		versionId = files.NullVersionId
+	}
+
+	legacy, ok := m.legacy[obj.Bucket+"/"+obj.Key]
+	if !ok || (legacy.VersionId != "" && legacy.VersionId != versionId) {
+		return LegacyRecord{}, ErrorLegacyChecksumNotFound(obj.Bucket, obj.Key)
+	}
+
+	legacy.VersionId = versionId
+	m.legacy[obj.Bucket+"/"+obj.Key] = legacy
+
+	legacy.Checksums = maps.Clone(legacy.Checksums)
+	return legacy, nil
+}
+
+// StageLegacy adds an imported checksum to the legacy record of an object, an object that was
+// already deposited gets ErrLegacyChecksumClaimed
+func (m *MemoryStore) StageLegacy(bucket, objectKey, algorithm, checksum, source string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	legacy, ok := m.legacy[bucket+"/"+objectKey]
+	if !ok {
+		legacy = LegacyRecord{BucketName: bucket, ObjectKey: objectKey, Checksums: make(map[string]string)}
+	}
+	if legacy.VersionId != "" {
+		return ErrorLegacyChecksumClaimed(bucket, objectKey)
+	}
+
+	legacy.Checksums[algorithm] = checksum
+	legacy.ImportDate = time.Now()
+	legacy.Source = source
+	m.legacy[bucket+"/"+objectKey] = legacy
+	return nil
+}
+
+// copyRecord copies the maps of a record so the store does not share them with callers
+func copyRecord(record ChecksumRecord) ChecksumRecord {
+	record.Checksums = maps.Clone(record.Checksums)
+	record.LegacyChecksums = maps.Clone(record.LegacyChecksums)
+	record.SuppliedChecksums = maps.Clone(record.SuppliedChecksums)
+	return record
+}
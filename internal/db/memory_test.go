@@ -0,0 +1,286 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	_ ChecksumStore = (*DB)(nil)
+	_ ChecksumStore = (*MemoryStore)(nil)
+	_ EventAppender = (*MemoryStore)(nil)
+	_ LegacyStager  = (*DB)(nil)
+	_ LegacyStager  = (*MemoryStore)(nil)
+	_ RecordLister  = (*DB)(nil)
+	_ RecordLister  = (*MemoryStore)(nil)
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	obj := files.NewS3ObjectVersion("bucket", "a/file.txt", "v1")
+	next := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := store.Get(obj); !errors.Is(err, ErrChecksumRecordNotFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+
+	record := ChecksumRecord{
+		BucketName:       obj.Bucket,
+		ObjectKey:        obj.Key,
+		Checksum:         "abc123",
+		Checksums:        map[string]string{"md5": "abc123"},
+		NextChecksumDate: next,
+		VersionId:        obj.VersionId,
+	}
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Schedule(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Changes made by the caller are not shared with the store
+	record.Checksums["md5"] = "changed"
+	got, err := store.Get(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Checksum != "abc123" || got.Checksums["md5"] != "abc123" {
+		t.Errorf("Unexpected record: %+v", got)
+	}
+
+	if _, err := store.Get(files.NewS3ObjectVersion("bucket", "a/file.txt", "v2")); err == nil {
+		t.Error("Expected another version to have no record")
+	}
+
+	scheduled, err := store.Next(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !scheduled.NextChecksumDate.Equal(next) || scheduled.Checksum != "" || scheduled.VersionId != "v1" {
+		t.Errorf("Unexpected scheduled record: %+v", scheduled)
+	}
+
+	if err := store.Delete(obj, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := store.Get(obj); err == nil {
+		t.Error("Expected the record to be deleted")
+	}
+	if _, err := store.Next(obj); err == nil {
+		t.Error("Expected the schedule to be deleted")
+	}
+}
+
+func TestMemoryStoreDue(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, offset := range []time.Duration{time.Hour, -time.Hour, -2 * time.Hour, 0} {
+		record := ChecksumRecord{
+			BucketName:       "bucket",
+			ObjectKey:        fmt.Sprintf("file-%d", i),
+			NextChecksumDate: now.Add(offset),
+		}
+		if err := store.Schedule(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	due := store.Due(now)
+	if len(due) != 3 {
+		t.Fatalf("Expected 3 due, got %d", len(due))
+	}
+	for i, expected := range []string{"file-2", "file-1", "file-3"} {
+		if due[i].ObjectKey != expected {
+			t.Errorf("Due[%d] = %s, expected %s", i, due[i].ObjectKey, expected)
+		}
+	}
+}
+
+func TestMemoryStoreReschedule(t *testing.T) {
+	store := NewMemoryStore()
+	last := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	next := last.AddDate(0, 0, 7)
+	record := ChecksumRecord{
+		BucketName:       "bucket",
+		ObjectKey:        "file.txt",
+		Checksum:         "abc123",
+		LastChecksumDate: last,
+		NextChecksumDate: last.AddDate(1, 0, 0),
+	}
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	moved, err := store.Reschedule(record, next)
+	if err != nil || !moved {
+		t.Fatalf("Expected the record to move, got %v (%v)", moved, err)
+	}
+	stored, _ := store.Get(record.Object())
+	scheduled, _ := store.Next(record.Object())
+	if !stored.NextChecksumDate.Equal(next) || !scheduled.NextChecksumDate.Equal(next) || stored.Checksum != "abc123" {
+		t.Errorf("Unexpected record %+v and schedule %+v", stored, scheduled)
+	}
+
+	// Checked since it was read
+	record.LastChecksumDate = last.Add(-time.Hour)
+	if moved, err := store.Reschedule(record, last); err != nil || moved {
+		t.Errorf("Expected a record checked since it was read to be left, got %v (%v)", moved, err)
+	}
+}
+
+func TestMemoryStoreHistory(t *testing.T) {
+	store := NewMemoryStore()
+	obj := files.NewS3Object("bucket", "file.txt")
+	first := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	events := []FixityEvent{
+		{BucketName: obj.Bucket, ObjectKey: obj.Key, EventDate: first.Add(time.Hour), EventType: FixityEventVerification},
+		{BucketName: obj.Bucket, ObjectKey: obj.Key, EventDate: first, EventType: FixityEventDeposit},
+	}
+	for _, event := range events {
+		if err := RecordEvent(store, event); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	history, err := store.History(obj)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(history) != 2 || history[0].EventType != FixityEventDeposit || history[1].EventType != FixityEventVerification {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestMemoryStoreConcurrent(t *testing.T) {
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record := ChecksumRecord{BucketName: "bucket", ObjectKey: fmt.Sprintf("file-%d", i)}
+			for range 50 {
+				_ = store.Put(record)
+				_ = store.Schedule(record)
+				_, _ = store.Get(record.Object())
+				_ = store.Due(time.Now())
+			}
+		}()
+	}
+	wg.Wait()
+
+	if due := store.Due(time.Now()); len(due) != 20 {
+		t.Errorf("Expected 20 scheduled, got %d", len(due))
+	}
+}
+
+func TestMemoryStoreSequencer(t *testing.T) {
+	store := NewMemoryStore()
+	obj := files.NewS3Object("bucket", "file.txt")
+	record := ChecksumRecord{BucketName: obj.Bucket, ObjectKey: obj.Key, Checksum: "second", Sequencer: "0055AED6DCD90281E6"}
+
+	if err := store.Put(record); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A redelivery or verification writes with the same sequencer
+	if err := store.Put(record); err != nil {
+		t.Errorf("Expected the same sequencer to be written, got %v", err)
+	}
+
+	earlier := record
+	earlier.Checksum = "first"
+	earlier.Sequencer = "0055AED6DCD90281E5"
+	if err := store.Put(earlier); !errors.Is(err, ErrStaleSequencer) {
+		t.Errorf("Expected an earlier deposit to be stale, got %v", err)
+	}
+
+	if err := store.Delete(obj, "0055AED6DCD90281E5"); !errors.Is(err, ErrStaleSequencer) {
+		t.Errorf("Expected an earlier delete to be stale, got %v", err)
+	}
+	if got, err := store.Get(obj); err != nil || got.Checksum != "second" {
+		t.Errorf("Expected the later deposit to be kept, got %+v (%v)", got, err)
+	}
+
+	if err := store.Delete(obj, "0055AED6DCD90281E7"); err != nil {
+		t.Errorf("Expected a later delete to apply, got %v", err)
+	}
+	if _, err := store.Get(obj); err == nil {
+		t.Error("Expected the record to be deleted")
+	}
+}
+
+func TestMemoryStoreListFailures(t *testing.T) {
+	store := NewMemoryStore()
+
+	for i := range 5 {
+		record := ChecksumRecord{
+			BucketName:          "bucket",
+			ObjectKey:           fmt.Sprintf("file-%d", i),
+			LastChecksumSuccess: i%2 == 0,
+		}
+		if err := store.Put(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := store.Put(ChecksumRecord{BucketName: "other", ObjectKey: "file-1"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page, err := store.ListFailures("bucket", Page{Limit: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Records) != 1 || page.Records[0].ObjectKey != "file-1" || page.NextKey != "file-1#null" {
+		t.Fatalf("Unexpected first page: %+v", page)
+	}
+
+	page, err = store.ListFailures("bucket", Page{Limit: 1, StartKey: page.NextKey})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page.Records) != 1 || page.Records[0].ObjectKey != "file-3" || page.NextKey != "" {
+		t.Errorf("Unexpected last page: %+v", page)
+	}
+}
+
+func TestMemoryStoreLegacy(t *testing.T) {
+	store := NewMemoryStore()
+
+	for algorithm, checksum := range map[string]string{"md5": "abc", "sha256": "def"} {
+		if err := store.StageLegacy("bucket", "file.txt", algorithm, checksum, "import.csv"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	legacy, err := store.ClaimLegacy(files.NewS3ObjectVersion("bucket", "file.txt", "v1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(legacy.Checksums) != 2 || legacy.Checksums["md5"] != "abc" || legacy.VersionId != "v1" {
+		t.Errorf("Unexpected legacy record: %+v", legacy)
+	}
+
+	// The version that claimed the checksums can claim them again, other versions cannot
+	if _, err := store.ClaimLegacy(files.NewS3ObjectVersion("bucket", "file.txt", "v1")); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if _, err := store.ClaimLegacy(files.NewS3ObjectVersion("bucket", "file.txt", "v2")); !errors.Is(err, ErrLegacyChecksumNotFound) {
+		t.Errorf("Expected ErrLegacyChecksumNotFound, got %v", err)
+	}
+	if _, err := store.ClaimLegacy(files.NewS3Object("bucket", "other.txt")); !errors.Is(err, ErrLegacyChecksumNotFound) {
+		t.Errorf("Expected ErrLegacyChecksumNotFound, got %v", err)
+	}
+
+	if err := store.StageLegacy("bucket", "file.txt", "sha512", "ghi", "import.csv"); !errors.Is(err, ErrLegacyChecksumClaimed) {
+		t.Errorf("Expected ErrLegacyChecksumClaimed, got %v", err)
+	}
+}
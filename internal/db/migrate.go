@@ -0,0 +1,185 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"errors"
+	"maps"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// migrateReserve is the time kept back from the context deadline to return a MigrateObjectKeyTables in progress
+const migrateReserve = 30 * time.Second
+
+// MigrateResult summarizes a MigrateObjectKeyTables, when it stopped before the end of the
+// table Complete is false and NextBucket and NextKey are the last record it reached
+type MigrateResult struct {
+	Complete   bool
+	NextBucket string
+	NextKey    string
+	Records    int // records read from the table keyed by ObjectKey
+	Copied     int // records copied to the checksum table
+	Dropped    int // records of objects without a current version, not copied
+	Existing   int // records already written to the checksum table since it was created, not copied
+	Scheduled  int // scheduler entries copied to the scheduler table
+}
+
+// CurrentVersion returns the version id of the current version of an object, false when the
+// object has no current version (it was deleted)
+type CurrentVersion func(obj files.S3Object) (string, bool, error)
+
+// MigrateObjectKeyTables copies the records of the checksum and scheduler tables keyed by
+// ObjectKey, written before records were version-aware, to the tables of the DB. Each record
+// described the object as it was last deposited, so it is keyed by the current version of its
+// object and keeps its scheduler entry. The record of an object without a current version is
+// dropped, a delete removed such records before they were version-aware. A record the DB
+// already has for the version is left as it is, so it can be run again. It stops before the
+// context deadline, call it again with NextBucket and NextKey to continue.
+func (d *DB) MigrateObjectKeyTables(checksumTable, schedulerTable, startBucket, startKey string, current CurrentVersion) (MigrateResult, error) {
+	result := MigrateResult{NextBucket: startBucket, NextKey: startKey}
+	deadline, hasDeadline := d.ctx.Deadline()
+
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(checksumTable),
+	}
+	if startBucket != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"BucketName": &types.AttributeValueMemberS{Value: startBucket},
+			"ObjectKey":  &types.AttributeValueMemberS{Value: startKey},
+		}
+	}
+
+	for {
+		page, err := d.client.Scan(d.ctx, input)
+		if err != nil {
+			return result, err
+		}
+
+		for _, item := range page.Items {
+			if hasDeadline && time.Until(deadline) < migrateReserve {
+				return result, nil
+			}
+
+			obj, ok := objectKeyItem(item)
+			if !ok {
+				return result, ErrorUnmarshallingChecksum(errors.New("record without BucketName or ObjectKey"))
+			}
+
+			versionId, exists, err := current(obj)
+			if err != nil {
+				return result, err
+			}
+
+			if !exists {
+				result.Dropped++
+			} else if err := d.migrateRecord(files.NewS3ObjectVersion(obj.Bucket, obj.Key, versionId), item,
+				checksumTable, schedulerTable, &result); err != nil {
+				return result, err
+			}
+			result.Records++
+			result.NextBucket = obj.Bucket
+			result.NextKey = obj.Key
+		}
+
+		if page.LastEvaluatedKey == nil {
+			result.Complete = true
+			result.NextBucket = ""
+			result.NextKey = ""
+			return result, nil
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+}
+
+// migrateRecord copies a record and its scheduler entry (if any)
+func (d *DB) migrateRecord(obj files.S3Object, item map[string]types.AttributeValue, checksumTable, schedulerTable string, result *MigrateResult) error {
+	copied, err := d.putIfAbsent(d.checksumTable, versionItem(obj, item, checksumTable))
+	if err != nil {
+		return err
+	}
+	if !copied {
+		result.Existing++
+		return nil
+	}
+	result.Copied++
+
+	entry, err := d.client.GetItem(d.ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(schedulerTable),
+		Key: map[string]types.AttributeValue{
+			"BucketName": &types.AttributeValueMemberS{Value: obj.Bucket},
+			"ObjectKey":  &types.AttributeValueMemberS{Value: obj.Key},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if entry.Item == nil {
+		// A failing record, or one left without an entry that the orphan sweep reschedules
+		return nil
+	}
+
+	scheduled, err := d.putIfAbsent(d.schedulerTable, versionItem(obj, entry.Item, ""))
+	if scheduled {
+		result.Scheduled++
+	}
+	return err
+}
+
+// putIfAbsent writes an item unless the table already has one with its key, it reports whether it did
+func (d *DB) putIfAbsent(table string, item map[string]types.AttributeValue) (bool, error) {
+	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(VersionKey)"),
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// objectKeyItem returns the object of an item keyed by ObjectKey
+func objectKeyItem(item map[string]types.AttributeValue) (files.S3Object, bool) {
+	bucket, ok := item["BucketName"].(*types.AttributeValueMemberS)
+	if !ok {
+		return files.S3Object{}, false
+	}
+
+	key, ok := item["ObjectKey"].(*types.AttributeValueMemberS)
+	if !ok {
+		return files.S3Object{}, false
+	}
+
+	return files.NewS3Object(bucket.Value, key.Value), true
+}
+
+// versionItem returns a copy of an item keyed by ObjectKey keyed by a version of its object
+// instead. A record that was failing joins the failures index, and a copied checksum
+// record is marked with the table it came from so its stream event is not taken for a new failure.
+func versionItem(obj files.S3Object, item map[string]types.AttributeValue, migratedFrom string) map[string]types.AttributeValue {
+	versionItem := maps.Clone(item)
+	versionKey := VersionKey(obj)
+	versionItem["VersionKey"] = &types.AttributeValueMemberS{Value: versionKey}
+	if obj.VersionId != "" {
+		versionItem[string(ChecksumTableVersionIdId)] = &types.AttributeValueMemberS{Value: obj.VersionId}
+	}
+
+	if migratedFrom == "" {
+		return versionItem
+	}
+	versionItem[string(ChecksumTableMigratedId)] = &types.AttributeValueMemberS{Value: migratedFrom}
+
+	if success, ok := item[string(ChecksumTableStatusId)].(*types.AttributeValueMemberBOOL); ok && !success.Value {
+		versionItem["FailureKey"] = &types.AttributeValueMemberS{Value: versionKey}
+	}
+
+	return versionItem
+}
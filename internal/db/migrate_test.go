@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"duracloud/internal/files"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestVersionItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"BucketName":          &types.AttributeValueMemberS{Value: "bucket"},
+		"ObjectKey":           &types.AttributeValueMemberS{Value: "dir/file.txt"},
+		"Checksum":            &types.AttributeValueMemberS{Value: "abc"},
+		"LastChecksumSuccess": &types.AttributeValueMemberBOOL{Value: false},
+	}
+
+	obj, ok := objectKeyItem(item)
+	if !ok || obj.Bucket != "bucket" || obj.Key != "dir/file.txt" || obj.VersionId != "" {
+		t.Fatalf("Unexpected object: %v (%t)", obj, ok)
+	}
+
+	record := versionItem(files.NewS3ObjectVersion(obj.Bucket, obj.Key, "v2"), item, "stack-checksum-table")
+	if key := record["VersionKey"].(*types.AttributeValueMemberS).Value; key != "dir/file.txt#v2" {
+		t.Errorf("Expected the version key, got %s", key)
+	}
+	if versionId := record["VersionId"].(*types.AttributeValueMemberS).Value; versionId != "v2" {
+		t.Errorf("Expected the version id, got %s", versionId)
+	}
+	if key, ok := record["FailureKey"].(*types.AttributeValueMemberS); !ok || key.Value != "dir/file.txt#v2" {
+		t.Errorf("Expected a failing record to join the failures index, got %v", record["FailureKey"])
+	}
+	if from := record[string(ChecksumTableMigratedId)].(*types.AttributeValueMemberS).Value; from != "stack-checksum-table" {
+		t.Errorf("Expected the record to be marked with its table, got %s", from)
+	}
+	if record["Checksum"].(*types.AttributeValueMemberS).Value != "abc" {
+		t.Error("Expected the attributes of the record to be copied")
+	}
+	if _, ok := item["VersionKey"]; ok {
+		t.Error("Expected the item keyed by ObjectKey to be left as it is")
+	}
+
+	entry := versionItem(obj, map[string]types.AttributeValue{
+		"BucketName": &types.AttributeValueMemberS{Value: "bucket"},
+		"ObjectKey":  &types.AttributeValueMemberS{Value: "dir/file.txt"},
+		"TTL":        &types.AttributeValueMemberN{Value: "1750000000"},
+	}, "")
+	if _, ok := entry[string(ChecksumTableMigratedId)]; ok {
+		t.Error("Expected a scheduler entry not to be marked")
+	}
+	if entry["VersionKey"].(*types.AttributeValueMemberS).Value != "dir/file.txt#null" {
+		t.Error("Expected the scheduler entry to be keyed by the null version")
+	}
+	if _, ok := entry["VersionId"]; ok {
+		t.Error("Expected the null version without a version id")
+	}
+
+	if _, ok := objectKeyItem(map[string]types.AttributeValue{"BucketName": &types.AttributeValueMemberS{Value: "bucket"}}); ok {
+		t.Error("Expected an item without ObjectKey to be rejected")
+	}
+}
+
+func TestIsMigration(t *testing.T) {
+	migrated := map[string]events.DynamoDBAttributeValue{
+		string(ChecksumTableMigratedId): events.NewStringAttribute("stack-checksum-table"),
+	}
+
+	if !IsMigration(events.DynamoDBEventRecord{EventName: "INSERT", Change: events.DynamoDBStreamRecord{NewImage: migrated}}) {
+		t.Error("Expected a copied record to be a migration")
+	}
+	if IsMigration(events.DynamoDBEventRecord{EventName: "MODIFY", Change: events.DynamoDBStreamRecord{NewImage: migrated}}) {
+		t.Error("Expected a change of a copied record not to be a migration")
+	}
+	if IsMigration(events.DynamoDBEventRecord{EventName: "INSERT", Change: events.DynamoDBStreamRecord{}}) {
+		t.Error("Expected a deposit not to be a migration")
+	}
+}
+
+// fakeMigrationTables keeps the items of the tables of a migration by table name, items are
+// keyed by ObjectKey in the tables migrated from and by VersionKey in the others
+type fakeMigrationTables struct {
+	DynamoDBClientInterface
+	tables map[string]map[string]map[string]types.AttributeValue
+}
+
+func (f *fakeMigrationTables) itemKey(table string, key map[string]types.AttributeValue) string {
+	if versionKey, ok := key["VersionKey"]; ok {
+		return attributeString(key, "BucketName") + "/" + versionKey.(*types.AttributeValueMemberS).Value
+	}
+	return attributeString(key, "BucketName") + "/" + attributeString(key, "ObjectKey")
+}
+
+func (f *fakeMigrationTables) Scan(ctx context.Context, input *dynamodb.ScanInput, opts ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	output := &dynamodb.ScanOutput{}
+	for _, item := range f.tables[aws.ToString(input.TableName)] {
+		output.Items = append(output.Items, item)
+	}
+	return output, nil
+}
+
+func (f *fakeMigrationTables) GetItem(ctx context.Context, input *dynamodb.GetItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	table := aws.ToString(input.TableName)
+	return &dynamodb.GetItemOutput{Item: f.tables[table][f.itemKey(table, input.Key)]}, nil
+}
+
+func (f *fakeMigrationTables) PutItem(ctx context.Context, input *dynamodb.PutItemInput, opts ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	table := aws.ToString(input.TableName)
+	if f.tables[table] == nil {
+		f.tables[table] = map[string]map[string]types.AttributeValue{}
+	}
+	key := f.itemKey(table, input.Item)
+	if _, exists := f.tables[table][key]; exists {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("exists")}
+	}
+	f.tables[table][key] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func TestMigrateObjectKeyTables(t *testing.T) {
+	objectKeyItem := func(key string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"BucketName":          &types.AttributeValueMemberS{Value: "bucket"},
+			"ObjectKey":           &types.AttributeValueMemberS{Value: key},
+			"LastChecksumSuccess": &types.AttributeValueMemberBOOL{Value: true},
+		}
+	}
+	client := &fakeMigrationTables{tables: map[string]map[string]map[string]types.AttributeValue{
+		"object-key-checksums": {
+			"bucket/current.txt": objectKeyItem("current.txt"),
+			"bucket/deleted.txt": objectKeyItem("deleted.txt"),
+		},
+		"object-key-scheduler": {
+			"bucket/current.txt": objectKeyItem("current.txt"),
+		},
+	}}
+	ddb := NewDB(context.Background(), client, "checksums", "scheduler")
+
+	current := func(obj files.S3Object) (string, bool, error) {
+		if obj.Key == "deleted.txt" {
+			return "", false, nil
+		}
+		return "v2", true, nil
+	}
+
+	result, err := ddb.MigrateObjectKeyTables("object-key-checksums", "object-key-scheduler", "", "", current)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Complete || result.Records != 2 || result.Copied != 1 || result.Dropped != 1 || result.Scheduled != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	record, ok := client.tables["checksums"]["bucket/current.txt#v2"]
+	if !ok || attributeString(record, "VersionId") != "v2" {
+		t.Errorf("Expected the record keyed by the current version, got %v", client.tables["checksums"])
+	}
+	if entry, ok := client.tables["scheduler"]["bucket/current.txt#v2"]; !ok || attributeString(entry, "VersionId") != "v2" {
+		t.Errorf("Expected the entry keyed by the current version, got %v", client.tables["scheduler"])
+	}
+	if len(client.tables["checksums"]) != 1 {
+		t.Errorf("Expected the record of the deleted object to be dropped, got %v", client.tables["checksums"])
+	}
+}
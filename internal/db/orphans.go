@@ -0,0 +1,174 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// orphanGrace is how long after its NextChecksumDate a record that passed may be without a
+// scheduler entry, DynamoDB removes expired entries within about 48 hours and the verification
+// that follows writes the next one
+const orphanGrace = 72 * time.Hour
+
+// The failing records the orphan sweep schedules to be checked again
+const (
+	RecheckAll       = "all"       // every failing record, including corrupted ones
+	RecheckNone      = "none"      // no failing record, they are left for an operator
+	RecheckTransient = "transient" // records whose object (or replica) was missing or unreadable
+)
+
+// RecheckPolicy sets which failing records the orphan sweep schedules to be checked again, After
+// their last check. Verify only schedules a record that passed, so without a recheck a failing
+// record is not checked again until it is requested.
+type RecheckPolicy struct {
+	Failures string
+	After    Period
+}
+
+// DefaultRecheckPolicy checks records that failed to read their object again after a week. A
+// recheck is a verification, which removes the records of a version that no longer exists
+// because the lifecycle rule expired it, so expired versions are not reported missing again.
+var DefaultRecheckPolicy = RecheckPolicy{
+	Failures: RecheckTransient,
+	After:    Period{Days: 7},
+}
+
+// NewRecheckPolicy parses a policy, an empty value keeps the default failures or period
+func NewRecheckPolicy(failures, after string) (RecheckPolicy, error) {
+	policy := DefaultRecheckPolicy
+
+	switch failures {
+	case "":
+	case RecheckAll, RecheckNone, RecheckTransient:
+		policy.Failures = failures
+	default:
+		return policy, ErrorInvalidRecheckPolicy(failures)
+	}
+
+	if after != "" {
+		period, err := ParsePeriod(after)
+		if err != nil {
+			return policy, err
+		}
+		policy.After = period
+	}
+
+	return policy, nil
+}
+
+func (p RecheckPolicy) String() string {
+	return fmt.Sprintf("failures=%s after=%s", p.Failures, p.After)
+}
+
+// rechecks reports whether the policy checks a failing record again, a record whose primary
+// passed failed its replica check
+func (p RecheckPolicy) rechecks(record ChecksumRecord) bool {
+	category := record.FailureCategory
+	if category == "" {
+		category = record.ReplicaStatus
+	}
+
+	switch p.Failures {
+	case RecheckAll:
+		return true
+	case RecheckTransient:
+		return category == FailureMissing || category == FailureUnreadable
+	}
+	return false
+}
+
+// Outcomes of sweeping a record
+const (
+	sweepNotDue = iota
+	sweepOrphan
+	sweepRecheck
+	sweepUnscheduled
+)
+
+// sweep returns what the orphan sweep does with a record at now, before it looks for its
+// scheduler entry
+func (p RecheckPolicy) sweep(record ChecksumRecord, now time.Time) int {
+	if record.LastChecksumSuccess {
+		if record.NextChecksumDate.Before(now.Add(-orphanGrace)) {
+			return sweepOrphan
+		}
+		return sweepNotDue
+	}
+
+	if !p.rechecks(record) {
+		return sweepUnscheduled
+	}
+	if p.After.After(record.LastChecksumDate).After(now) {
+		return sweepNotDue
+	}
+	return sweepRecheck
+}
+
+// SweepResult summarizes a SweepOrphans
+type SweepResult struct {
+	Pass
+	Records     int // checksum records read
+	Orphans     int // records that passed without a scheduler entry past their next check, rescheduled
+	Rechecked   int // failing records scheduled to be checked again under the recheck policy
+	Unscheduled int // failing records the recheck policy leaves without a scheduler entry
+}
+
+// SweepOrphans finds the records of a bucket that have no scheduler entry although they are
+// due, because Schedule failed after Put or a verification failed, and recreates the entries.
+// Orphans are due within the jitter window of the fixity policy from now, failing records are
+// checked again under the recheck policy without changing their record (so the failure is not
+// reported again before it is checked). It is a pass over the bucket (see Pass).
+func (d *DB) SweepOrphans(bucket string, fixity FixityPolicy, recheck RecheckPolicy, startKey string) (SweepResult, error) {
+	var result SweepResult
+
+	var err error
+	result.Pass, err = d.queryBucket(d.checksumTable, bucket, startKey, queryFilter{}, func(record ChecksumRecord) error {
+		if err := d.sweepOrphan(record, fixity, recheck, &result); err != nil {
+			return err
+		}
+		result.Records++
+		return nil
+	})
+	return result, err
+}
+
+// sweepOrphan recreates the scheduler entry of a record that is due without one
+func (d *DB) sweepOrphan(record ChecksumRecord, fixity FixityPolicy, recheck RecheckPolicy, result *SweepResult) error {
+	now := time.Now()
+	action := recheck.sweep(record, now)
+	if action == sweepUnscheduled {
+		result.Unscheduled++
+	}
+	if action != sweepOrphan && action != sweepRecheck {
+		return nil
+	}
+
+	_, err := d.Next(record.Object())
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrChecksumRecordNotFound) {
+		return err
+	}
+
+	if action == sweepRecheck {
+		record.NextChecksumDate = now
+		if err := d.Schedule(record); err != nil {
+			return err
+		}
+		result.Rechecked++
+		return nil
+	}
+
+	next, err := fixity.withJitter(now)
+	if err != nil {
+		return err
+	}
+
+	rescheduled, err := d.Reschedule(record, next)
+	if rescheduled {
+		result.Orphans++
+	}
+	return err
+}
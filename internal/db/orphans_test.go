@@ -0,0 +1,98 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRecheckPolicy(t *testing.T) {
+	policy, err := NewRecheckPolicy("", "")
+	if err != nil || policy != DefaultRecheckPolicy {
+		t.Errorf("Expected the default policy, got %v (%v)", policy, err)
+	}
+
+	policy, err = NewRecheckPolicy(RecheckAll, "1m")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if policy.Failures != RecheckAll || policy.After != (Period{Months: 1}) {
+		t.Errorf("Unexpected policy: %v", policy)
+	}
+
+	if _, err := NewRecheckPolicy("corrupted", ""); err == nil {
+		t.Error("Expected an error for unknown failures")
+	}
+
+	if _, err := NewRecheckPolicy(RecheckNone, "soon"); err == nil {
+		t.Error("Expected an error for an invalid period")
+	}
+}
+
+func TestRecheckPolicySweep(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastWeek := now.AddDate(0, 0, -8)
+
+	tests := []struct {
+		name     string
+		policy   RecheckPolicy
+		record   ChecksumRecord
+		expected int
+	}{
+		{
+			name:     "passed and due",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{LastChecksumSuccess: true, NextChecksumDate: now.AddDate(0, 0, -1)},
+			expected: sweepNotDue,
+		},
+		{
+			name:     "passed and overdue",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{LastChecksumSuccess: true, NextChecksumDate: now.AddDate(0, 0, -4)},
+			expected: sweepOrphan,
+		},
+		{
+			name:     "missing",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{FailureCategory: FailureMissing, LastChecksumDate: lastWeek},
+			expected: sweepRecheck,
+		},
+		{
+			name:     "missing recently",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{FailureCategory: FailureMissing, LastChecksumDate: now.AddDate(0, 0, -2)},
+			expected: sweepNotDue,
+		},
+		{
+			name:     "unreadable replica",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{ReplicaStatus: FailureUnreadable, LastChecksumDate: lastWeek},
+			expected: sweepRecheck,
+		},
+		{
+			name:     "corrupted",
+			policy:   DefaultRecheckPolicy,
+			record:   ChecksumRecord{FailureCategory: FailureCorrupted, LastChecksumDate: lastWeek},
+			expected: sweepUnscheduled,
+		},
+		{
+			name:     "corrupted with all",
+			policy:   RecheckPolicy{Failures: RecheckAll, After: Period{Days: 7}},
+			record:   ChecksumRecord{FailureCategory: FailureCorrupted, LastChecksumDate: lastWeek},
+			expected: sweepRecheck,
+		},
+		{
+			name:     "missing with none",
+			policy:   RecheckPolicy{Failures: RecheckNone, After: Period{Days: 7}},
+			record:   ChecksumRecord{FailureCategory: FailureMissing, LastChecksumDate: lastWeek},
+			expected: sweepUnscheduled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if action := tt.policy.sweep(tt.record, now); action != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, action)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"maps"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// queryReserve is the time kept back from the context deadline to return a pass over a bucket in progress
+const queryReserve = 30 * time.Second
+
+// Pass is how far a pass over the records of a bucket got. A pass stops before the context
+// deadline, then Complete is false and NextKey is the last object version it reached, call it
+// again with NextKey as its startKey to continue.
+type Pass struct {
+	Complete bool
+	NextKey  string
+}
+
+// queryFilter narrows the records of a bucket queried by queryBucket, Condition is added to the
+// key condition on BucketName and Expression filters the records read, both are optional
+type queryFilter struct {
+	Condition  string
+	Expression string
+	Values     map[string]types.AttributeValue
+}
+
+// queryBucket calls fn with each record of a bucket in a table (the checksum or scheduler
+// table) after startKey in key order, it stops before the context deadline
+func (d *DB) queryBucket(table, bucket, startKey string, filter queryFilter, fn func(ChecksumRecord) error) (Pass, error) {
+	pass := Pass{NextKey: startKey}
+	deadline, hasDeadline := d.ctx.Deadline()
+
+	condition := "BucketName = :bucket"
+	if filter.Condition != "" {
+		condition += " AND " + filter.Condition
+	}
+
+	values := maps.Clone(filter.Values)
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+	values[":bucket"] = &types.AttributeValueMemberS{Value: bucket}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(table),
+		KeyConditionExpression:    aws.String(condition),
+		ExpressionAttributeValues: values,
+	}
+	if filter.Expression != "" {
+		input.FilterExpression = aws.String(filter.Expression)
+	}
+	if startKey != "" {
+		input.ExclusiveStartKey = map[string]types.AttributeValue{
+			"BucketName": &types.AttributeValueMemberS{Value: bucket},
+			"VersionKey": &types.AttributeValueMemberS{Value: startKey},
+		}
+	}
+
+	for {
+		page, err := d.client.Query(d.ctx, input)
+		if err != nil {
+			return pass, err
+		}
+
+		for _, item := range page.Items {
+			if hasDeadline && time.Until(deadline) < queryReserve {
+				return pass, nil
+			}
+
+			var record ChecksumRecord
+			if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+				return pass, ErrorUnmarshallingChecksum(err)
+			}
+
+			if err := fn(record); err != nil {
+				return pass, err
+			}
+			pass.NextKey = VersionKey(record.Object())
+		}
+
+		if page.LastEvaluatedKey == nil {
+			return Pass{Complete: true}, nil
+		}
+		input.ExclusiveStartKey = page.LastEvaluatedKey
+	}
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
+
+// DayLoad is the number of verifications due on a day and the bytes they read
+type DayLoad struct {
+	Objects int   `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+}
+
+// lighter reports whether a load reads fewer bytes than another, or as many bytes for fewer objects
+func (l DayLoad) lighter(other DayLoad) bool {
+	if l.Bytes != other.Bytes {
+		return l.Bytes < other.Bytes
+	}
+	return l.Objects < other.Objects
+}
+
+// Histogram is the verification load due on each day, keyed by the UTC date (2006-01-02)
+type Histogram map[string]DayLoad
+
+// DayOf returns the histogram day of a time
+func DayOf(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// Add counts a verification of size bytes due on a day
+func (h Histogram) Add(day string, size int64) {
+	load := h[day]
+	load.Objects++
+	load.Bytes += size
+	h[day] = load
+}
+
+func (h Histogram) remove(day string, size int64) {
+	load := h[day]
+	load.Objects--
+	load.Bytes -= size
+	if load.Objects <= 0 {
+		delete(h, day)
+		return
+	}
+	h[day] = load
+}
+
+// Days returns the days with verifications due in order
+func (h Histogram) Days() []string {
+	return slices.Sorted(maps.Keys(h))
+}
+
+// Peak returns the day with the heaviest load, the earliest of days with the same load
+func (h Histogram) Peak() (string, DayLoad) {
+	var peak string
+	var peakLoad DayLoad
+	for _, day := range h.Days() {
+		if peakLoad.lighter(h[day]) {
+			peak, peakLoad = day, h[day]
+		}
+	}
+	return peak, peakLoad
+}
+
+// lightest returns the lightest day from start until end, current when no day is lighter than it
+func (h Histogram) lightest(start, end time.Time, current string) string {
+	best, bestLoad := current, h[current]
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if load := h[DayOf(day)]; load.lighter(bestLoad) {
+			best, bestLoad = DayOf(day), load
+		}
+	}
+	return best
+}
+
+// Outcomes of rebalancing a scheduled verification
+const (
+	rebalanceFixed = iota
+	rebalanceKept
+	rebalanceMoved
+)
+
+// rebalanceWindow returns the part of the window of a policy the verification of a record
+// scheduled at next may move within, from the day after now. Verifications of failing or handed
+// off records, that are due by tomorrow or outside the window (respread or swept) are not moved.
+func (p FixityPolicy) rebalanceWindow(record ChecksumRecord, next, now time.Time) (time.Time, time.Time, bool) {
+	if !record.LastChecksumSuccess || record.HandedOff || !record.NextChecksumDate.Equal(next) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := p.Interval.After(record.LastChecksumDate)
+	end := p.Jitter.After(start)
+	tomorrow := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if next.Before(start) || next.After(end) || next.Before(tomorrow) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	if start.Before(tomorrow) {
+		start = tomorrow
+	}
+	return start, end, true
+}
+
+// rebalance moves the verification of a record scheduled at next to the lightest day of its
+// window, the histogram includes it and is updated. It returns when the verification is due.
+func (h Histogram) rebalance(policy FixityPolicy, record ChecksumRecord, next, now time.Time) (time.Time, int, error) {
+	start, end, ok := policy.rebalanceWindow(record, next, now)
+	if !ok {
+		return next, rebalanceFixed, nil
+	}
+
+	day := DayOf(next)
+	h.remove(day, record.Size)
+	target := h.lightest(start, end, day)
+	h.Add(target, record.Size)
+	if target == day {
+		return next, rebalanceKept, nil
+	}
+
+	// A random time of the day, within the window on its first and last days
+	dayStart, err := time.Parse(time.DateOnly, target)
+	if err != nil {
+		return next, rebalanceFixed, err
+	}
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	if start.After(dayStart) {
+		dayStart = start
+	}
+	if end.Before(dayEnd) {
+		dayEnd = end
+	}
+
+	moved, err := randomTime(dayStart, dayEnd)
+	if err != nil {
+		return next, rebalanceFixed, err
+	}
+	return moved, rebalanceMoved, nil
+}
+
+// RebalanceResult summarizes a pass over the scheduler entries of a bucket
+type RebalanceResult struct {
+	Pass
+	Entries int // scheduler entries read
+	Moved   int // verifications moved to a lighter day of their window
+	Kept    int // verifications already on the lightest day of their window
+	Fixed   int // verifications that are not moved (see FixityPolicy.rebalanceWindow)
+}
+
+// SurveySchedule adds the verifications scheduled in a bucket to a histogram, sized by their
+// checksum record. It is a pass over the bucket (see Pass), continued with the same histogram.
+func (d *DB) SurveySchedule(bucket string, histogram Histogram, startKey string) (RebalanceResult, error) {
+	return d.scheduleEntries(bucket, startKey, func(entry ChecksumRecord, _ *RebalanceResult) error {
+		record, err := d.Get(entry.Object())
+		if err != nil && !errors.Is(err, ErrChecksumRecordNotFound) {
+			return err
+		}
+
+		histogram.Add(DayOf(entry.NextChecksumDate), record.Size)
+		return nil
+	})
+}
+
+// RebalanceSchedule moves each verification scheduled in a bucket to the lightest day of its
+// window under a policy (the interval after its last check plus the jitter window). The
+// histogram is the survey of the bucket, it is updated as verifications move and ends as the
+// distribution after rebalancing. With dryRun the distribution is worked out without moving
+// anything. It is a pass over the bucket (see Pass), continued with the same histogram.
+func (d *DB) RebalanceSchedule(bucket string, policy FixityPolicy, histogram Histogram, startKey string, dryRun bool) (RebalanceResult, error) {
+	now := time.Now()
+
+	return d.scheduleEntries(bucket, startKey, func(entry ChecksumRecord, result *RebalanceResult) error {
+		record, err := d.Get(entry.Object())
+		if errors.Is(err, ErrChecksumRecordNotFound) {
+			result.Fixed++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		next, outcome, err := histogram.rebalance(policy, record, entry.NextChecksumDate, now)
+		if err != nil {
+			return err
+		}
+
+		switch outcome {
+		case rebalanceFixed:
+			result.Fixed++
+		case rebalanceKept:
+			result.Kept++
+		case rebalanceMoved:
+			if !dryRun {
+				moved, err := d.Reschedule(record, next)
+				if err != nil {
+					return err
+				}
+
+				// Checked since it was read, its next verification is no longer in the window
+				if !moved {
+					histogram.remove(DayOf(next), record.Size)
+					result.Fixed++
+					return nil
+				}
+			}
+			result.Moved++
+		}
+		return nil
+	})
+}
+
+// scheduleEntries calls fn with each scheduler entry of a bucket after startKey in key order
+func (d *DB) scheduleEntries(bucket, startKey string, fn func(ChecksumRecord, *RebalanceResult) error) (RebalanceResult, error) {
+	var result RebalanceResult
+
+	var err error
+	result.Pass, err = d.queryBucket(d.schedulerTable, bucket, startKey, queryFilter{}, func(entry ChecksumRecord) error {
+		if err := fn(entry, &result); err != nil {
+			return err
+		}
+		result.Entries++
+		return nil
+	})
+	return result, err
+}
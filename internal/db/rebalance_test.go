@@ -0,0 +1,144 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	histogram := Histogram{}
+	histogram.Add("2025-06-02", 100)
+	histogram.Add("2025-06-01", 50)
+	histogram.Add("2025-06-02", 20)
+	histogram.Add("2025-06-03", 120)
+
+	if days := histogram.Days(); len(days) != 3 || days[0] != "2025-06-01" || days[2] != "2025-06-03" {
+		t.Errorf("Unexpected days: %v", days)
+	}
+
+	// Equal bytes, the day with more objects is heavier
+	if day, load := histogram.Peak(); day != "2025-06-02" || load != (DayLoad{Objects: 2, Bytes: 120}) {
+		t.Errorf("Unexpected peak: %s %+v", day, load)
+	}
+
+	histogram.remove("2025-06-01", 50)
+	if _, ok := histogram["2025-06-01"]; ok {
+		t.Error("Expected a day without verifications to be removed")
+	}
+
+	if day, load := (Histogram{}).Peak(); day != "" || load != (DayLoad{}) {
+		t.Errorf("Expected no peak, got %s %+v", day, load)
+	}
+}
+
+func TestHistogramRebalance(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := FixityPolicy{Interval: Period{Months: 1}, Jitter: Period{Days: 5}}
+
+	// Checked on May 5th, the window is June 5th to June 10th
+	last := time.Date(2025, 5, 5, 9, 30, 0, 0, time.UTC)
+	next := time.Date(2025, 6, 6, 8, 0, 0, 0, time.UTC)
+	record := ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true, NextChecksumDate: next, Size: 10}
+
+	histogram := Histogram{}
+	for range 3 {
+		histogram.Add("2025-06-06", 10)
+	}
+	for _, day := range []string{"2025-06-05", "2025-06-07", "2025-06-08", "2025-06-10"} {
+		histogram.Add(day, 10)
+	}
+
+	moved, outcome, err := histogram.rebalance(policy, record, next, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if outcome != rebalanceMoved || DayOf(moved) != "2025-06-09" {
+		t.Fatalf("Expected a move to the empty day, got %d %s", outcome, moved)
+	}
+	if histogram["2025-06-06"].Objects != 2 || histogram["2025-06-09"].Objects != 1 {
+		t.Errorf("Unexpected histogram: %v", histogram)
+	}
+
+	// Without it its new day is the lightest of the window, it stays there
+	record.NextChecksumDate = moved
+	if _, outcome, _ := histogram.rebalance(policy, record, moved, now); outcome != rebalanceKept {
+		t.Errorf("Expected the verification to be kept, got %d", outcome)
+	}
+
+	// The last day of the window ends when the window ends
+	end := time.Date(2025, 6, 10, 9, 30, 0, 0, time.UTC)
+	for range 10 {
+		tail := Histogram{"2025-06-06": {Objects: 2, Bytes: 20}}
+		for _, day := range []string{"2025-06-05", "2025-06-07", "2025-06-08", "2025-06-09"} {
+			tail.Add(day, 10)
+		}
+		record.NextChecksumDate = next
+		moved, _, _ := tail.rebalance(policy, record, next, now)
+		if DayOf(moved) != "2025-06-10" || moved.After(end) {
+			t.Errorf("Expected a time on June 10th by %s, got %s", end, moved)
+		}
+	}
+}
+
+func TestFixityPolicyRebalanceWindow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := FixityPolicy{Interval: Period{Months: 1}, Jitter: Period{Days: 5}}
+	last := time.Date(2025, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		record ChecksumRecord
+		next   time.Time
+		start  time.Time
+		ok     bool
+	}{
+		{
+			name:   "starts tomorrow",
+			record: ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true},
+			next:   time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+			start:  time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC),
+			ok:     true,
+		},
+		{
+			name:   "due today",
+			record: ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true},
+			next:   time.Date(2025, 6, 1, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "outside the window",
+			record: ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true},
+			next:   time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "failing",
+			record: ChecksumRecord{LastChecksumDate: last},
+			next:   time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "handed off",
+			record: ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true, HandedOff: true},
+			next:   time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "record scheduled for another time",
+			record: ChecksumRecord{LastChecksumDate: last, LastChecksumSuccess: true, NextChecksumDate: now},
+			next:   time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.record.NextChecksumDate.IsZero() {
+				tt.record.NextChecksumDate = tt.next
+			}
+
+			start, end, ok := policy.rebalanceWindow(tt.record, tt.next, now)
+			if ok != tt.ok {
+				t.Fatalf("Expected %v, got %v", tt.ok, ok)
+			}
+			if ok && (!start.Equal(tt.start) || !end.Equal(time.Date(2025, 6, 6, 9, 30, 0, 0, time.UTC))) {
+				t.Errorf("Unexpected window: %s to %s", start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SchedulerTableRequestId marks a scheduler entry that was removed to verify an object on request
+const SchedulerTableRequestId = "VerificationRequest"
+
+// RequestResult summarizes a RequestVerifications
+type RequestResult struct {
+	Pass
+	Requested int
+	Skipped   int
+}
+
+// IsVerificationRequest checks if a scheduler entry was removed by RequestVerification
+func IsVerificationRequest(record events.DynamoDBEventRecord) bool {
+	if record.EventName != "REMOVE" {
+		return false
+	}
+
+	_, exists := record.Change.OldImage[SchedulerTableRequestId]
+	return exists
+}
+
+// RequestVerification verifies an object version now rather than when it is scheduled. The
+// scheduler entry is replaced by one marked with the request and removed, the removal triggers
+// the verification like a TTL expiry and the verification schedules the next check.
+func (d *DB) RequestVerification(obj files.S3Object, requestId string) error {
+	now := time.Now()
+	item := map[string]types.AttributeValue{
+		"BucketName":            &types.AttributeValueMemberS{Value: obj.Bucket},
+		"ObjectKey":             &types.AttributeValueMemberS{Value: obj.Key},
+		"VersionKey":            &types.AttributeValueMemberS{Value: VersionKey(obj)},
+		"NextChecksumDate":      &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		"TTL":                   &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", now.Unix())},
+		SchedulerTableRequestId: &types.AttributeValueMemberS{Value: requestId},
+	}
+
+	if obj.VersionId != "" {
+		item["VersionId"] = &types.AttributeValueMemberS{Value: obj.VersionId}
+	}
+
+	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.schedulerTable),
+		Item:      item,
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.delete(d.schedulerTable, obj)
+}
+
+// RequestVerifications requests the verification of every version of the objects in a bucket
+// whose key starts with prefix, or of the object key itself when exact is set. Versions already
+// handed off for an immediate check are skipped. It is a pass over the bucket (see Pass).
+func (d *DB) RequestVerifications(bucket, prefix string, exact bool, requestId, startKey string) (RequestResult, error) {
+	var result RequestResult
+	filter := queryFilter{
+		Condition: "begins_with(VersionKey, :prefix)",
+		Values: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: prefix},
+		},
+	}
+	if exact {
+		// Version keys of other objects can share the prefix when their key contains "#"
+		filter.Expression = "ObjectKey = :key"
+		filter.Values[":prefix"] = &types.AttributeValueMemberS{Value: prefix + "#"}
+		filter.Values[":key"] = &types.AttributeValueMemberS{Value: prefix}
+	}
+
+	var err error
+	result.Pass, err = d.queryBucket(d.checksumTable, bucket, startKey, filter, func(record ChecksumRecord) error {
+		if record.HandedOff {
+			result.Skipped++
+			return nil
+		}
+
+		if err := d.RequestVerification(record.Object(), requestId); err != nil {
+			return err
+		}
+		result.Requested++
+		return nil
+	})
+	return result, err
+}
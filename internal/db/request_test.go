@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestIsVerificationRequest(t *testing.T) {
+	requested := map[string]events.DynamoDBAttributeValue{
+		"BucketName":            events.NewStringAttribute("test-bucket"),
+		"ObjectKey":             events.NewStringAttribute("file.txt"),
+		SchedulerTableRequestId: events.NewStringAttribute("s3://test-bucket-requested/verify-requested.txt"),
+	}
+	scheduled := map[string]events.DynamoDBAttributeValue{
+		"BucketName": events.NewStringAttribute("test-bucket"),
+		"ObjectKey":  events.NewStringAttribute("file.txt"),
+	}
+
+	tests := []struct {
+		name     string
+		event    string
+		image    map[string]events.DynamoDBAttributeValue
+		expected bool
+	}{
+		{"requested removal", "REMOVE", requested, true},
+		{"scheduled removal", "REMOVE", scheduled, false},
+		{"requested insert", "INSERT", requested, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := events.DynamoDBEventRecord{
+				EventName: tt.event,
+				Change:    events.DynamoDBStreamRecord{OldImage: tt.image},
+			}
+			if got := IsVerificationRequest(record); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
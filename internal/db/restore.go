@@ -0,0 +1,103 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RestoreExpiry is how long a restore request is tracked, a Bulk restore from Deep Archive
+// completes within 48 hours so a request that never completed is dropped after this and
+// requested again by a later verification
+const RestoreExpiry = 7 * 24 * time.Hour
+
+// RestoreRecord is a restore of the replica of an object version that was requested so its
+// replica check can finish once the restore completes, it is keyed by the primary object version
+type RestoreRecord struct {
+	BucketName  string    `dynamodbav:"BucketName"`
+	ObjectKey   string    `dynamodbav:"ObjectKey"`
+	VersionId   string    `dynamodbav:"VersionId"`
+	RequestDate time.Time `dynamodbav:"RequestDate"`
+}
+
+// Object returns the object version the restore was requested for
+func (r RestoreRecord) Object() files.S3Object {
+	return files.NewS3ObjectVersion(r.BucketName, r.ObjectKey, r.VersionId)
+}
+
+// WithRestores tracks pending replica restores in the restore table
+func (d *DB) WithRestores(restoreTable string) *DB {
+	d.restoreTable = restoreTable
+	return d
+}
+
+// DeleteRestore removes the restore request of an object version once it has been handled
+func (d *DB) DeleteRestore(obj files.S3Object) error {
+	if d.restoreTable == "" {
+		return ErrorRestoresNotConfigured(ObjectId(obj))
+	}
+
+	return d.delete(d.restoreTable, obj)
+}
+
+// GetRestore returns the pending restore request of an object version
+func (d *DB) GetRestore(obj files.S3Object) (RestoreRecord, error) {
+	if d.restoreTable == "" {
+		return RestoreRecord{}, ErrorRestoresNotConfigured(ObjectId(obj))
+	}
+
+	result, err := d.client.GetItem(d.ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.restoreTable),
+		Key:            key(obj),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return RestoreRecord{}, err
+	}
+
+	if result.Item == nil {
+		return RestoreRecord{}, ErrorRestoreNotFound(obj.Bucket, obj.Key)
+	}
+
+	restoreRecord := RestoreRecord{}
+	err = attributevalue.UnmarshalMap(result.Item, &restoreRecord)
+	if err != nil {
+		return RestoreRecord{}, ErrorUnmarshallingRestore(err)
+	}
+
+	return restoreRecord, nil
+}
+
+// PutRestore records a restore request, it expires after RestoreExpiry
+func (d *DB) PutRestore(record RestoreRecord) error {
+	if d.restoreTable == "" {
+		return ErrorRestoresNotConfigured(ObjectId(record.Object()))
+	}
+
+	if record.RequestDate.IsZero() {
+		record.RequestDate = time.Now()
+	}
+
+	item := map[string]types.AttributeValue{
+		"BucketName":  &types.AttributeValueMemberS{Value: record.BucketName},
+		"ObjectKey":   &types.AttributeValueMemberS{Value: record.ObjectKey},
+		"VersionKey":  &types.AttributeValueMemberS{Value: VersionKey(record.Object())},
+		"RequestDate": &types.AttributeValueMemberS{Value: record.RequestDate.Format(time.RFC3339)},
+		"TTL":         &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", record.RequestDate.Add(RestoreExpiry).Unix())},
+	}
+
+	if record.VersionId != "" {
+		item["VersionId"] = &types.AttributeValueMemberS{Value: record.VersionId}
+	}
+
+	_, err := d.client.PutItem(d.ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.restoreTable),
+		Item:      item,
+	})
+	return err
+}
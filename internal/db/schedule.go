@@ -0,0 +1,245 @@
+package db
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var periodPattern = regexp.MustCompile(`(\d+)([ymwd])`)
+
+// Period is a calendar period, months and years vary in length so it is applied with AddDate
+type Period struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// ParsePeriod parses a period such as "1y", "3m", "5m14d", "2w" or "90d"
+func ParsePeriod(value string) (Period, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	matches := periodPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return Period{}, ErrorInvalidPeriod(value)
+	}
+
+	var period Period
+	end := 0
+	for _, match := range matches {
+		if match[0] != end {
+			return Period{}, ErrorInvalidPeriod(value)
+		}
+		end = match[1]
+
+		n, err := strconv.Atoi(value[match[2]:match[3]])
+		if err != nil {
+			return Period{}, ErrorInvalidPeriod(value)
+		}
+
+		switch value[match[4]:match[5]] {
+		case "y":
+			period.Years += n
+		case "m":
+			period.Months += n
+		case "w":
+			period.Days += n * 7
+		case "d":
+			period.Days += n
+		}
+	}
+
+	if end != len(value) {
+		return Period{}, ErrorInvalidPeriod(value)
+	}
+
+	return period, nil
+}
+
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+func (p Period) String() string {
+	var b strings.Builder
+	if p.Years > 0 {
+		b.WriteString(fmt.Sprintf("%dy", p.Years))
+	}
+	if p.Months > 0 {
+		b.WriteString(fmt.Sprintf("%dm", p.Months))
+	}
+	if p.Days > 0 || b.Len() == 0 {
+		b.WriteString(fmt.Sprintf("%dd", p.Days))
+	}
+	return b.String()
+}
+
+// After returns t advanced by the period
+func (p Period) After(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days)
+}
+
+// FixityPolicy sets when an object is verified again, Interval after the last check
+// plus a random delay within the Jitter window so deposits made together are spread out
+type FixityPolicy struct {
+	Interval Period
+	Jitter   Period
+}
+
+// DefaultFixityPolicy applies to buckets without a policy of their own
+var DefaultFixityPolicy = FixityPolicy{
+	Interval: Period{Months: 5, Days: 14},
+	Jitter:   Period{Days: 30},
+}
+
+// NewFixityPolicy parses a policy, an empty value keeps the default interval or jitter
+func NewFixityPolicy(interval, jitter string) (FixityPolicy, error) {
+	policy := DefaultFixityPolicy
+
+	if interval != "" {
+		period, err := ParsePeriod(interval)
+		if err != nil {
+			return policy, err
+		}
+		if period.IsZero() {
+			return policy, ErrorInvalidPeriod(interval)
+		}
+		policy.Interval = period
+	}
+
+	if jitter != "" {
+		period, err := ParsePeriod(jitter)
+		if err != nil {
+			return policy, err
+		}
+		policy.Jitter = period
+	}
+
+	return policy, nil
+}
+
+func (p FixityPolicy) String() string {
+	return fmt.Sprintf("interval=%s jitter=%s", p.Interval, p.Jitter)
+}
+
+// NextScheduledTime returns when an object checked at from is next verified
+func (p FixityPolicy) NextScheduledTime(from time.Time) (time.Time, error) {
+	return p.withJitter(p.Interval.After(from))
+}
+
+// withJitter returns a random time (to the minute) within the jitter window starting at base
+func (p FixityPolicy) withJitter(base time.Time) (time.Time, error) {
+	return randomTime(base, p.Jitter.After(base))
+}
+
+// randomTime returns a random time (to the minute) from start until end
+func randomTime(start, end time.Time) (time.Time, error) {
+	window := int64(end.Sub(start) / time.Minute)
+	if window <= 0 {
+		return start, nil
+	}
+
+	jitterMinutes, err := rand.Int(rand.Reader, big.NewInt(window))
+	if err != nil {
+		return start, ErrorGeneratingJitter("minute", err)
+	}
+
+	return start.Add(time.Duration(jitterMinutes.Int64()) * time.Minute), nil
+}
+
+// GetNextScheduledTime returns the next verification time under the default policy
+func GetNextScheduledTime() (time.Time, error) {
+	return DefaultFixityPolicy.NextScheduledTime(time.Now())
+}
+
+// RespreadResult summarizes a Respread
+type RespreadResult struct {
+	Pass
+	Rescheduled int
+	Skipped     int
+}
+
+// Respread reschedules the verified objects of a bucket under a new policy. Each object is due
+// the policy interval after its last check, or within the jitter window from now when that has
+// already passed. It is a pass over the bucket (see Pass).
+func (d *DB) Respread(bucket string, policy FixityPolicy, startKey string) (RespreadResult, error) {
+	var result RespreadResult
+	filter := queryFilter{
+		Expression: "LastChecksumSuccess = :success",
+		Values: map[string]types.AttributeValue{
+			":success": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	}
+
+	var err error
+	result.Pass, err = d.queryBucket(d.checksumTable, bucket, startKey, filter, func(record ChecksumRecord) error {
+		rescheduled, err := d.respread(record, policy)
+		if err != nil {
+			return err
+		}
+
+		if rescheduled {
+			result.Rescheduled++
+		} else {
+			result.Skipped++
+		}
+		return nil
+	})
+	return result, err
+}
+
+// respread moves the next verification of a record, records handed off for an immediate
+// check or checked since they were read are left as they are
+func (d *DB) respread(record ChecksumRecord, policy FixityPolicy) (bool, error) {
+	if record.HandedOff {
+		return false, nil
+	}
+
+	next, err := policy.NextScheduledTime(record.LastChecksumDate)
+	if err != nil {
+		return false, err
+	}
+
+	if now := time.Now(); next.Before(now) {
+		next, err = policy.withJitter(now)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return d.Reschedule(record, next)
+}
+
+// Reschedule moves the next verification of a record to next and writes its scheduler entry,
+// only NextChecksumDate is updated so the change is not taken for a new check. A record checked
+// since it was read is left as it is and false returned.
+func (d *DB) Reschedule(record ChecksumRecord, next time.Time) (bool, error) {
+	_, err := d.client.UpdateItem(d.ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(d.checksumTable),
+		Key:                 key(record.Object()),
+		UpdateExpression:    aws.String("SET NextChecksumDate = :next"),
+		ConditionExpression: aws.String("LastChecksumDate = :last"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":next": &types.AttributeValueMemberS{Value: next.Format(time.RFC3339)},
+			":last": &types.AttributeValueMemberS{Value: record.LastChecksumDate.Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	record.NextChecksumDate = next
+	return true, d.Schedule(record)
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Period
+		wantErr  bool
+	}{
+		{"3m", Period{Months: 3}, false},
+		{"1y", Period{Years: 1}, false},
+		{"5m14d", Period{Months: 5, Days: 14}, false},
+		{"2w", Period{Days: 14}, false},
+		{" 90D ", Period{Days: 90}, false},
+		{"0d", Period{}, false},
+		{"", Period{}, true},
+		{"3", Period{}, true},
+		{"3h", Period{}, true},
+		{"m3", Period{}, true},
+		{"3m x", Period{}, true},
+	}
+
+	for _, tt := range tests {
+		period, err := ParsePeriod(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePeriod(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+
+		if period != tt.expected {
+			t.Errorf("ParsePeriod(%q) = %+v, expected %+v", tt.value, period, tt.expected)
+		}
+	}
+}
+
+func TestPeriodString(t *testing.T) {
+	tests := map[Period]string{
+		{}:                    "0d",
+		{Years: 1}:            "1y",
+		{Months: 5, Days: 14}: "5m14d",
+	}
+
+	for period, expected := range tests {
+		if period.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, period.String())
+		}
+	}
+}
+
+func TestNewFixityPolicy(t *testing.T) {
+	policy, err := NewFixityPolicy("", "")
+	if err != nil || policy != DefaultFixityPolicy {
+		t.Errorf("Expected the default policy, got %v (%v)", policy, err)
+	}
+
+	policy, err = NewFixityPolicy("3m", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if policy.Interval != (Period{Months: 3}) || policy.Jitter != DefaultFixityPolicy.Jitter {
+		t.Errorf("Unexpected policy: %v", policy)
+	}
+
+	if _, err := NewFixityPolicy("0d", ""); err == nil {
+		t.Error("Expected an error for a zero interval")
+	}
+
+	if _, err := NewFixityPolicy("1y", "soon"); err == nil {
+		t.Error("Expected an error for an invalid jitter")
+	}
+}
+
+func TestFixityPolicyNextScheduledTime(t *testing.T) {
+	from := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		policy FixityPolicy
+		min    time.Time
+		max    time.Time
+	}{
+		{
+			name:   "default",
+			policy: DefaultFixityPolicy,
+			min:    from.AddDate(0, 5, 14),
+			max:    from.AddDate(0, 5, 44),
+		},
+		{
+			name:   "quarterly",
+			policy: FixityPolicy{Interval: Period{Months: 3}, Jitter: Period{Days: 7}},
+			min:    from.AddDate(0, 3, 0),
+			max:    from.AddDate(0, 3, 7),
+		},
+		{
+			name:   "no jitter",
+			policy: FixityPolicy{Interval: Period{Years: 1}},
+			min:    from.AddDate(1, 0, 0),
+			max:    from.AddDate(1, 0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				next, err := tt.policy.NextScheduledTime(from)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				if next.Before(tt.min) || next.After(tt.max) {
+					t.Errorf("Expected %s between %s and %s", next, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
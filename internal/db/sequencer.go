@@ -0,0 +1,34 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SequencerWidth is the width sequencers are left padded to so they compare as strings
+const SequencerWidth = 32
+
+var sequencerPattern = regexp.MustCompile(`^[0-9A-F]+$`)
+
+// NormalizeSequencer returns the sequencer of an S3 event upper cased and left padded with
+// zeros to SequencerWidth, so the sequencers of the events of an object key compare as strings
+// (a greater sequencer is a later event). A value that is not hexadecimal is no sequencer.
+func NormalizeSequencer(sequencer string) string {
+	sequencer = strings.ToUpper(strings.TrimSpace(sequencer))
+	if !sequencerPattern.MatchString(sequencer) || len(sequencer) > SequencerWidth {
+		return ""
+	}
+	return strings.Repeat("0", SequencerWidth-len(sequencer)) + sequencer
+}
+
+// sequencerApplies reports whether an event with sequencer takes effect over a record with
+// stored, an event or record without a sequencer always does. The event that deposited a
+// record may write it again (a redelivery or a verification) when orEqual is set.
+func sequencerApplies(stored, sequencer string, orEqual bool) bool {
+	stored = NormalizeSequencer(stored)
+	sequencer = NormalizeSequencer(sequencer)
+	if stored == "" || sequencer == "" {
+		return true
+	}
+	return sequencer > stored || (orEqual && sequencer == stored)
+}
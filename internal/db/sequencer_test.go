@@ -0,0 +1,48 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSequencer(t *testing.T) {
+	tests := map[string]string{
+		"0055AED6DCD90281E5":                  strings.Repeat("0", 14) + "0055AED6DCD90281E5",
+		" 0a1b ":                              strings.Repeat("0", 28) + "0A1B",
+		"":                                    "",
+		"test-sequencer":                      "",
+		strings.Repeat("F", SequencerWidth+1): "",
+	}
+
+	for value, expected := range tests {
+		if normalized := NormalizeSequencer(value); normalized != expected {
+			t.Errorf("NormalizeSequencer(%q) = %q, expected %q", value, normalized, expected)
+		}
+	}
+}
+
+func TestSequencerApplies(t *testing.T) {
+	tests := []struct {
+		stored    string
+		sequencer string
+		orEqual   bool
+		expected  bool
+	}{
+		{"", "0A", false, true},
+		{"0A", "", false, true},
+		{"0A", "0B", false, true},
+		{"0B", "0A", false, false},
+		{"0A", "0A", false, false},
+		{"0A", "0A", true, true},
+		// Sequencers of different lengths are compared after padding
+		{"FF", "100", false, true},
+		{"100", "FF", true, false},
+	}
+
+	for _, tt := range tests {
+		if applies := sequencerApplies(tt.stored, tt.sequencer, tt.orEqual); applies != tt.expected {
+			t.Errorf("sequencerApplies(%q, %q, %v) = %v, expected %v",
+				tt.stored, tt.sequencer, tt.orEqual, applies, tt.expected)
+		}
+	}
+}
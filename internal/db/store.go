@@ -0,0 +1,66 @@
+package db
+
+import (
+	"duracloud/internal/files"
+	"time"
+)
+
+// ChecksumStore keeps the checksum record of each object version and its next scheduled
+// verification, DB is the DynamoDB implementation and MemoryStore keeps them in memory. Put and
+// Delete return ErrStaleSequencer rather than undo the changes of a later S3 event.
+type ChecksumStore interface {
+	Delete(obj files.S3Object, sequencer string) error
+	Get(obj files.S3Object) (ChecksumRecord, error)
+	Next(obj files.S3Object) (ChecksumRecord, error)
+	Put(record ChecksumRecord) error
+	Reschedule(record ChecksumRecord, next time.Time) (bool, error)
+	Schedule(record ChecksumRecord) error
+}
+
+// EventAppender is implemented by a store that keeps the fixity history
+type EventAppender interface {
+	AppendEvent(event FixityEvent) error
+}
+
+// LegacyStager is implemented by a store that stages checksums imported from a legacy repository
+// until the objects are deposited
+type LegacyStager interface {
+	ClaimLegacy(obj files.S3Object) (LegacyRecord, error)
+	StageLegacy(bucket, objectKey, algorithm, checksum, source string) error
+}
+
+// RestoreTracker is implemented by a store that tracks pending replica restores
+type RestoreTracker interface {
+	PutRestore(record RestoreRecord) error
+}
+
+// BudgetCounter is implemented by a store that counts verification reads against a byte budget
+type BudgetCounter interface {
+	RecordDeferred(budget ByteBudget) error
+	ReserveBytes(budget ByteBudget, size int64) (bool, error)
+}
+
+// VerificationRequester is implemented by a store that can verify an object version now rather
+// than when it is scheduled
+type VerificationRequester interface {
+	RequestVerification(obj files.S3Object, requestId string) error
+}
+
+// FailureLister is implemented by a store that lists the records whose last check failed
+type FailureLister interface {
+	ListFailures(bucket string, page Page) (FailurePage, error)
+}
+
+// RecordLister is implemented by a store that lists every record of a bucket
+type RecordLister interface {
+	Records(bucket string, fn func(ChecksumRecord) error) error
+}
+
+// RecordEvent appends an event to the fixity history of a store, a no-op for a store without one
+func RecordEvent(store ChecksumStore, event FixityEvent) error {
+	history, ok := store.(EventAppender)
+	if !ok {
+		return nil
+	}
+	return history.AppendEvent(event)
+}
@@ -2,6 +2,8 @@ package exports
 
 import (
 	"context"
+	"duracloud/internal/checksum"
+	"duracloud/internal/db"
 	"duracloud/internal/files"
 	"encoding/csv"
 	"encoding/json"
@@ -10,7 +12,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -20,16 +24,29 @@ import (
 
 const ManifestFile = "manifest-files.json"
 
+// ExportFormat is a report format written for each bucket of an export
+type ExportFormat string
+
+const (
+	FormatCSV    ExportFormat = "csv"
+	FormatPREMIS ExportFormat = "premis"
+)
+
 // This is an approx. overestimation of average csv row size (bytes)
 const RowSizeEstimate = 180
 
 var ExportHeaders = []string{
 	"BucketName",
 	"ObjectKey",
+	"VersionId",
 	"Checksum",
+	"ChecksumSHA256",
+	"ChecksumSHA512",
 	"LastChecksumSuccess",
 	"LastChecksumDate",
 	"LastChecksumMessage",
+	"FailureCategory",
+	"ReplicaStatus",
 }
 
 type csvOutput struct {
@@ -37,13 +54,40 @@ type csvOutput struct {
 	writer *csv.Writer
 }
 
+// ParseFormats parses a comma separated list of export formats, empty is CSV
+func ParseFormats(value string) ([]ExportFormat, error) {
+	var formats []ExportFormat
+	for _, name := range strings.Split(value, ",") {
+		format := ExportFormat(strings.ToLower(strings.TrimSpace(name)))
+		switch format {
+		case "":
+			continue
+		case FormatCSV, FormatPREMIS:
+			if !slices.Contains(formats, format) {
+				formats = append(formats, format)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported export format: %s", name)
+		}
+	}
+
+	if len(formats) == 0 {
+		return []ExportFormat{FormatCSV}, nil
+	}
+
+	return formats, nil
+}
+
 // Exporter provides utilites for processing an export manifest
 type Exporter struct {
 	ctx           context.Context
 	s3Client      *s3.Client
+	formats       []ExportFormat
+	history       HistorySource
 	manifest      files.S3Object
 	manifestFiles []string
 	outputFiles   map[string]*csvOutput
+	premisFiles   map[string]*premisOutput
 	totalItems    int
 }
 
@@ -51,11 +95,30 @@ func NewExporter(ctx context.Context, s3Client *s3.Client, manifest files.S3Obje
 	return &Exporter{
 		ctx:         ctx,
 		s3Client:    s3Client,
+		formats:     []ExportFormat{FormatCSV},
 		manifest:    manifest,
 		outputFiles: make(map[string]*csvOutput),
+		premisFiles: make(map[string]*premisOutput),
 	}
 }
 
+// WithFormats sets the report formats written for each bucket (CSV by default)
+func (e *Exporter) WithFormats(formats ...ExportFormat) *Exporter {
+	e.formats = formats
+	return e
+}
+
+// WithHistory adds the fixity history of each object to PREMIS reports, without it
+// the latest fixity check of the record is the only event
+func (e *Exporter) WithHistory(history HistorySource) *Exporter {
+	e.history = history
+	return e
+}
+
+func (e *Exporter) writes(format ExportFormat) bool {
+	return slices.Contains(e.formats, format)
+}
+
 func (e *Exporter) ProcessManifest() error {
 	manifest, err := files.DownloadObject(e.ctx, e.s3Client, e.manifest, false)
 	if err != nil {
@@ -94,6 +157,12 @@ func (e *Exporter) ProcessManifest() error {
 		_ = output.file.Close()
 	}
 
+	for _, output := range e.premisFiles {
+		if err := output.finish(); err != nil {
+			return err
+		}
+	}
+
 	// Upload each file, abort if any fail
 	err = e.uploadFiles()
 	if err != nil {
@@ -114,6 +183,9 @@ func (e *Exporter) processFiles() error {
 				_ = output.file.Close()
 				_ = os.Remove(output.file.Name())
 			}
+			for _, output := range e.premisFiles {
+				output.remove()
+			}
 			return fmt.Errorf("failed to process file %s: %w", manifestFile, err)
 		}
 	}
@@ -128,33 +200,16 @@ func (e *Exporter) processFile(obj files.S3Object) error {
 	defer func() { _ = file.Close() }()
 
 	_, err = ProcessExport(file, func(rec *ExportRecord) error {
-		// Use the bucket name from the record as the key
-		bucketName := rec.Item.BucketName.S
-		output, ok := e.outputFiles[bucketName]
-
-		if !ok {
-			// Create new temp file and writer for this bucket
-			csvFile, err := os.CreateTemp("", fmt.Sprintf("%s-*.csv", bucketName))
-			if err != nil {
-				return fmt.Errorf("failed to create temp CSV file: %w", err)
+		if e.writes(FormatCSV) {
+			if err := e.writeCSV(rec); err != nil {
+				return err
 			}
-
-			csvWriter := csv.NewWriter(csvFile)
-			if err := csvWriter.Write(ExportHeaders); err != nil {
-				_ = csvFile.Close()
-				_ = os.Remove(csvFile.Name())
-				return fmt.Errorf("failed to write CSV headers: %w", err)
-			}
-
-			output = &csvOutput{
-				file:   csvFile,
-				writer: csvWriter,
-			}
-			e.outputFiles[bucketName] = output
 		}
 
-		if err := output.writer.Write(rec.ToCSVRow()); err != nil {
-			return fmt.Errorf("failed to write CSV row: %w", err)
+		if e.writes(FormatPREMIS) {
+			if err := e.writePREMIS(rec); err != nil {
+				return err
+			}
 		}
 
 		return nil
@@ -167,47 +222,130 @@ func (e *Exporter) processFile(obj files.S3Object) error {
 	return nil
 }
 
+func (e *Exporter) writeCSV(rec *ExportRecord) error {
+	// Use the bucket name from the record as the key
+	bucketName := rec.Item.BucketName.S
+	output, ok := e.outputFiles[bucketName]
+
+	if !ok {
+		// Create new temp file and writer for this bucket
+		csvFile, err := os.CreateTemp("", fmt.Sprintf("%s-*.csv", bucketName))
+		if err != nil {
+			return fmt.Errorf("failed to create temp CSV file: %w", err)
+		}
+
+		csvWriter := csv.NewWriter(csvFile)
+		if err := csvWriter.Write(ExportHeaders); err != nil {
+			_ = csvFile.Close()
+			_ = os.Remove(csvFile.Name())
+			return fmt.Errorf("failed to write CSV headers: %w", err)
+		}
+
+		output = &csvOutput{
+			file:   csvFile,
+			writer: csvWriter,
+		}
+		e.outputFiles[bucketName] = output
+	}
+
+	if err := output.writer.Write(rec.ToCSVRow()); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Exporter) writePREMIS(rec *ExportRecord) error {
+	bucketName := rec.Item.BucketName.S
+	output, ok := e.premisFiles[bucketName]
+
+	if !ok {
+		var err error
+		output, err = newPREMISOutput(bucketName)
+		if err != nil {
+			return err
+		}
+		e.premisFiles[bucketName] = output
+	}
+
+	var events []db.FixityEvent
+	if e.history != nil {
+		history, err := e.history.History(rec.Object())
+		if err != nil {
+			return fmt.Errorf("failed to get fixity history for %s: %w", rec.Object().URI(), err)
+		}
+		events = history
+	}
+
+	// Records that predate the history table only have their latest fixity check
+	if len(events) == 0 {
+		events = []db.FixityEvent{rec.FixityEvent()}
+	}
+
+	return output.write(rec, events)
+}
+
 func (e *Exporter) uploadFiles() error {
 	defer func() {
 		for _, output := range e.outputFiles {
 			_ = os.Remove(output.file.Name())
 		}
+		for _, output := range e.premisFiles {
+			_ = os.Remove(output.file.Name())
+		}
 	}()
 
 	date := time.Now().UTC().Format("2006-01-02")
 	for bucket, output := range e.outputFiles {
 		uploadFilename := filepath.Join("exports", "checksum-table", date, "CSV", fmt.Sprintf("%s.csv", bucket))
-		tempFilePath := output.file.Name()
+		if err := e.uploadFile(bucket, output.file.Name(), uploadFilename, "CSV", "text/csv"); err != nil {
+			return err
+		}
+	}
 
-		uploadFile, err := os.Open(tempFilePath)
-		if err != nil {
-			return fmt.Errorf("failed to reopen CSV file: %w", err)
+	for bucket, output := range e.premisFiles {
+		uploadFilename := filepath.Join("exports", "checksum-table", date, "PREMIS", fmt.Sprintf("%s.xml", bucket))
+		if err := e.uploadFile(bucket, output.file.Name(), uploadFilename, "PREMIS", "application/xml"); err != nil {
+			return err
 		}
+	}
 
-		log.Printf("Uploading CSV Report: %s, Key: %s", e.manifest.Bucket, uploadFilename)
+	return nil
+}
 
-		uploadObj := files.NewS3Object(e.manifest.Bucket, uploadFilename)
-		err = files.UploadObject(e.ctx, e.s3Client, uploadObj, uploadFile, "text/csv")
-		_ = uploadFile.Close()
+func (e *Exporter) uploadFile(bucket, tempFilePath, uploadFilename, format, contentType string) error {
+	uploadFile, err := os.Open(tempFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to reopen %s file: %w", format, err)
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to upload CSV for %s to %s: %w", bucket, uploadFilename, err)
-		}
+	log.Printf("Uploading %s Report: %s, Key: %s", format, e.manifest.Bucket, uploadFilename)
+
+	uploadObj := files.NewS3Object(e.manifest.Bucket, uploadFilename)
+	err = files.UploadObject(e.ctx, e.s3Client, uploadObj, uploadFile, contentType)
+	_ = uploadFile.Close()
 
-		log.Printf("Successfully wrote CSV Report to S3: %s, Key: %s", bucket, uploadFilename)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s for %s to %s: %w", format, bucket, uploadFilename, err)
 	}
 
+	log.Printf("Successfully wrote %s Report to S3: %s, Key: %s", format, bucket, uploadFilename)
+
 	return nil
 }
 
 // ExportItem represents the fields in a DynamoDB export record
 type ExportItem struct {
-	BucketName          struct{ S string }  `json:"BucketName"`
-	ObjectKey           struct{ S string }  `json:"ObjectKey"`
-	Checksum            struct{ S string }  `json:"Checksum"`
-	LastChecksumSuccess struct{ BOOL bool } `json:"LastChecksumSuccess"`
-	LastChecksumDate    struct{ S string }  `json:"LastChecksumDate"`
-	LastChecksumMessage struct{ S string }  `json:"LastChecksumMessage"`
+	BucketName          struct{ S string }                        `json:"BucketName"`
+	ObjectKey           struct{ S string }                        `json:"ObjectKey"`
+	VersionId           struct{ S string }                        `json:"VersionId"`
+	Checksum            struct{ S string }                        `json:"Checksum"`
+	Checksums           struct{ M map[string]struct{ S string } } `json:"Checksums"`
+	LastChecksumSuccess struct{ BOOL bool }                       `json:"LastChecksumSuccess"`
+	LastChecksumDate    struct{ S string }                        `json:"LastChecksumDate"`
+	LastChecksumMessage struct{ S string }                        `json:"LastChecksumMessage"`
+	FailureCategory     struct{ S string }                        `json:"FailureCategory"`
+	ReplicaStatus       struct{ S string }                        `json:"ReplicaStatus"`
 }
 
 // ExportRecord represents a single record from the exports table
@@ -220,10 +358,15 @@ func (r *ExportRecord) ToCSVRow() []string {
 	return []string{
 		r.Item.BucketName.S,
 		r.Item.ObjectKey.S,
+		r.Item.VersionId.S,
 		r.Item.Checksum.S,
+		r.Item.Checksums.M[checksum.AlgorithmSHA256].S,
+		r.Item.Checksums.M[checksum.AlgorithmSHA512].S,
 		strconv.FormatBool(r.Item.LastChecksumSuccess.BOOL),
 		r.Item.LastChecksumDate.S,
 		r.Item.LastChecksumMessage.S,
+		r.Item.FailureCategory.S,
+		r.Item.ReplicaStatus.S,
 	}
 }
 
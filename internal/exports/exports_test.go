@@ -10,12 +10,21 @@ func TestExportRecord_ToCSVRow(t *testing.T) {
 	// Create a test record
 	record := &ExportRecord{
 		Item: ExportItem{
-			BucketName:          struct{ S string }{"test-bucket"},
-			ObjectKey:           struct{ S string }{"path/to/file.txt"},
-			Checksum:            struct{ S string }{"abc123def456"},
+			BucketName: struct{ S string }{"test-bucket"},
+			ObjectKey:  struct{ S string }{"path/to/file.txt"},
+			VersionId:  struct{ S string }{"3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY"},
+			Checksum:   struct{ S string }{"abc123def456"},
+			Checksums: struct{ M map[string]struct{ S string } }{
+				M: map[string]struct{ S string }{
+					"md5":    {"abc123def456"},
+					"sha256": {"sha256value"},
+					"sha512": {"sha512value"},
+				},
+			},
 			LastChecksumSuccess: struct{ BOOL bool }{true},
 			LastChecksumDate:    struct{ S string }{"2025-08-26T10:30:00Z"},
 			LastChecksumMessage: struct{ S string }{"Checksum verified successfully"},
+			ReplicaStatus:       struct{ S string }{"ok"},
 		},
 	}
 
@@ -36,8 +45,8 @@ func TestExportRecord_ToCSVRow(t *testing.T) {
 
 	// Verify the CSV content
 	expectedLines := []string{
-		"BucketName,ObjectKey,Checksum,LastChecksumSuccess,LastChecksumDate,LastChecksumMessage",
-		"test-bucket,path/to/file.txt,abc123def456,true,2025-08-26T10:30:00Z,Checksum verified successfully",
+		"BucketName,ObjectKey,VersionId,Checksum,ChecksumSHA256,ChecksumSHA512,LastChecksumSuccess,LastChecksumDate,LastChecksumMessage,FailureCategory,ReplicaStatus",
+		"test-bucket,path/to/file.txt,3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY,abc123def456,sha256value,sha512value,true,2025-08-26T10:30:00Z,Checksum verified successfully,,ok",
 	}
 
 	lines := strings.Split(strings.TrimSpace(csvOutput), "\n")
@@ -52,3 +61,30 @@ func TestExportRecord_ToCSVRow(t *testing.T) {
 		}
 	}
 }
+
+func TestExportRecord_ToCSVRowLegacyRecord(t *testing.T) {
+	// Records deposited before multiple algorithms were supported have no Checksums map
+	record := &ExportRecord{
+		Item: ExportItem{
+			BucketName:          struct{ S string }{"test-bucket"},
+			ObjectKey:           struct{ S string }{"legacy.txt"},
+			Checksum:            struct{ S string }{"abc123def456"},
+			LastChecksumSuccess: struct{ BOOL bool }{true},
+			LastChecksumDate:    struct{ S string }{"2025-08-26T10:30:00Z"},
+			LastChecksumMessage: struct{ S string }{"ok"},
+		},
+	}
+
+	row := record.ToCSVRow()
+	if len(row) != len(ExportHeaders) {
+		t.Fatalf("Expected %d columns, got %d", len(ExportHeaders), len(row))
+	}
+
+	if row[2] != "" {
+		t.Errorf("Expected empty VersionId column for legacy record, got %q", row[2])
+	}
+
+	if row[4] != "" || row[5] != "" {
+		t.Errorf("Expected empty SHA columns for legacy record, got %q and %q", row[4], row[5])
+	}
+}
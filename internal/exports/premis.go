@@ -0,0 +1,334 @@
+package exports
+
+import (
+	"duracloud/internal/checksum"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	PREMISNamespace = "http://www.loc.gov/premis/v3"
+	PREMISVersion   = "3.0"
+)
+
+// PREMIS event types (https://id.loc.gov/vocabulary/preservation/eventType)
+const (
+	PREMISEventDeletion                 = "deletion"
+	PREMISEventFixityCheck              = "fixity check"
+	PREMISEventMessageDigestCalculation = "message digest calculation"
+)
+
+const premisHeader = xml.Header + `<premis xmlns="` + PREMISNamespace +
+	`" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" version="` + PREMISVersion + `">` + "\n"
+
+const premisFooter = "\n</premis>\n"
+
+// premisAlgorithms maps algorithms to the PREMIS cryptographic hash function vocabulary,
+// other algorithms (the s3-* native checksums) are exported under their own name
+var premisAlgorithms = map[string]string{
+	checksum.AlgorithmMD5:    "MD5",
+	checksum.AlgorithmSHA256: "SHA-256",
+	checksum.AlgorithmSHA512: "SHA-512",
+}
+
+// HistorySource returns the fixity events of an object version, oldest first
+type HistorySource interface {
+	History(obj files.S3Object) ([]db.FixityEvent, error)
+}
+
+type premisObject struct {
+	XMLName         xml.Name                    `xml:"object"`
+	Type            string                      `xml:"xsi:type,attr"`
+	Identifier      premisObjectIdentifier      `xml:"objectIdentifier"`
+	Characteristics premisObjectCharacteristics `xml:"objectCharacteristics"`
+}
+
+type premisObjectIdentifier struct {
+	Type  string `xml:"objectIdentifierType"`
+	Value string `xml:"objectIdentifierValue"`
+}
+
+type premisObjectCharacteristics struct {
+	Fixity []premisFixity `xml:"fixity"`
+	Format premisFormat   `xml:"format"`
+}
+
+type premisFixity struct {
+	Algorithm string `xml:"messageDigestAlgorithm"`
+	Digest    string `xml:"messageDigest"`
+}
+
+type premisFormat struct {
+	Name string `xml:"formatDesignation>formatName"`
+}
+
+type premisEvent struct {
+	XMLName       xml.Name                      `xml:"event"`
+	Identifier    premisEventIdentifier         `xml:"eventIdentifier"`
+	Type          string                        `xml:"eventType"`
+	DateTime      string                        `xml:"eventDateTime"`
+	Detail        string                        `xml:"eventDetailInformation>eventDetail,omitempty"`
+	Outcome       premisEventOutcome            `xml:"eventOutcomeInformation"`
+	LinkingObject premisLinkingObjectIdentifier `xml:"linkingObjectIdentifier"`
+}
+
+type premisEventIdentifier struct {
+	Type  string `xml:"eventIdentifierType"`
+	Value string `xml:"eventIdentifierValue"`
+}
+
+type premisEventOutcome struct {
+	Outcome string                     `xml:"eventOutcome"`
+	Details []premisEventOutcomeDetail `xml:"eventOutcomeDetail"`
+}
+
+type premisEventOutcomeDetail struct {
+	Note string `xml:"eventOutcomeDetailNote"`
+}
+
+type premisLinkingObjectIdentifier struct {
+	Type  string `xml:"linkingObjectIdentifierType"`
+	Value string `xml:"linkingObjectIdentifierValue"`
+}
+
+// premisOutput writes the objects of a bucket to file and its events to a separate file,
+// PREMIS requires every object to precede the events so they are joined by finish
+type premisOutput struct {
+	file          *os.File
+	events        *os.File
+	objectEncoder *xml.Encoder
+	eventEncoder  *xml.Encoder
+}
+
+func newPREMISOutput(bucketName string) (*premisOutput, error) {
+	file, err := os.CreateTemp("", fmt.Sprintf("%s-*.xml", bucketName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp PREMIS file: %w", err)
+	}
+
+	events, err := os.CreateTemp("", fmt.Sprintf("%s-events-*.xml", bucketName))
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to create temp PREMIS events file: %w", err)
+	}
+
+	output := &premisOutput{
+		file:          file,
+		events:        events,
+		objectEncoder: xml.NewEncoder(file),
+		eventEncoder:  xml.NewEncoder(events),
+	}
+	output.objectEncoder.Indent("  ", "  ")
+	output.eventEncoder.Indent("  ", "  ")
+
+	if _, err := io.WriteString(file, premisHeader); err != nil {
+		output.remove()
+		return nil, fmt.Errorf("failed to write PREMIS header: %w", err)
+	}
+
+	return output, nil
+}
+
+// write adds the object of a record and its events
+func (o *premisOutput) write(rec *ExportRecord, events []db.FixityEvent) error {
+	if err := o.objectEncoder.Encode(newPREMISObject(rec)); err != nil {
+		return fmt.Errorf("failed to write PREMIS object: %w", err)
+	}
+
+	for _, event := range events {
+		if err := o.eventEncoder.Encode(newPREMISEvent(event)); err != nil {
+			return fmt.Errorf("failed to write PREMIS event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// finish appends the events and closes the document, file is then ready to upload
+func (o *premisOutput) finish() error {
+	if err := o.objectEncoder.Flush(); err != nil {
+		return fmt.Errorf("error flushing PREMIS objects: %w", err)
+	}
+
+	if err := o.eventEncoder.Flush(); err != nil {
+		return fmt.Errorf("error flushing PREMIS events: %w", err)
+	}
+
+	if _, err := o.events.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind PREMIS events: %w", err)
+	}
+
+	if _, err := io.WriteString(o.file, "\n"); err != nil {
+		return fmt.Errorf("failed to write PREMIS events: %w", err)
+	}
+
+	if _, err := io.Copy(o.file, o.events); err != nil {
+		return fmt.Errorf("failed to write PREMIS events: %w", err)
+	}
+
+	if _, err := io.WriteString(o.file, premisFooter); err != nil {
+		return fmt.Errorf("failed to write PREMIS footer: %w", err)
+	}
+
+	_ = o.file.Close()
+	_ = o.events.Close()
+	_ = os.Remove(o.events.Name())
+
+	return nil
+}
+
+func (o *premisOutput) remove() {
+	_ = o.file.Close()
+	_ = o.events.Close()
+	_ = os.Remove(o.file.Name())
+	_ = os.Remove(o.events.Name())
+}
+
+// Object returns the object version of the record
+func (r *ExportRecord) Object() files.S3Object {
+	return files.NewS3ObjectVersion(r.Item.BucketName.S, r.Item.ObjectKey.S, r.Item.VersionId.S)
+}
+
+// FixityEvent returns the latest fixity event of the record, it is used when there is no history
+func (r *ExportRecord) FixityEvent() db.FixityEvent {
+	date, _ := time.Parse(time.RFC3339, r.Item.LastChecksumDate.S)
+
+	return db.FixityEvent{
+		BucketName:      r.Item.BucketName.S,
+		ObjectKey:       r.Item.ObjectKey.S,
+		VersionId:       r.Item.VersionId.S,
+		EventDate:       date,
+		EventType:       db.FixityEventVerification,
+		Checksums:       r.checksums(),
+		Message:         r.Item.LastChecksumMessage.S,
+		Success:         r.Item.LastChecksumSuccess.BOOL,
+		FailureCategory: r.Item.FailureCategory.S,
+	}
+}
+
+// checksums returns the stored digests by algorithm, the primary checksum is md5
+func (r *ExportRecord) checksums() map[string]string {
+	checksums := make(map[string]string, len(r.Item.Checksums.M)+1)
+	for algorithm, value := range r.Item.Checksums.M {
+		checksums[algorithm] = value.S
+	}
+
+	if r.Item.Checksum.S != "" {
+		checksums[checksum.AlgorithmMD5] = r.Item.Checksum.S
+	}
+
+	return checksums
+}
+
+func newPREMISObject(rec *ExportRecord) premisObject {
+	checksums := rec.checksums()
+
+	fixity := make([]premisFixity, 0, len(checksums))
+	for _, algorithm := range sortedAlgorithms(checksums) {
+		fixity = append(fixity, premisFixity{
+			Algorithm: premisAlgorithm(algorithm),
+			Digest:    checksums[algorithm],
+		})
+	}
+
+	return premisObject{
+		Type: "file",
+		Identifier: premisObjectIdentifier{
+			Type:  "uri",
+			Value: rec.Object().URI(),
+		},
+		Characteristics: premisObjectCharacteristics{
+			Fixity: fixity,
+			Format: premisFormat{Name: "unknown"},
+		},
+	}
+}
+
+func newPREMISEvent(event db.FixityEvent) premisEvent {
+	obj := event.Object()
+	date := event.EventDate.UTC()
+
+	outcome := "success"
+	if !event.Success {
+		outcome = "fail"
+	}
+
+	var details []premisEventOutcomeDetail
+	if event.Message != "" {
+		details = append(details, premisEventOutcomeDetail{Note: event.Message})
+	}
+	if !event.Success && event.FailureCategory != "" {
+		details = append(details, premisEventOutcomeDetail{Note: "failure: " + event.FailureCategory})
+	}
+
+	// The values calculated by the event
+	algorithms := sortedAlgorithms(event.Checksums)
+	for _, algorithm := range algorithms {
+		details = append(details, premisEventOutcomeDetail{
+			Note: fmt.Sprintf("%s: %s", premisAlgorithm(algorithm), event.Checksums[algorithm]),
+		})
+	}
+
+	var detail string
+	if len(algorithms) > 0 {
+		names := make([]string, 0, len(algorithms))
+		for _, algorithm := range algorithms {
+			names = append(names, premisAlgorithm(algorithm))
+		}
+		detail = "algorithms: " + strings.Join(names, ", ")
+	}
+
+	return premisEvent{
+		Identifier: premisEventIdentifier{
+			Type:  "local",
+			Value: db.ObjectId(obj) + "@" + date.Format(db.EventDateFormat),
+		},
+		Type:     premisEventType(event.EventType),
+		DateTime: date.Format(time.RFC3339),
+		Detail:   detail,
+		Outcome: premisEventOutcome{
+			Outcome: outcome,
+			Details: details,
+		},
+		LinkingObject: premisLinkingObjectIdentifier{
+			Type:  "uri",
+			Value: obj.URI(),
+		},
+	}
+}
+
+func premisAlgorithm(algorithm string) string {
+	if name, ok := premisAlgorithms[algorithm]; ok {
+		return name
+	}
+	return algorithm
+}
+
+func premisEventType(eventType db.FixityEventType) string {
+	switch eventType {
+	case db.FixityEventDeletion:
+		return PREMISEventDeletion
+	case db.FixityEventDeposit:
+		return PREMISEventMessageDigestCalculation
+	default:
+		return PREMISEventFixityCheck
+	}
+}
+
+func sortedAlgorithms(checksums map[string]string) []string {
+	algorithms := make([]string, 0, len(checksums))
+	for algorithm := range checksums {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+
+	return algorithms
+}
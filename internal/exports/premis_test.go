@@ -0,0 +1,166 @@
+package exports
+
+import (
+	"duracloud/internal/db"
+	"encoding/xml"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testExportRecord(key, versionId string) *ExportRecord {
+	return &ExportRecord{
+		Item: ExportItem{
+			BucketName: struct{ S string }{"test-bucket"},
+			ObjectKey:  struct{ S string }{key},
+			VersionId:  struct{ S string }{versionId},
+			Checksum:   struct{ S string }{"abc123def456"},
+			Checksums: struct{ M map[string]struct{ S string } }{
+				M: map[string]struct{ S string }{
+					"md5":    {"abc123def456"},
+					"sha256": {"sha256value"},
+				},
+			},
+			LastChecksumSuccess: struct{ BOOL bool }{false},
+			LastChecksumDate:    struct{ S string }{"2025-08-26T10:30:00Z"},
+			LastChecksumMessage: struct{ S string }{"Checksum mismatch"},
+			FailureCategory:     struct{ S string }{"corrupted"},
+		},
+	}
+}
+
+func TestPREMISOutput(t *testing.T) {
+	output, err := newPREMISOutput("test-bucket")
+	if err != nil {
+		t.Fatalf("Failed to create output: %v", err)
+	}
+	defer output.remove()
+
+	deposited := time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)
+	first := testExportRecord("a.txt", "v1")
+	err = output.write(first, []db.FixityEvent{
+		{
+			BucketName: "test-bucket",
+			ObjectKey:  "a.txt",
+			VersionId:  "v1",
+			EventDate:  deposited,
+			EventType:  db.FixityEventDeposit,
+			Checksums:  map[string]string{"md5": "abc123def456", "sha256": "sha256value"},
+			Message:    "ok",
+			Success:    true,
+		},
+		first.FixityEvent(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to write first record: %v", err)
+	}
+
+	second := testExportRecord("b.txt", "")
+	if err := output.write(second, []db.FixityEvent{second.FixityEvent()}); err != nil {
+		t.Fatalf("Failed to write second record: %v", err)
+	}
+
+	if err := output.finish(); err != nil {
+		t.Fatalf("Failed to finish output: %v", err)
+	}
+
+	data, err := os.ReadFile(output.file.Name())
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	content := string(data)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.loc.gov/premis/v3 premis"`
+		Version string   `xml:"version,attr"`
+		Objects []struct {
+			Identifier string   `xml:"objectIdentifier>objectIdentifierValue"`
+			Algorithms []string `xml:"objectCharacteristics>fixity>messageDigestAlgorithm"`
+		} `xml:"object"`
+		Events []struct {
+			Type    string `xml:"eventType"`
+			Date    string `xml:"eventDateTime"`
+			Outcome string `xml:"eventOutcomeInformation>eventOutcome"`
+			Linking string `xml:"linkingObjectIdentifier>linkingObjectIdentifierValue"`
+		} `xml:"event"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, content)
+	}
+
+	if doc.Version != PREMISVersion {
+		t.Errorf("Expected version %s, got %s", PREMISVersion, doc.Version)
+	}
+
+	if len(doc.Objects) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(doc.Objects))
+	}
+	if doc.Objects[0].Identifier != "s3://test-bucket/a.txt?versionId=v1" {
+		t.Errorf("Unexpected object identifier: %s", doc.Objects[0].Identifier)
+	}
+	if strings.Join(doc.Objects[0].Algorithms, ",") != "MD5,SHA-256" {
+		t.Errorf("Unexpected fixity algorithms: %v", doc.Objects[0].Algorithms)
+	}
+
+	if len(doc.Events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(doc.Events))
+	}
+
+	expected := []struct {
+		eventType, date, outcome, linking string
+	}{
+		{PREMISEventMessageDigestCalculation, "2025-02-01T09:00:00Z", "success", "s3://test-bucket/a.txt?versionId=v1"},
+		{PREMISEventFixityCheck, "2025-08-26T10:30:00Z", "fail", "s3://test-bucket/a.txt?versionId=v1"},
+		{PREMISEventFixityCheck, "2025-08-26T10:30:00Z", "fail", "s3://test-bucket/b.txt"},
+	}
+	for i, want := range expected {
+		got := doc.Events[i]
+		if got.Type != want.eventType || got.Date != want.date || got.Outcome != want.outcome || got.Linking != want.linking {
+			t.Errorf("Event %d: got %+v, expected %+v", i, got, want)
+		}
+	}
+
+	if !strings.Contains(content, "<eventOutcomeDetailNote>failure: corrupted</eventOutcomeDetailNote>") {
+		t.Error("Expected the failure category in the outcome of a failed event")
+	}
+
+	// PREMIS requires every object to precede the events
+	if strings.LastIndex(content, "<object") > strings.Index(content, "<event>") {
+		t.Error("Expected objects before events")
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []ExportFormat
+		wantErr  bool
+	}{
+		{"", []ExportFormat{FormatCSV}, false},
+		{"csv", []ExportFormat{FormatCSV}, false},
+		{"csv, PREMIS", []ExportFormat{FormatCSV, FormatPREMIS}, false},
+		{"premis,premis", []ExportFormat{FormatPREMIS}, false},
+		{"json", nil, true},
+	}
+
+	for _, tt := range tests {
+		formats, err := ParseFormats(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormats(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+
+		if strings.Join(formatNames(formats), ",") != strings.Join(formatNames(tt.expected), ",") {
+			t.Errorf("ParseFormats(%q) = %v, expected %v", tt.value, formats, tt.expected)
+		}
+	}
+}
+
+func formatNames(formats []ExportFormat) []string {
+	names := make([]string, 0, len(formats))
+	for _, format := range formats {
+		names = append(names, string(format))
+	}
+	return names
+}
@@ -10,10 +10,14 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
-// S3Object represents an S3 object
+// NullVersionId is the S3 version id of objects stored while versioning was not enabled
+const NullVersionId = "null"
+
+// S3Object represents an S3 object, VersionId is empty for the current version
 type S3Object struct {
-	Bucket string
-	Key    string
+	Bucket    string
+	Key       string
+	VersionId string
 }
 
 // NewS3Object creates a new S3Object
@@ -21,16 +25,33 @@ func NewS3Object(bucket, key string) S3Object {
 	return S3Object{Bucket: bucket, Key: key}
 }
 
+// NewS3ObjectVersion creates a new S3Object for a specific version
+func NewS3ObjectVersion(bucket, key, versionId string) S3Object {
+	return S3Object{Bucket: bucket, Key: key, VersionId: versionId}
+}
+
 // URI returns a human-readable URI for the S3 object
 func (obj S3Object) URI() string {
+	if obj.VersionId != "" {
+		return fmt.Sprintf("s3://%s/%s?versionId=%s", obj.Bucket, obj.Key, obj.VersionId)
+	}
 	return fmt.Sprintf("s3://%s/%s", obj.Bucket, obj.Key)
 }
 
+// VersionIdInput returns the version id for S3 requests, nil targets the current version
+func (obj S3Object) VersionIdInput() *string {
+	if obj.VersionId == "" {
+		return nil
+	}
+	return aws.String(obj.VersionId)
+}
+
 // DownloadObject returns a streaming reader for S3 object with optional gzip decompression
 func DownloadObject(ctx context.Context, s3Client *s3.Client, obj S3Object, decompress bool) (io.ReadCloser, error) {
 	res, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(obj.Bucket),
-		Key:    aws.String(obj.Key),
+		Bucket:    aws.String(obj.Bucket),
+		Key:       aws.String(obj.Key),
+		VersionId: obj.VersionIdInput(),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object: %w", err)
@@ -52,8 +73,9 @@ func DownloadObject(ctx context.Context, s3Client *s3.Client, obj S3Object, deco
 // TryObject checks if an S3 object exists and can be accessed by performing a HeadObject operation.
 func TryObject(ctx context.Context, s3Client *s3.Client, obj S3Object) bool {
 	_, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(obj.Bucket),
-		Key:    aws.String(obj.Key),
+		Bucket:    aws.String(obj.Bucket),
+		Key:       aws.String(obj.Key),
+		VersionId: obj.VersionIdInput(),
 	})
 	return err == nil
 }
@@ -0,0 +1,19 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidInventory = errors.New("invalid inventory")
+	ErrNoInventory      = errors.New("no inventory found")
+)
+
+func ErrorInvalidInventory(line int, reason string) error {
+	return fmt.Errorf("%w: line=%d %s", ErrInvalidInventory, line, reason)
+}
+
+func ErrorNoInventory(bucket string) error {
+	return fmt.Errorf("%w: bucket=%s", ErrNoInventory, bucket)
+}
@@ -0,0 +1,375 @@
+package inventory
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// Statuses of an object version in an inventory reconciliation report
+const (
+	StatusMissingRecord = "missing-record" // in the bucket without a checksum record
+	StatusOrphanRecord  = "orphan-record"  // a checksum record of a version that is not in the bucket
+	StatusSizeMismatch  = "size-mismatch"  // the inventory size differs from the size in the record
+)
+
+// ReconciliationHeaders are the columns of an inventory reconciliation report
+var ReconciliationHeaders = []string{
+	"Status",
+	"Key",
+	"VersionId",
+	"InventorySize",
+	"RecordSize",
+}
+
+// RecordSource reads the checksum records of a bucket, db.DB implements it
+type RecordSource interface {
+	Get(obj files.S3Object) (db.ChecksumRecord, error)
+	Records(bucket string, fn func(db.ChecksumRecord) error) error
+}
+
+// InventoryObject is an object version listed in an inventory
+type InventoryObject struct {
+	Key       string
+	VersionId string
+	Size      int64
+}
+
+// Discrepancy is an object version the inventory and the checksum table disagree about, ETag
+// is the (current) etag of a version missing its record
+type Discrepancy struct {
+	Status        string
+	Key           string
+	VersionId     string
+	InventorySize int64
+	RecordSize    int64
+	ETag          string
+}
+
+// Reconciliation is the outcome of reconciling the inventory of a bucket with its checksum
+// records, Objects and Records count the versions in the inventory and in the table
+type Reconciliation struct {
+	Bucket        string
+	Inventory     files.S3Object
+	Date          time.Time
+	Objects       int
+	Records       int
+	Discrepancies []Discrepancy
+}
+
+// Count returns the number of discrepancies with a status
+func (r Reconciliation) Count(status string) int {
+	count := 0
+	for _, discrepancy := range r.Discrepancies {
+		if discrepancy.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Key returns where the report is written in the managed bucket
+func (r Reconciliation) Key() string {
+	return fmt.Sprintf("reports/inventory/%s/reconciliation-%s.csv", r.Bucket, r.Date.UTC().Format("20060102T150405Z"))
+}
+
+// MissingRecords returns the versions in the bucket without a checksum record
+func (r Reconciliation) MissingRecords() []Discrepancy {
+	var missing []Discrepancy
+	for _, discrepancy := range r.Discrepancies {
+		if discrepancy.Status == StatusMissingRecord {
+			missing = append(missing, discrepancy)
+		}
+	}
+	return missing
+}
+
+// WriteCSV writes the discrepancies of the report, the inventory size of an orphan record and
+// the size of a record deposited before sizes were stored are left empty
+func (r Reconciliation) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ReconciliationHeaders); err != nil {
+		return err
+	}
+
+	for _, d := range r.Discrepancies {
+		var inventorySize, recordSize string
+		if d.Status != StatusOrphanRecord {
+			inventorySize = strconv.FormatInt(d.InventorySize, 10)
+		}
+		if d.RecordSize > 0 {
+			recordSize = strconv.FormatInt(d.RecordSize, 10)
+		}
+
+		if err := writer.Write([]string{d.Status, d.Key, d.VersionId, inventorySize, recordSize}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// InventoryBuckets returns the buckets with inventories in the managed bucket
+func InventoryBuckets(ctx context.Context, s3Client s3.ListObjectsV2APIClient, managedBucket string) ([]string, error) {
+	var buckets []string
+
+	// Inventories are stored under inventory/{bucket}/
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(managedBucket),
+		Prefix:    aws.String("inventory/"),
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list inventory prefixes: %w", err)
+		}
+
+		for _, prefix := range page.CommonPrefixes {
+			bucket := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(prefix.Prefix), "inventory/"), "/")
+			if bucket != "" {
+				buckets = append(buckets, bucket)
+			}
+		}
+	}
+
+	return buckets, nil
+}
+
+// LatestInventory returns the most recent consolidated inventory CSV of a bucket
+func LatestInventory(ctx context.Context, s3Client s3.ListObjectsV2APIClient, managedBucket, bucket string) (files.S3Object, error) {
+	// Inventories are stored in: inventory/{bucket}/inventory/csv/inventory-{date}.csv
+	prefix := fmt.Sprintf("inventory/%s/inventory/csv/", bucket)
+
+	var latestKey string
+	paginator := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(managedBucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return files.S3Object{}, fmt.Errorf("failed to list inventories of %s: %w", bucket, err)
+		}
+
+		// Named by date so lexical order is chronological
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, ".csv") && key > latestKey {
+				latestKey = key
+			}
+		}
+	}
+
+	if latestKey == "" {
+		return files.S3Object{}, ErrorNoInventory(bucket)
+	}
+
+	return files.NewS3Object(managedBucket, latestKey), nil
+}
+
+// ReadInventory reads the object versions of a consolidated inventory CSV keyed by their
+// db.VersionKey, delete markers and prefixes are skipped
+func ReadInventory(r io.Reader) (map[string]InventoryObject, error) {
+	csvParser := csv.NewReader(r)
+
+	headers, err := csvParser.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(headers))
+	for i, header := range headers {
+		columns[header] = i
+	}
+	for _, required := range []string{"Key", "Size"} {
+		if _, ok := columns[required]; !ok {
+			return nil, ErrorInvalidInventory(1, fmt.Sprintf("missing %s column", required))
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	objects := make(map[string]InventoryObject)
+	for line := 2; ; line++ {
+		record, err := csvParser.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV record at line %d: %w", line, err)
+		}
+
+		if field(record, "IsDeleteMarker") == "true" {
+			continue
+		}
+
+		// Inventory keys are URL encoded
+		key, err := url.QueryUnescape(field(record, "Key"))
+		if err != nil {
+			return nil, ErrorInvalidInventory(line, fmt.Sprintf("invalid key: %v", err))
+		}
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		size, err := strconv.ParseInt(field(record, "Size"), 10, 64)
+		if err != nil {
+			return nil, ErrorInvalidInventory(line, fmt.Sprintf("invalid size: %v", err))
+		}
+
+		obj := InventoryObject{Key: key, VersionId: field(record, "VersionId"), Size: size}
+		objects[db.VersionKey(files.NewS3ObjectVersion("", obj.Key, obj.VersionId))] = obj
+	}
+
+	return objects, nil
+}
+
+// Reconcile compares the object versions in the inventory of a bucket with its checksum
+// records. A version found on only one side is checked again in S3 and the table before it
+// is reported, versions deposited or deleted since the inventory was taken agree.
+func Reconcile(
+	ctx context.Context,
+	s3Client s3.HeadObjectAPIClient,
+	records RecordSource,
+	bucket string,
+	objects map[string]InventoryObject,
+) (Reconciliation, error) {
+	report := Reconciliation{
+		Bucket:  bucket,
+		Date:    time.Now(),
+		Objects: len(objects),
+	}
+
+	matched := make(map[string]bool, len(objects))
+	err := records.Records(bucket, func(record db.ChecksumRecord) error {
+		report.Records++
+
+		versionKey := db.VersionKey(record.Object())
+		obj, ok := objects[versionKey]
+		if ok {
+			matched[versionKey] = true
+			if record.Size > 0 && record.Size != obj.Size {
+				report.Discrepancies = append(report.Discrepancies, Discrepancy{
+					Status:        StatusSizeMismatch,
+					Key:           record.ObjectKey,
+					VersionId:     record.VersionId,
+					InventorySize: obj.Size,
+					RecordSize:    record.Size,
+				})
+			}
+			return nil
+		}
+
+		head, err := headVersion(ctx, s3Client, record.Object())
+		if err != nil || head != nil {
+			// Deposited since the inventory was taken
+			return err
+		}
+
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			Status:     StatusOrphanRecord,
+			Key:        record.ObjectKey,
+			VersionId:  record.VersionId,
+			RecordSize: record.Size,
+		})
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to read checksum records of %s: %w", bucket, err)
+	}
+
+	unmatched := make([]string, 0, len(objects)-len(matched))
+	for versionKey := range objects {
+		if !matched[versionKey] {
+			unmatched = append(unmatched, versionKey)
+		}
+	}
+	sort.Strings(unmatched)
+
+	for _, versionKey := range unmatched {
+		obj := objects[versionKey]
+		version := files.NewS3ObjectVersion(bucket, obj.Key, obj.VersionId)
+
+		_, err := records.Get(version)
+		if err == nil {
+			// Deposited while the records were read
+			continue
+		}
+		if !errors.Is(err, db.ErrChecksumRecordNotFound) {
+			return report, err
+		}
+
+		head, err := headVersion(ctx, s3Client, version)
+		if err != nil {
+			return report, err
+		}
+		if head == nil {
+			// Deleted since the inventory was taken
+			continue
+		}
+
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			Status:        StatusMissingRecord,
+			Key:           obj.Key,
+			VersionId:     obj.VersionId,
+			InventorySize: obj.Size,
+			ETag:          aws.ToString(head.ETag),
+		})
+	}
+
+	sort.SliceStable(report.Discrepancies, func(i, j int) bool {
+		a, b := report.Discrepancies[i], report.Discrepancies[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.Key < b.Key
+	})
+
+	log.Printf("Reconciled inventory of %s: %d versions, %d records, %d discrepancies",
+		bucket, report.Objects, report.Records, len(report.Discrepancies))
+
+	return report, nil
+}
+
+// headVersion returns the metadata of an object version, nil when it does not exist
+func headVersion(ctx context.Context, s3Client s3.HeadObjectAPIClient, obj files.S3Object) (*s3.HeadObjectOutput, error) {
+	head, err := s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(obj.Bucket),
+		Key:       aws.String(obj.Key),
+		VersionId: obj.VersionIdInput(),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.ErrorCode() {
+			case "NoSuchKey", "NoSuchVersion", "NotFound":
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("failed to head %s: %w", obj.URI(), err)
+	}
+
+	return head, nil
+}
@@ -0,0 +1,134 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/db"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// mockHeadClient holds the etag of each object version that exists
+type mockHeadClient map[string]string
+
+func (m mockHeadClient) HeadObject(
+	_ context.Context,
+	params *s3.HeadObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.HeadObjectOutput, error) {
+	etag, ok := m[aws.ToString(params.Key)+"?"+aws.ToString(params.VersionId)]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NotFound"}
+	}
+	return &s3.HeadObjectOutput{ETag: aws.String(etag)}, nil
+}
+
+const testInventory = `Bucket,Key,VersionId,IsLatest,IsDeleteMarker,Size,LastModifiedDate,StorageClass
+"bucket","matched.txt","v1","true","false","10","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","images/","v1","true","false","0","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","images/Screen%20Recording.mp4","v2","true","false","20","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","images/Screen%20Recording.mp4","v1","false","false","15","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","deleted.txt","v2","true","true","","2025-10-23T21:17:07.000Z",""
+"bucket","deleted.txt","v1","false","false","5","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","resized.txt","v1","true","false","30","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","undeposited.txt","v1","true","false","40","2025-10-23T21:17:07.000Z","STANDARD"
+"bucket","purged.txt","v1","true","false","50","2025-10-23T21:17:07.000Z","STANDARD"
+`
+
+func TestReadInventory(t *testing.T) {
+	objects, err := ReadInventory(strings.NewReader(testInventory))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The prefix and the delete marker are skipped
+	if len(objects) != 7 {
+		t.Fatalf("Expected 7 versions, got %d: %v", len(objects), objects)
+	}
+
+	obj, ok := objects["images/Screen Recording.mp4#v1"]
+	if !ok || obj.Size != 15 || obj.VersionId != "v1" {
+		t.Errorf("Unexpected decoded version: %+v", obj)
+	}
+
+	if _, err := ReadInventory(strings.NewReader("Bucket,Key\n")); !errors.Is(err, ErrInvalidInventory) {
+		t.Errorf("Expected an inventory without sizes to be invalid, got %v", err)
+	}
+	if _, err := ReadInventory(strings.NewReader("Key,Size\nfile.txt,big\n")); !errors.Is(err, ErrInvalidInventory) {
+		t.Errorf("Expected an invalid size to be invalid, got %v", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	objects, err := ReadInventory(strings.NewReader(testInventory))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	store := db.NewMemoryStore()
+	for _, record := range []db.ChecksumRecord{
+		{ObjectKey: "matched.txt", VersionId: "v1", Size: 10},
+		{ObjectKey: "images/Screen Recording.mp4", VersionId: "v2"}, // deposited before sizes were stored
+		{ObjectKey: "images/Screen Recording.mp4", VersionId: "v1", Size: 15},
+		{ObjectKey: "deleted.txt", VersionId: "v1", Size: 5},
+		{ObjectKey: "resized.txt", VersionId: "v1", Size: 31},
+		{ObjectKey: "orphan.txt", VersionId: "v1", Size: 60},
+		{ObjectKey: "uploaded-since.txt", VersionId: "v1", Size: 70},
+	} {
+		record.BucketName = "bucket"
+		if err := store.Put(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	client := mockHeadClient{
+		"uploaded-since.txt?v1": `"etag-since"`,
+		"undeposited.txt?v1":    `"etag-undeposited"`,
+	}
+
+	report, err := Reconcile(context.Background(), client, store, "bucket", objects)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if report.Objects != 7 || report.Records != 7 {
+		t.Errorf("Expected 7 versions and 7 records, got %d and %d", report.Objects, report.Records)
+	}
+
+	expected := []Discrepancy{
+		{Status: StatusMissingRecord, Key: "undeposited.txt", VersionId: "v1", InventorySize: 40, ETag: `"etag-undeposited"`},
+		{Status: StatusOrphanRecord, Key: "orphan.txt", VersionId: "v1", RecordSize: 60},
+		{Status: StatusSizeMismatch, Key: "resized.txt", VersionId: "v1", InventorySize: 30, RecordSize: 31},
+	}
+	if len(report.Discrepancies) != len(expected) {
+		t.Fatalf("Expected %d discrepancies, got %+v", len(expected), report.Discrepancies)
+	}
+	for i, discrepancy := range report.Discrepancies {
+		if discrepancy != expected[i] {
+			t.Errorf("Discrepancy[%d] = %+v, expected %+v", i, discrepancy, expected[i])
+		}
+	}
+
+	if missing := report.MissingRecords(); len(missing) != 1 || missing[0].Key != "undeposited.txt" {
+		t.Errorf("Unexpected missing records: %+v", missing)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedCSV := `Status,Key,VersionId,InventorySize,RecordSize
+missing-record,undeposited.txt,v1,40,
+orphan-record,orphan.txt,v1,,60
+size-mismatch,resized.txt,v1,30,31
+`
+	if buf.String() != expectedCSV {
+		t.Errorf("Unexpected report:\n%s", buf.String())
+	}
+}
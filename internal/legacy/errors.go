@@ -0,0 +1,19 @@
+package legacy
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidHeader     = errors.New("invalid legacy import header")
+	ErrUnsupportedFormat = errors.New("unsupported legacy import format")
+)
+
+func ErrorInvalidHeader(missing string) error {
+	return fmt.Errorf("%w: missing=%s (expected columns %s)", ErrInvalidHeader, missing, "bucket, key, algorithm and checksum")
+}
+
+func ErrorUnsupportedFormat(key string) error {
+	return fmt.Errorf("%w: key=%s (expected .csv, .jsonl or .ndjson)", ErrUnsupportedFormat, key)
+}
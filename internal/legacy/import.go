@@ -0,0 +1,251 @@
+package legacy
+
+import (
+	"bufio"
+	"context"
+	"duracloud/internal/buckets"
+	"duracloud/internal/checksum"
+	"duracloud/internal/db"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Formats of a legacy fixity export
+const (
+	FormatCSV       = "csv"   // a header row naming the bucket, key, algorithm and checksum columns
+	FormatJSONLines = "jsonl" // an object with bucket, key, algorithm and checksum per line
+)
+
+// importReserve is the time kept back from the context deadline to report how far an import got
+const importReserve = 30 * time.Second
+
+// maxReportedErrors is the number of invalid rows described in a result, the rest are only counted
+const maxReportedErrors = 20
+
+// ImportPrefix is where legacy fixity exports are uploaded in the managed bucket
+const ImportPrefix = "imports/legacy/"
+
+// Row is a checksum of an object exported by the legacy repository
+type Row struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	Algorithm string `json:"algorithm"`
+	Checksum  string `json:"checksum"`
+}
+
+// Result counts the rows of an import. Rows are numbered from 1 (the CSV header is not a row),
+// NextRow is the row to start from when the import stopped before the context deadline.
+type Result struct {
+	Complete bool     `json:"complete"`
+	NextRow  int      `json:"nextRow,omitempty"`
+	Read     int      `json:"read"`
+	Staged   int      `json:"staged"`
+	Invalid  int      `json:"invalid"`
+	Late     int      `json:"late"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// FormatOf returns the format of an export from its file extension
+func FormatOf(key string) (string, error) {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONLines, nil
+	}
+	return "", ErrorUnsupportedFormat(key)
+}
+
+// Importer stages the checksums of a legacy fixity export until the objects are deposited, when
+// each deposit is compared with them
+type Importer struct {
+	ctx          context.Context
+	stager       db.LegacyStager
+	bucketPrefix string
+	source       string
+}
+
+// NewImporter stages checksums for the buckets of a stack, source is recorded with each checksum
+// (i.e. the key of the export)
+func NewImporter(ctx context.Context, stager db.LegacyStager, bucketPrefix, source string) *Importer {
+	return &Importer{
+		ctx:          ctx,
+		stager:       stager,
+		bucketPrefix: bucketPrefix,
+		source:       source,
+	}
+}
+
+// Import stages the rows of an export from startRow. Invalid rows are counted and the first few
+// reported, rows of objects that were deposited before they were imported are counted as late.
+// It stops before the context deadline, import again from NextRow to continue.
+func (i *Importer) Import(reader io.Reader, format string, startRow int) (Result, error) {
+	next, err := newRowReader(reader, format)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{}
+	for number := 1; ; number++ {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.As(err, new(*rowError)) {
+			return result, fmt.Errorf("failed to read row %d: %w", number, err)
+		}
+		if number < startRow {
+			continue
+		}
+
+		if i.stopping() {
+			result.NextRow = number
+			return result, nil
+		}
+		result.Read++
+
+		if err == nil {
+			err = i.stage(row)
+		}
+
+		switch {
+		case err == nil:
+			result.Staged++
+		case errors.Is(err, db.ErrLegacyChecksumClaimed):
+			result.Late++
+		case errors.As(err, new(*rowError)):
+			result.Invalid++
+			if len(result.Errors) < maxReportedErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", number, err))
+			}
+		default:
+			return result, fmt.Errorf("failed to stage row %d: %w", number, err)
+		}
+	}
+
+	result.Complete = true
+	return result, nil
+}
+
+// stage validates a row and stages its checksum under the bucket name of the stack
+func (i *Importer) stage(row Row) error {
+	bucket := strings.TrimSpace(row.Bucket)
+	if bucket == "" || row.Key == "" {
+		return &rowError{"bucket and key are required"}
+	}
+	if buckets.GetBucketPrefix(bucket) != i.bucketPrefix {
+		bucket = i.bucketPrefix + "-" + bucket
+	}
+
+	algorithm, value, ok := checksum.LegacyChecksum(row.Algorithm, row.Checksum)
+	if !ok {
+		return &rowError{fmt.Sprintf("invalid checksum: algorithm=%s checksum=%s", row.Algorithm, row.Checksum)}
+	}
+
+	return i.stager.StageLegacy(bucket, row.Key, algorithm, value, i.source)
+}
+
+func (i *Importer) stopping() bool {
+	deadline, ok := i.ctx.Deadline()
+	return ok && time.Until(deadline) < importReserve
+}
+
+// rowError is a row that cannot be staged, the import continues with the next row
+type rowError struct {
+	reason string
+}
+
+func (e *rowError) Error() string {
+	return e.reason
+}
+
+// newRowReader returns a function that reads the next row of an export, io.EOF after the last
+func newRowReader(reader io.Reader, format string) (func() (Row, error), error) {
+	switch format {
+	case FormatCSV:
+		return csvRowReader(reader)
+	case FormatJSONLines:
+		return jsonRowReader(reader), nil
+	}
+	return nil, ErrorUnsupportedFormat(format)
+}
+
+func csvRowReader(reader io.Reader) (func() (Row, error), error) {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy import header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for index, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = index
+	}
+	for _, name := range []string{"bucket", "key", "algorithm", "checksum"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrorInvalidHeader(name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if index := columns[name]; index < len(record) {
+			return record[index]
+		}
+		return ""
+	}
+
+	return func() (Row, error) {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return Row{}, io.EOF
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return Row{}, &rowError{parseErr.Err.Error()}
+			}
+			return Row{}, err
+		}
+
+		return Row{
+			Bucket:    field(record, "bucket"),
+			Key:       field(record, "key"),
+			Algorithm: field(record, "algorithm"),
+			Checksum:  field(record, "checksum"),
+		}, nil
+	}, nil
+}
+
+func jsonRowReader(reader io.Reader) func() (Row, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	return func() (Row, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			var row Row
+			if err := json.Unmarshal([]byte(line), &row); err != nil {
+				return Row{}, &rowError{fmt.Sprintf("invalid json: %v", err)}
+			}
+			return row, nil
+		}
+
+		if err := scanner.Err(); err != nil {
+			return Row{}, err
+		}
+		return Row{}, io.EOF
+	}
+}
@@ -0,0 +1,122 @@
+package legacy
+
+import (
+	"context"
+	"crypto/md5"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func digest(content string) []byte {
+	sum := md5.Sum([]byte(content))
+	return sum[:]
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]string{
+		"imports/legacy/space.csv":    FormatCSV,
+		"imports/legacy/space.CSV":    FormatCSV,
+		"imports/legacy/space.jsonl":  FormatJSONLines,
+		"imports/legacy/space.ndjson": FormatJSONLines,
+	}
+	for key, expected := range tests {
+		if format, err := FormatOf(key); err != nil || format != expected {
+			t.Errorf("Expected %s for %s, got %s (%v)", expected, key, format, err)
+		}
+	}
+
+	if _, err := FormatOf("imports/legacy/space.xml"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	export := "Key,Bucket,Algorithm,Checksum,Size\n" +
+		"file.txt,images,MD5," + hex.EncodeToString(digest("file")) + ",4\n" +
+		"file.txt,duracloud-test-images,md5," + hex.EncodeToString(digest("file")) + ",4\n" +
+		"other.txt,images,md5," + base64.StdEncoding.EncodeToString(digest("other")) + ",5\n" +
+		"bad.txt,images,crc32,abcd,4\n" +
+		",images,md5," + hex.EncodeToString(digest("none")) + ",0\n"
+
+	store := db.NewMemoryStore()
+	result, err := NewImporter(context.Background(), store, "duracloud-test", "space.csv").
+		Import(strings.NewReader(export), FormatCSV, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Complete || result.Read != 5 || result.Staged != 3 || result.Invalid != 2 || len(result.Errors) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if !strings.HasPrefix(result.Errors[0], "row 4:") {
+		t.Errorf("Expected the error to name its row, got %s", result.Errors[0])
+	}
+
+	legacy, err := store.ClaimLegacy(files.NewS3Object("duracloud-test-images", "other.txt"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if legacy.Checksums["md5"] != hex.EncodeToString(digest("other")) || legacy.Source != "space.csv" {
+		t.Errorf("Unexpected legacy record: %+v", legacy)
+	}
+
+	// The claimed object is deposited, importing it again is late
+	result, err = NewImporter(context.Background(), store, "duracloud-test", "space.csv").
+		Import(strings.NewReader(export), FormatCSV, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Read != 3 || result.Late != 1 || result.Staged != 0 {
+		t.Errorf("Unexpected resumed result: %+v", result)
+	}
+}
+
+func TestImportCSVHeader(t *testing.T) {
+	_, err := NewImporter(context.Background(), db.NewMemoryStore(), "duracloud-test", "space.csv").
+		Import(strings.NewReader("bucket,key,checksum\n"), FormatCSV, 1)
+	if !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("Expected ErrInvalidHeader, got %v", err)
+	}
+}
+
+func TestImportJSONLines(t *testing.T) {
+	export := `{"bucket": "images", "key": "file.txt", "algorithm": "md5", "checksum": "` + hex.EncodeToString(digest("file")) + `"}` + "\n" +
+		"\n" +
+		`{"bucket": "images", "key": "file.txt", "algorithm": "sha-256", "checksum": "not-a-digest"}` + "\n" +
+		"not json\n"
+
+	store := db.NewMemoryStore()
+	result, err := NewImporter(context.Background(), store, "duracloud-test", "space.jsonl").
+		Import(strings.NewReader(export), FormatJSONLines, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !result.Complete || result.Read != 3 || result.Staged != 1 || result.Invalid != 2 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if _, err := store.ClaimLegacy(files.NewS3Object("duracloud-test-images", "file.txt")); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestImportStopsBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), importReserve/2)
+	defer cancel()
+
+	export := "bucket,key,algorithm,checksum\nimages,file.txt,md5," + hex.EncodeToString(digest("file")) + "\n"
+	result, err := NewImporter(ctx, db.NewMemoryStore(), "duracloud-test", "space.csv").
+		Import(strings.NewReader(export), FormatCSV, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Complete || result.NextRow != 1 || result.Staged != 0 {
+		t.Errorf("Expected the import to stop before the first row, got %+v", result)
+	}
+}
@@ -0,0 +1,24 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidManifest      = errors.New("invalid manifest")
+	ErrUnsupportedAlgorithm = errors.New("unsupported manifest algorithm")
+	ErrUnsupportedManifest  = errors.New("unsupported manifest")
+)
+
+func ErrorInvalidManifest(name string, line int, reason string) error {
+	return fmt.Errorf("%w: manifest=%s line=%d %s", ErrInvalidManifest, name, line, reason)
+}
+
+func ErrorUnsupportedAlgorithm(name string, algorithm string) error {
+	return fmt.Errorf("%w: manifest=%s algorithm=%s", ErrUnsupportedAlgorithm, name, algorithm)
+}
+
+func ErrorUnsupportedManifest(name string) error {
+	return fmt.Errorf("%w: manifest=%s (expected manifest-<algorithm>.txt or .csv)", ErrUnsupportedManifest, name)
+}
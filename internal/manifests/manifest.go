@@ -0,0 +1,209 @@
+package manifests
+
+import (
+	"bufio"
+	"context"
+	"duracloud/internal/checksum"
+	"duracloud/internal/files"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Prefix is the reserved prefix of a bucket that manifests are uploaded to, a manifest
+// at Prefix/<path>/<name> describes the objects under <path>/
+const Prefix = "duracloud-manifests/"
+
+// Format identifies how a manifest is written
+type Format string
+
+const (
+	FormatBagIt Format = "bagit"
+	FormatCSV   Format = "csv"
+)
+
+// Entry is an object listed in a manifest with its expected checksum, Key is relative to the root
+type Entry struct {
+	Key       string
+	Algorithm string
+	Checksum  string
+}
+
+// Manifest is a list of the objects a depositor uploaded under Root
+type Manifest struct {
+	Object  files.S3Object
+	Format  Format
+	Root    string
+	Entries []Entry
+}
+
+// IsManifest reports whether an object key is under the reserved manifest prefix
+func IsManifest(key string) bool {
+	return strings.HasPrefix(key, Prefix) && !strings.HasSuffix(key, "/")
+}
+
+// RootPrefix returns the prefix of the objects described by a manifest, empty for the whole bucket
+func RootPrefix(key string) string {
+	dir := path.Dir(strings.TrimPrefix(key, Prefix))
+	if dir == "." {
+		return ""
+	}
+	return dir + "/"
+}
+
+// Load downloads and parses a manifest
+func Load(ctx context.Context, s3Client *s3.Client, obj files.S3Object) (Manifest, error) {
+	body, err := files.DownloadObject(ctx, s3Client, obj, false)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	return Parse(obj, body)
+}
+
+// Parse reads a BagIt manifest (manifest-<algorithm>.txt) or a CSV manifest (*.csv)
+func Parse(obj files.S3Object, r io.Reader) (Manifest, error) {
+	name := path.Base(obj.Key)
+	manifest := Manifest{
+		Object: obj,
+		Root:   RootPrefix(obj.Key),
+	}
+
+	var err error
+	switch {
+	case strings.HasPrefix(name, "manifest-") && strings.HasSuffix(name, ".txt"):
+		manifest.Format = FormatBagIt
+		algorithm := strings.TrimSuffix(strings.TrimPrefix(name, "manifest-"), ".txt")
+		manifest.Entries, err = parseBagIt(name, algorithm, r)
+	case strings.HasSuffix(strings.ToLower(name), ".csv"):
+		manifest.Format = FormatCSV
+		manifest.Entries, err = parseCSV(name, r)
+	default:
+		err = ErrorUnsupportedManifest(name)
+	}
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// parseBagIt reads "<checksum> <path>" lines, paths may percent-encode CR, LF and %
+func parseBagIt(name string, algorithm string, r io.Reader) ([]Entry, error) {
+	algorithm = strings.ToLower(algorithm)
+	if !slices.Contains(checksum.DefaultAlgorithms, algorithm) {
+		return nil, ErrorUnsupportedAlgorithm(name, algorithm)
+	}
+
+	decoder := strings.NewReplacer("%0A", "\n", "%0a", "\n", "%0D", "\r", "%0d", "\r", "%25", "%")
+
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		value, key, ok := strings.Cut(strings.TrimLeft(text, " \t"), " ")
+		key = strings.TrimLeft(key, " \t")
+		if !ok || key == "" {
+			return nil, ErrorInvalidManifest(name, line, "expected a checksum and a path")
+		}
+
+		entries = append(entries, Entry{
+			Key:       decoder.Replace(key),
+			Algorithm: algorithm,
+			Checksum:  strings.ToLower(value),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, ErrorInvalidManifest(name, 0, err.Error())
+	}
+
+	return entries, nil
+}
+
+// parseCSV reads a CSV with a header of "key" and one or more algorithm columns (md5, sha256,
+// sha512), an entry is added for every algorithm with a value
+func parseCSV(name string, r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ErrorInvalidManifest(name, 1, err.Error())
+	}
+
+	keyColumn := -1
+	algorithms := make(map[int]string)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch {
+		case column == "key":
+			keyColumn = i
+		case slices.Contains(checksum.DefaultAlgorithms, column):
+			algorithms[i] = column
+		}
+	}
+	if keyColumn < 0 || len(algorithms) == 0 {
+		return nil, ErrorInvalidManifest(name, 1,
+			fmt.Sprintf("expected a key column and one of %s", strings.Join(checksum.DefaultAlgorithms, ", ")))
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrorInvalidManifest(name, line, err.Error())
+		}
+		if keyColumn >= len(record) || record[keyColumn] == "" {
+			return nil, ErrorInvalidManifest(name, line, "missing key")
+		}
+
+		for i, algorithm := range algorithms {
+			if i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+			entries = append(entries, Entry{
+				Key:       record[keyColumn],
+				Algorithm: algorithm,
+				Checksum:  strings.ToLower(strings.TrimSpace(record[i])),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// isTagFile reports whether a key relative to the root of a bag is a BagIt tag file, those
+// are not listed in the payload manifest
+func isTagFile(key string) bool {
+	if strings.Contains(key, "/") {
+		return false
+	}
+
+	switch {
+	case key == "bagit.txt", key == "bag-info.txt", key == "fetch.txt":
+		return true
+	case strings.HasPrefix(key, "manifest-"), strings.HasPrefix(key, "tagmanifest-"):
+		return strings.HasSuffix(key, ".txt")
+	}
+	return false
+}
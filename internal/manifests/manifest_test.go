@@ -0,0 +1,141 @@
+package manifests
+
+import (
+	"duracloud/internal/files"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIsManifest(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"duracloud-manifests/manifest-sha256.txt", true},
+		{"duracloud-manifests/batch-1/files.csv", true},
+		{"duracloud-manifests/batch-1/", false},
+		{"batch-1/manifest-sha256.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := IsManifest(tt.key); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRootPrefix(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"duracloud-manifests/manifest-sha256.txt", ""},
+		{"duracloud-manifests/batch-1/files.csv", "batch-1/"},
+		{"duracloud-manifests/2025/batch-1/manifest-md5.txt", "2025/batch-1/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := RootPrefix(tt.key); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_BagIt(t *testing.T) {
+	content := "ABC123  data/file one.txt\r\n" +
+		"\n" +
+		"def456 data/line%0Abreak%25.txt\n"
+	obj := files.NewS3Object("test-bucket", "duracloud-manifests/bag-1/manifest-sha256.txt")
+
+	manifest, err := Parse(obj, strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if manifest.Format != FormatBagIt {
+		t.Errorf("expected format %s, got %s", FormatBagIt, manifest.Format)
+	}
+	if manifest.Root != "bag-1/" {
+		t.Errorf("expected root bag-1/, got %s", manifest.Root)
+	}
+
+	expected := []Entry{
+		{Key: "data/file one.txt", Algorithm: "sha256", Checksum: "abc123"},
+		{Key: "data/line\nbreak%.txt", Algorithm: "sha256", Checksum: "def456"},
+	}
+	if len(manifest.Entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %v", len(expected), manifest.Entries)
+	}
+	for i, entry := range expected {
+		if manifest.Entries[i] != entry {
+			t.Errorf("expected entry %d to be %+v, got %+v", i, entry, manifest.Entries[i])
+		}
+	}
+}
+
+func TestParse_CSV(t *testing.T) {
+	content := "\ufeffKey,MD5,SHA256\n" +
+		"a.txt,AAA,bbb\n" +
+		"b.txt,,ccc\n"
+	obj := files.NewS3Object("test-bucket", "duracloud-manifests/files.csv")
+
+	manifest, err := Parse(obj, strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if manifest.Format != FormatCSV {
+		t.Errorf("expected format %s, got %s", FormatCSV, manifest.Format)
+	}
+	if manifest.Root != "" {
+		t.Errorf("expected an empty root, got %s", manifest.Root)
+	}
+
+	checksums := make(map[string]string)
+	for _, entry := range manifest.Entries {
+		checksums[entry.Key+" "+entry.Algorithm] = entry.Checksum
+	}
+	expected := map[string]string{
+		"a.txt md5":    "aaa",
+		"a.txt sha256": "bbb",
+		"b.txt sha256": "ccc",
+	}
+	if len(checksums) != len(expected) {
+		t.Fatalf("expected %d entries, got %v", len(expected), manifest.Entries)
+	}
+	for entry, value := range expected {
+		if checksums[entry] != value {
+			t.Errorf("expected %s=%s, got %s", entry, value, checksums[entry])
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		content  string
+		expected error
+	}{
+		{"unsupported manifest", "duracloud-manifests/files.json", "{}", ErrUnsupportedManifest},
+		{"unsupported algorithm", "duracloud-manifests/manifest-sha1.txt", "abc data/a.txt\n", ErrUnsupportedAlgorithm},
+		{"bagit line without path", "duracloud-manifests/manifest-md5.txt", "abc\n", ErrInvalidManifest},
+		{"csv without key column", "duracloud-manifests/files.csv", "path,md5\na.txt,abc\n", ErrInvalidManifest},
+		{"csv without algorithm column", "duracloud-manifests/files.csv", "key,crc32\na.txt,abc\n", ErrInvalidManifest},
+		{"csv row without key", "duracloud-manifests/files.csv", "key,md5\n,abc\n", ErrInvalidManifest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(files.NewS3Object("test-bucket", tt.key), strings.NewReader(tt.content))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package manifests
+
+import (
+	"context"
+	"duracloud/internal/checksum"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ReconcileAttempts is the number of times a manifest is reconciled while some of its objects
+// have not been deposited yet, the last attempt reports them as pending
+const ReconcileAttempts = 12
+
+// Statuses of an object in a reconciliation report
+const (
+	StatusExtra      = "extra"      // in the bucket but not in the manifest
+	StatusMatched    = "matched"    // deposited with the checksums in the manifest
+	StatusMismatched = "mismatched" // deposited with a checksum that does not match the manifest
+	StatusMissing    = "missing"    // in the manifest but not in the bucket
+	StatusPending    = "pending"    // in the bucket but not deposited (yet)
+)
+
+// ReportHeaders are the columns of a reconciliation report
+var ReportHeaders = []string{
+	"Status",
+	"Key",
+	"VersionId",
+	"Algorithm",
+	"Expected",
+	"Actual",
+}
+
+// RecordGetter returns the checksum record of an object version, db.DB implements it
+type RecordGetter interface {
+	Get(obj files.S3Object) (db.ChecksumRecord, error)
+}
+
+// Discrepancy is an object whose deposit does not agree with the manifest, Key is the object key
+type Discrepancy struct {
+	Status    string
+	Key       string
+	VersionId string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+// Report is the outcome of reconciling a manifest with the deposits of its objects, Objects
+// is the number of objects in the manifest
+type Report struct {
+	Manifest      files.S3Object
+	Root          string
+	Date          time.Time
+	Objects       int
+	Matched       int
+	Discrepancies []Discrepancy
+}
+
+// Count returns the number of discrepancies with a status
+func (r Report) Count(status string) int {
+	count := 0
+	for _, discrepancy := range r.Discrepancies {
+		if discrepancy.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Key returns where the report is written in the managed bucket
+func (r Report) Key() string {
+	return fmt.Sprintf("reports/manifests/%s/%s-%s.csv",
+		r.Manifest.Bucket, strings.TrimPrefix(r.Manifest.Key, Prefix), r.Date.UTC().Format("20060102T150405Z"))
+}
+
+// WriteCSV writes the discrepancies of the report, matched objects are only counted
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ReportHeaders); err != nil {
+		return err
+	}
+
+	for _, d := range r.Discrepancies {
+		if err := writer.Write([]string{d.Status, d.Key, d.VersionId, d.Algorithm, d.Expected, d.Actual}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Reconcile compares every object in a manifest with the checksum record of its current version
+// and lists the objects under the root of the manifest that it does not include
+func Reconcile(
+	ctx context.Context,
+	s3Client s3.ListObjectVersionsAPIClient,
+	records RecordGetter,
+	manifest Manifest,
+) (Report, error) {
+	bucket := manifest.Object.Bucket
+	report := Report{
+		Manifest: manifest.Object,
+		Root:     manifest.Root,
+		Date:     time.Now(),
+	}
+
+	current, err := currentVersions(ctx, s3Client, bucket, manifest.Root)
+	if err != nil {
+		return report, err
+	}
+
+	expected := make(map[string][]Entry)
+	for _, entry := range manifest.Entries {
+		key := manifest.Root + entry.Key
+		expected[key] = append(expected[key], entry)
+	}
+	report.Objects = len(expected)
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		discrepancies, err := reconcileObject(records, bucket, key, current, expected[key])
+		if err != nil {
+			return report, err
+		}
+
+		if len(discrepancies) == 0 {
+			report.Matched++
+		}
+		report.Discrepancies = append(report.Discrepancies, discrepancies...)
+	}
+
+	for key, versionId := range current {
+		if _, ok := expected[key]; ok {
+			continue
+		}
+		if manifest.Format == FormatBagIt && isTagFile(strings.TrimPrefix(key, manifest.Root)) {
+			continue
+		}
+
+		report.Discrepancies = append(report.Discrepancies, Discrepancy{
+			Status:    StatusExtra,
+			Key:       key,
+			VersionId: versionId,
+		})
+	}
+
+	sort.SliceStable(report.Discrepancies, func(i, j int) bool {
+		a, b := report.Discrepancies[i], report.Discrepancies[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		return a.Key < b.Key
+	})
+
+	log.Printf("Reconciled %s: %d objects, %d matched, %d discrepancies",
+		manifest.Object.URI(), report.Objects, report.Matched, len(report.Discrepancies))
+
+	return report, nil
+}
+
+// reconcileObject compares the entries of an object with the checksum record of its current version
+func reconcileObject(
+	records RecordGetter,
+	bucket string,
+	key string,
+	current map[string]string,
+	entries []Entry,
+) ([]Discrepancy, error) {
+	versionId, ok := current[key]
+	if !ok {
+		return []Discrepancy{{
+			Status:    StatusMissing,
+			Key:       key,
+			Algorithm: entries[0].Algorithm,
+			Expected:  entries[0].Checksum,
+		}}, nil
+	}
+
+	record, err := records.Get(files.NewS3ObjectVersion(bucket, key, versionId))
+	if errors.Is(err, db.ErrChecksumRecordNotFound) {
+		return []Discrepancy{{
+			Status:    StatusPending,
+			Key:       key,
+			VersionId: versionId,
+			Algorithm: entries[0].Algorithm,
+			Expected:  entries[0].Checksum,
+		}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := checksum.StoredChecksums(record)
+
+	var discrepancies []Discrepancy
+	for _, entry := range entries {
+		actual := stored[entry.Algorithm]
+		status := StatusMismatched
+		switch {
+		case actual == "":
+			// A deposit that was handed off or could not read the object has no checksum yet
+			status = StatusPending
+		case actual == entry.Checksum:
+			continue
+		}
+
+		discrepancies = append(discrepancies, Discrepancy{
+			Status:    status,
+			Key:       key,
+			VersionId: versionId,
+			Algorithm: entry.Algorithm,
+			Expected:  entry.Checksum,
+			Actual:    actual,
+		})
+	}
+
+	return discrepancies, nil
+}
+
+// currentVersions returns the version id of every current object under a prefix, manifests
+// and deleted objects are excluded
+func currentVersions(
+	ctx context.Context,
+	s3Client s3.ListObjectVersionsAPIClient,
+	bucket string,
+	prefix string,
+) (map[string]string, error) {
+	current := make(map[string]string)
+
+	paginator := s3.NewListObjectVersionsPaginator(s3Client, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of s3://%s/%s: %w", bucket, prefix, err)
+		}
+
+		for _, version := range page.Versions {
+			key := aws.ToString(version.Key)
+			if !aws.ToBool(version.IsLatest) || IsManifest(key) || strings.HasSuffix(key, "/") {
+				continue
+			}
+			current[key] = aws.ToString(version.VersionId)
+		}
+	}
+
+	return current, nil
+}
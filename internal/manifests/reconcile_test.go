@@ -0,0 +1,186 @@
+package manifests
+
+import (
+	"bytes"
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type mockListClient struct {
+	versions []types.ObjectVersion
+}
+
+func (m *mockListClient) ListObjectVersions(
+	_ context.Context,
+	params *s3.ListObjectVersionsInput,
+	_ ...func(*s3.Options),
+) (*s3.ListObjectVersionsOutput, error) {
+	var versions []types.ObjectVersion
+	for _, version := range m.versions {
+		if strings.HasPrefix(aws.ToString(version.Key), aws.ToString(params.Prefix)) {
+			versions = append(versions, version)
+		}
+	}
+	return &s3.ListObjectVersionsOutput{Versions: versions}, nil
+}
+
+func (m *mockListClient) addVersion(key string, versionId string, latest bool) {
+	m.versions = append(m.versions, types.ObjectVersion{
+		Key:       aws.String(key),
+		VersionId: aws.String(versionId),
+		IsLatest:  aws.Bool(latest),
+	})
+}
+
+type mockRecords map[string]db.ChecksumRecord
+
+func (m mockRecords) Get(obj files.S3Object) (db.ChecksumRecord, error) {
+	record, ok := m[obj.Key+"?"+obj.VersionId]
+	if !ok {
+		return db.ChecksumRecord{}, db.ErrorChecksumRecordNotFound(obj.Bucket, obj.Key)
+	}
+	return record, nil
+}
+
+func TestReconcile(t *testing.T) {
+	client := &mockListClient{}
+	client.addVersion("batch-1/matched.txt", "v2", true)
+	client.addVersion("batch-1/matched.txt", "v1", false)
+	client.addVersion("batch-1/mismatched.txt", "v1", true)
+	client.addVersion("batch-1/pending.txt", "v1", true)
+	client.addVersion("batch-1/unchecked.txt", "v1", true)
+	client.addVersion("batch-1/extra.txt", "v1", true)
+	client.addVersion("batch-2/other.txt", "v1", true)
+
+	records := mockRecords{
+		"batch-1/matched.txt?v2":    {Checksums: map[string]string{"md5": "aaa", "sha256": "bbb"}},
+		"batch-1/mismatched.txt?v1": {Checksums: map[string]string{"sha256": "ccc"}},
+		"batch-1/unchecked.txt?v1":  {LastChecksumMessage: "handed off"},
+	}
+
+	manifest := Manifest{
+		Object: files.NewS3Object("test-bucket", "duracloud-manifests/batch-1/files.csv"),
+		Format: FormatCSV,
+		Root:   "batch-1/",
+		Entries: []Entry{
+			{Key: "matched.txt", Algorithm: "md5", Checksum: "aaa"},
+			{Key: "matched.txt", Algorithm: "sha256", Checksum: "bbb"},
+			{Key: "mismatched.txt", Algorithm: "sha256", Checksum: "ddd"},
+			{Key: "pending.txt", Algorithm: "sha256", Checksum: "eee"},
+			{Key: "unchecked.txt", Algorithm: "sha256", Checksum: "fff"},
+			{Key: "missing.txt", Algorithm: "sha256", Checksum: "ggg"},
+		},
+	}
+
+	report, err := Reconcile(context.Background(), client, records, manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Objects != 5 {
+		t.Errorf("expected 5 objects, got %d", report.Objects)
+	}
+	if report.Matched != 1 {
+		t.Errorf("expected 1 matched object, got %d", report.Matched)
+	}
+
+	expected := []Discrepancy{
+		{Status: StatusExtra, Key: "batch-1/extra.txt", VersionId: "v1"},
+		{Status: StatusMismatched, Key: "batch-1/mismatched.txt", VersionId: "v1", Algorithm: "sha256", Expected: "ddd", Actual: "ccc"},
+		{Status: StatusMissing, Key: "batch-1/missing.txt", Algorithm: "sha256", Expected: "ggg"},
+		{Status: StatusPending, Key: "batch-1/pending.txt", VersionId: "v1", Algorithm: "sha256", Expected: "eee"},
+		{Status: StatusPending, Key: "batch-1/unchecked.txt", VersionId: "v1", Algorithm: "sha256", Expected: "fff"},
+	}
+	if len(report.Discrepancies) != len(expected) {
+		t.Fatalf("expected %d discrepancies, got %+v", len(expected), report.Discrepancies)
+	}
+	for i, discrepancy := range expected {
+		if report.Discrepancies[i] != discrepancy {
+			t.Errorf("expected discrepancy %d to be %+v, got %+v", i, discrepancy, report.Discrepancies[i])
+		}
+	}
+
+	if report.Count(StatusPending) != 2 {
+		t.Errorf("expected 2 pending objects, got %d", report.Count(StatusPending))
+	}
+}
+
+func TestReconcile_BagItTagFiles(t *testing.T) {
+	client := &mockListClient{}
+	client.addVersion("bag-1/bagit.txt", "v1", true)
+	client.addVersion("bag-1/manifest-sha256.txt", "v1", true)
+	client.addVersion("bag-1/data/a.txt", "v1", true)
+	client.addVersion("bag-1/data/bagit.txt", "v1", true)
+
+	records := mockRecords{
+		"bag-1/data/a.txt?v1": {Checksums: map[string]string{"sha256": "aaa"}},
+	}
+
+	manifest := Manifest{
+		Object:  files.NewS3Object("test-bucket", "duracloud-manifests/bag-1/manifest-sha256.txt"),
+		Format:  FormatBagIt,
+		Root:    "bag-1/",
+		Entries: []Entry{{Key: "data/a.txt", Algorithm: "sha256", Checksum: "aaa"}},
+	}
+
+	report, err := Reconcile(context.Background(), client, records, manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Discrepancies) != 1 || report.Discrepancies[0].Key != "bag-1/data/bagit.txt" {
+		t.Errorf("expected only the payload bagit.txt to be extra, got %+v", report.Discrepancies)
+	}
+}
+
+func TestReconcile_RecordError(t *testing.T) {
+	client := &mockListClient{}
+	client.addVersion("a.txt", "v1", true)
+
+	manifest := Manifest{
+		Object:  files.NewS3Object("test-bucket", "duracloud-manifests/files.csv"),
+		Format:  FormatCSV,
+		Entries: []Entry{{Key: "a.txt", Algorithm: "md5", Checksum: "aaa"}},
+	}
+
+	failing := errors.New("throttled")
+	_, err := Reconcile(context.Background(), client, failingRecords{failing}, manifest)
+	if !errors.Is(err, failing) {
+		t.Errorf("expected %v, got %v", failing, err)
+	}
+}
+
+type failingRecords struct {
+	err error
+}
+
+func (f failingRecords) Get(_ files.S3Object) (db.ChecksumRecord, error) {
+	return db.ChecksumRecord{}, f.err
+}
+
+func TestReport_WriteCSV(t *testing.T) {
+	report := Report{
+		Discrepancies: []Discrepancy{
+			{Status: StatusMissing, Key: "a,b.txt", Algorithm: "md5", Expected: "aaa"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Status,Key,VersionId,Algorithm,Expected,Actual\n" +
+		"missing,\"a,b.txt\",,md5,aaa,\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -18,15 +18,21 @@ type SNSNotification interface {
 }
 
 type ChecksumFailureNotification struct {
-	Account      string
-	Bucket       string
-	Object       string
-	Date         string
-	ErrorMessage string
-	Stack        string
-	Title        string
-	Template     *template.Template
-	Topic        string
+	Account        string
+	Bucket         string
+	Checksums      map[string]string
+	Object         string
+	Date           string
+	Diverged       string
+	ErrorMessage   string
+	Failure        string
+	Replica        string
+	ReplicaMessage string
+	Stack          string
+	Title          string
+	Template       *template.Template
+	Topic          string
+	VersionId      string
 }
 
 func (n ChecksumFailureNotification) Message() (string, error) {
@@ -45,6 +51,162 @@ func (n ChecksumFailureNotification) TopicArn() string {
 	return n.Topic
 }
 
+// ChecksumRepairNotification reports the repair of a corrupted primary from its replica
+type ChecksumRepairNotification struct {
+	Account         string
+	Bucket          string
+	Object          string
+	Date            string
+	RepairMessage   string
+	RepairVersionId string
+	Replica         string
+	Stack           string
+	Status          string
+	Title           string
+	Template        *template.Template
+	Topic           string
+	VersionId       string
+}
+
+func (n ChecksumRepairNotification) Message() (string, error) {
+	var buf bytes.Buffer
+	if err := n.Template.Execute(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (n ChecksumRepairNotification) Subject() string {
+	return n.Title
+}
+
+func (n ChecksumRepairNotification) TopicArn() string {
+	return n.Topic
+}
+
+// ChecksumSweepNotification reports the checksum records found without a scheduler entry in each
+// bucket when there are more orphans than the alert threshold
+type ChecksumSweepNotification struct {
+	Account   string
+	Buckets   []ChecksumSweepSummary
+	Date      string
+	Orphans   int
+	Recheck   string
+	Stack     string
+	Threshold int
+	Title     string
+	Template  *template.Template
+	Topic     string
+}
+
+// ChecksumSweepSummary is the outcome of sweeping the checksum records of a bucket, NextKey is set
+// when the sweep stopped before the end of the bucket and Error when it could not be swept
+type ChecksumSweepSummary struct {
+	Bucket      string
+	Error       string
+	NextKey     string
+	Orphans     int
+	Rechecked   int
+	Records     int
+	Unscheduled int
+}
+
+func (n ChecksumSweepNotification) Message() (string, error) {
+	var buf bytes.Buffer
+	if err := n.Template.Execute(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (n ChecksumSweepNotification) Subject() string {
+	return n.Title
+}
+
+func (n ChecksumSweepNotification) TopicArn() string {
+	return n.Topic
+}
+
+// InventoryReconciliationNotification reports the discrepancies between the inventory of each
+// bucket and its checksum records
+type InventoryReconciliationNotification struct {
+	Account  string
+	Buckets  []InventoryReconciliationSummary
+	Date     string
+	Stack    string
+	Title    string
+	Template *template.Template
+	Topic    string
+}
+
+// InventoryReconciliationSummary is the outcome of reconciling the inventory of a bucket, Error
+// is set when it could not be reconciled
+type InventoryReconciliationSummary struct {
+	Bucket         string
+	Enqueued       int
+	Error          string
+	Inventory      string
+	MissingRecords int
+	Objects        int
+	OrphanRecords  int
+	Records        int
+	Report         string
+	SizeMismatches int
+}
+
+func (n InventoryReconciliationNotification) Message() (string, error) {
+	var buf bytes.Buffer
+	if err := n.Template.Execute(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (n InventoryReconciliationNotification) Subject() string {
+	return n.Title
+}
+
+func (n InventoryReconciliationNotification) TopicArn() string {
+	return n.Topic
+}
+
+// ManifestReconciliationNotification reports the discrepancies between a manifest and the
+// deposits of the objects it lists
+type ManifestReconciliationNotification struct {
+	Account    string
+	Bucket     string
+	Date       string
+	Error      string
+	Extra      int
+	Manifest   string
+	Matched    int
+	Mismatched int
+	Missing    int
+	Objects    int
+	Pending    int
+	Report     string
+	Stack      string
+	Title      string
+	Template   *template.Template
+	Topic      string
+}
+
+func (n ManifestReconciliationNotification) Message() (string, error) {
+	var buf bytes.Buffer
+	if err := n.Template.Execute(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (n ManifestReconciliationNotification) Subject() string {
+	return n.Title
+}
+
+func (n ManifestReconciliationNotification) TopicArn() string {
+	return n.Topic
+}
+
 func SendNotification(ctx context.Context, client *sns.Client, notification SNSNotification) error {
 	message, err := notification.Message()
 	if err != nil {
@@ -3,6 +3,7 @@ package notifications
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -60,3 +61,406 @@ Error: S3 ETag mismatch: expected abc123, got def456
 		t.Errorf("Unexpected topic ARN: %s", notification.TopicArn())
 	}
 }
+
+func TestChecksumFailureNotificationMessageWithChecksums(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "checksum-failure", "templates", "failure-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ChecksumFailureNotification{
+		Account: "123456789012",
+		Stack:   "duracloud-pilot",
+		Date:    "2025-06-26 14:30:25 +0000 UTC",
+		Bucket:  "duracloud-pilot-private-files",
+		Checksums: map[string]string{
+			"sha256": "def456",
+			"md5":    "abc123",
+		},
+		Object:       "documents/report-2024.pdf",
+		ErrorMessage: "Checksum mismatch",
+		Failure:      "corrupted",
+		Template:     tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Checksum verification failed for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26 14:30:25 +0000 UTC
+
+Bucket: duracloud-pilot-private-files
+Object: documents/report-2024.pdf
+Failure: corrupted
+Error: Checksum mismatch
+Stored md5: abc123
+Stored sha256: def456
+`
+
+	if message != expected {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+}
+
+func TestChecksumFailureNotificationMessageWithVersion(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "checksum-failure", "templates", "failure-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ChecksumFailureNotification{
+		Account:      "123456789012",
+		Stack:        "duracloud-pilot",
+		Date:         "2025-06-26 14:30:25 +0000 UTC",
+		Bucket:       "duracloud-pilot-private-files",
+		Object:       "documents/report-2024.pdf",
+		VersionId:    "3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY",
+		ErrorMessage: "Checksum mismatch",
+		Template:     tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Checksum verification failed for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26 14:30:25 +0000 UTC
+
+Bucket: duracloud-pilot-private-files
+Object: documents/report-2024.pdf
+Version: 3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY
+Error: Checksum mismatch
+`
+
+	if message != expected {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+}
+
+func TestChecksumFailureNotificationMessageWithReplica(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "checksum-failure", "templates", "failure-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ChecksumFailureNotification{
+		Account:        "123456789012",
+		Stack:          "duracloud-pilot",
+		Date:           "2025-06-26 14:30:25 +0000 UTC",
+		Bucket:         "duracloud-pilot-private-files",
+		Object:         "documents/report-2024.pdf",
+		Diverged:       "replica",
+		ErrorMessage:   "replica: Checksum mismatch",
+		Replica:        "corrupted",
+		ReplicaMessage: "Checksum mismatch",
+		Template:       tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Checksum verification failed for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26 14:30:25 +0000 UTC
+
+Bucket: duracloud-pilot-private-files
+Object: documents/report-2024.pdf
+Diverged: replica
+Error: replica: Checksum mismatch
+Replica: corrupted
+Replica Error: Checksum mismatch
+`
+
+	if message != expected {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+}
+
+func TestChecksumRepairNotificationMessage(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "checksum-verification", "templates", "repair-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ChecksumRepairNotification{
+		Account:         "123456789012",
+		Stack:           "duracloud-pilot",
+		Date:            "2025-06-26T14:30:25Z",
+		Bucket:          "duracloud-pilot-private-files",
+		Object:          "documents/report-2024.pdf",
+		VersionId:       "v1",
+		RepairMessage:   "copied replica to new version",
+		RepairVersionId: "v2",
+		Replica:         "s3://duracloud-pilot-private-files-repl/documents/report-2024.pdf?versionId=v1",
+		Status:          "repaired",
+		Title:           "DuraCloud Checksum Repair (repaired): duracloud-pilot-private-files",
+		Template:        tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Checksum repair (repaired) for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26T14:30:25Z
+
+Bucket: duracloud-pilot-private-files
+Object: documents/report-2024.pdf
+Version: v1
+Replica: s3://duracloud-pilot-private-files-repl/documents/report-2024.pdf?versionId=v1
+Repaired Version: v2
+Result: copied replica to new version
+`
+
+	if !strings.HasPrefix(message, expected) {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+
+	if notification.Subject() != "DuraCloud Checksum Repair (repaired): duracloud-pilot-private-files" {
+		t.Errorf("Unexpected subject: %s", notification.Subject())
+	}
+}
+
+func TestManifestReconciliationNotificationMessage(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "manifest-reconciler", "templates", "reconciliation-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ManifestReconciliationNotification{
+		Account:    "123456789012",
+		Stack:      "duracloud-pilot",
+		Date:       "2025-06-26T14:30:25Z",
+		Bucket:     "duracloud-pilot-private-files",
+		Manifest:   "duracloud-manifests/batch-1/manifest-sha256.txt",
+		Objects:    10,
+		Matched:    7,
+		Mismatched: 1,
+		Missing:    2,
+		Extra:      1,
+		Report:     "s3://duracloud-pilot-managed/reports/manifests/duracloud-pilot-private-files/batch-1/manifest-sha256.txt-20250626T143025Z.csv",
+		Title:      "DuraCloud Manifest Reconciliation: duracloud-pilot-private-files",
+		Template:   tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Manifest reconciliation for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26T14:30:25Z
+
+Bucket: duracloud-pilot-private-files
+Manifest: duracloud-manifests/batch-1/manifest-sha256.txt
+Objects: 10
+Matched: 7
+Mismatched: 1
+Missing: 2
+Extra: 1
+Pending: 0
+Report: s3://duracloud-pilot-managed/reports/manifests/duracloud-pilot-private-files/batch-1/manifest-sha256.txt-20250626T143025Z.csv
+`
+
+	if !strings.HasPrefix(message, expected) {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+
+	notification.Error = "invalid manifest: name=files.csv line=1"
+	message, err = notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	if !strings.Contains(message, "Error: invalid manifest") || strings.Contains(message, "Matched:") {
+		t.Errorf("Expected only the error to be reported, got:\n%s", message)
+	}
+}
+
+func TestChecksumSweepNotificationMessage(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "checksum-sweeper", "templates", "sweep-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := ChecksumSweepNotification{
+		Account: "123456789012",
+		Stack:   "duracloud-pilot",
+		Date:    "2025-06-26T14:30:25Z",
+		Buckets: []ChecksumSweepSummary{
+			{
+				Bucket:      "duracloud-pilot-private-files",
+				NextKey:     "images/logo.png#v2",
+				Orphans:     120,
+				Rechecked:   2,
+				Records:     5000,
+				Unscheduled: 1,
+			},
+			{
+				Bucket: "duracloud-pilot-public-files",
+				Error:  "invalid period: value=\"soon\"",
+			},
+		},
+		Orphans:   120,
+		Recheck:   "failures=transient after=7d",
+		Threshold: 100,
+		Title:     "DuraCloud Checksum Sweep: duracloud-pilot",
+		Template:  tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Checksum records without a scheduled verification found for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26T14:30:25Z
+Orphans: 120 (alert threshold 100)
+Recheck policy: failures=transient after=7d
+
+Bucket: duracloud-pilot-private-files
+Records: 5000
+Orphans rescheduled: 120
+Failures rechecked: 2
+Failures left unscheduled: 1
+Stopped at: images/logo.png#v2
+
+Bucket: duracloud-pilot-public-files
+Error: invalid period: value="soon"
+
+`
+
+	if !strings.HasPrefix(message, expected) {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+
+	if notification.Subject() != "DuraCloud Checksum Sweep: duracloud-pilot" {
+		t.Errorf("Unexpected subject: %s", notification.Subject())
+	}
+}
+
+func TestInventoryReconciliationNotificationMessage(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "cmd", "inventory-reconciler", "templates", "reconciliation-notification.txt")
+	templateBytes, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("Failed to read template file: %v", err)
+	}
+
+	tmpl, err := template.New("test").Parse(string(templateBytes))
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	notification := InventoryReconciliationNotification{
+		Account: "123456789012",
+		Stack:   "duracloud-pilot",
+		Date:    "2025-06-26T14:30:25Z",
+		Buckets: []InventoryReconciliationSummary{
+			{
+				Bucket:         "duracloud-pilot-private-files",
+				Enqueued:       2,
+				Inventory:      "s3://duracloud-pilot-managed/inventory/duracloud-pilot-private-files/inventory/csv/inventory-2025-06-25.csv",
+				MissingRecords: 2,
+				Objects:        10,
+				OrphanRecords:  1,
+				Records:        9,
+				Report:         "s3://duracloud-pilot-managed/reports/inventory/duracloud-pilot-private-files/reconciliation-20250626T143025Z.csv",
+			},
+			{
+				Bucket: "duracloud-pilot-public-files",
+				Error:  "no inventory found: bucket=duracloud-pilot-public-files",
+			},
+		},
+		Title:    "DuraCloud Inventory Reconciliation: duracloud-pilot",
+		Template: tmpl,
+	}
+
+	message, err := notification.Message()
+	if err != nil {
+		t.Fatalf("Failed to execute template: %v", err)
+	}
+
+	expected := `Inventory reconciliation for:
+
+Account: 123456789012
+Stack: duracloud-pilot
+Time: 2025-06-26T14:30:25Z
+
+Bucket: duracloud-pilot-private-files
+Inventory: s3://duracloud-pilot-managed/inventory/duracloud-pilot-private-files/inventory/csv/inventory-2025-06-25.csv
+Objects: 10
+Records: 9
+Missing records: 2
+Orphan records: 1
+Size mismatches: 0
+Deposits enqueued: 2
+Report: s3://duracloud-pilot-managed/reports/inventory/duracloud-pilot-private-files/reconciliation-20250626T143025Z.csv
+
+Bucket: duracloud-pilot-public-files
+Error: no inventory found: bucket=duracloud-pilot-public-files
+
+`
+
+	if !strings.HasPrefix(message, expected) {
+		t.Errorf("Template output mismatch.\nExpected:\n%s\nGot:\n%s", expected, message)
+	}
+}
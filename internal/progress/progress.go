@@ -0,0 +1,115 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// Progress is the state of an operation on a bucket that continues over several invocations
+type Progress interface {
+	// BucketName returns the bucket the operation is on
+	BucketName() string
+	// CompletionKey returns where the progress is written in the managed bucket once the operation is complete
+	CompletionKey() string
+}
+
+// S3ClientInterface defines the S3 operations required to store progress
+type S3ClientInterface interface {
+	GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// Key returns the managed bucket key of the progress of an operation on a bucket
+func Key(operation, bucket string) string {
+	return fmt.Sprintf("%s/%s.json", operation, bucket)
+}
+
+// Store stores the progress of an operation (such as seeding) on each bucket in the managed bucket
+type Store[P Progress] struct {
+	s3Client  S3ClientInterface
+	bucket    string
+	operation string
+}
+
+func NewStore[P Progress](s3Client S3ClientInterface, bucket, operation string) *Store[P] {
+	return &Store[P]{
+		s3Client:  s3Client,
+		bucket:    bucket,
+		operation: operation,
+	}
+}
+
+// Load returns the progress of a bucket, false when the operation is not in progress
+func (s *Store[P]) Load(ctx context.Context, bucket string) (P, bool, error) {
+	var progress P
+	resp, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(Key(s.operation, bucket)),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey" {
+			return progress, false, nil
+		}
+		return progress, false, fmt.Errorf("failed to load %s progress of %s: %w", s.operation, bucket, err)
+	}
+	defer func(Body io.ReadCloser) { _ = Body.Close() }(resp.Body)
+
+	if err := json.NewDecoder(resp.Body).Decode(&progress); err != nil {
+		return progress, false, fmt.Errorf("failed to decode %s progress of %s: %w", s.operation, bucket, err)
+	}
+
+	return progress, true, nil
+}
+
+// Save stores the progress of an operation that is not complete
+func (s *Store[P]) Save(ctx context.Context, progress P) error {
+	return s.put(ctx, Key(s.operation, progress.BucketName()), progress)
+}
+
+// Complete writes the progress of a complete operation to its completion key and removes its
+// progress, it returns the completion key
+func (s *Store[P]) Complete(ctx context.Context, progress P) (string, error) {
+	key := progress.CompletionKey()
+	if err := s.put(ctx, key, progress); err != nil {
+		return "", err
+	}
+
+	_, err := s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(Key(s.operation, progress.BucketName())),
+	})
+	if err != nil {
+		return key, fmt.Errorf("failed to remove %s progress of %s: %w", s.operation, progress.BucketName(), err)
+	}
+
+	return key, nil
+}
+
+func (s *Store[P]) put(ctx context.Context, key string, progress P) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+type testProgress struct {
+	Bucket   string `json:"bucket"`
+	NextKey  string `json:"nextKey"`
+	Complete bool   `json:"complete"`
+}
+
+func (p testProgress) BucketName() string {
+	return p.Bucket
+}
+
+func (p testProgress) CompletionKey() string {
+	return "reports/test/" + p.Bucket + "/summary.json"
+}
+
+type mockS3Client struct {
+	objects map[string][]byte
+}
+
+func (m *mockS3Client) GetObject(ctx context.Context, input *s3.GetObjectInput, opts ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := m.objects[aws.ToString(input.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{Message: aws.String("not found")}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, input *s3.PutObjectInput, opts ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.objects[aws.ToString(input.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *mockS3Client) DeleteObject(ctx context.Context, input *s3.DeleteObjectInput, opts ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(m.objects, aws.ToString(input.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	client := &mockS3Client{objects: map[string][]byte{}}
+	store := NewStore[testProgress](client, "managed", "test")
+
+	if _, ok, err := store.Load(ctx, "bucket"); ok || err != nil {
+		t.Fatalf("Expected no progress, got %v (%v)", ok, err)
+	}
+
+	progress := testProgress{Bucket: "bucket", NextKey: "a/file.txt"}
+	if err := store.Save(ctx, progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := client.objects["test/bucket.json"]; !ok {
+		t.Fatalf("Expected the progress at test/bucket.json, got %v", client.objects)
+	}
+
+	loaded, ok, err := store.Load(ctx, "bucket")
+	if !ok || err != nil {
+		t.Fatalf("Expected the progress, got %v (%v)", ok, err)
+	}
+	if loaded != progress {
+		t.Errorf("Expected %v, got %v", progress, loaded)
+	}
+
+	progress.Complete = true
+	key, err := store.Complete(ctx, progress)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if key != progress.CompletionKey() {
+		t.Errorf("Expected %s, got %s", progress.CompletionKey(), key)
+	}
+	if _, ok := client.objects[key]; !ok {
+		t.Error("Expected the completed progress to be written")
+	}
+	if _, ok, _ := store.Load(ctx, "bucket"); ok {
+		t.Error("Expected the progress to be removed")
+	}
+}
@@ -2,8 +2,10 @@ package queues
 
 import (
 	"duracloud/internal/buckets"
+	"duracloud/internal/files"
 	"encoding/json"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -17,12 +19,28 @@ type S3EventBridgeEvent struct {
 			Name string `json:"name"`
 		} `json:"bucket"`
 		Object struct {
-			Etag string `json:"etag"`
-			Key  string `json:"key"`
+			Etag      string `json:"etag"`
+			Key       string `json:"key"`
+			Sequencer string `json:"sequencer"`
+			VersionId string `json:"version-id"`
 		} `json:"object"`
+		DeletionType string `json:"deletion-type"`
 	} `json:"detail"`
 }
 
+// NewObjectCreatedEvent returns the event S3 sends when an object version is created, the etag
+// is unquoted as in events from S3
+func NewObjectCreatedEvent(obj files.S3Object, etag string) S3EventBridgeEvent {
+	var event S3EventBridgeEvent
+	event.DetailType = "Object Created"
+	event.Source = "aws.s3"
+	event.Detail.Bucket.Name = obj.Bucket
+	event.Detail.Object.Etag = strings.Trim(etag, `"`)
+	event.Detail.Object.Key = obj.Key
+	event.Detail.Object.VersionId = obj.VersionId
+	return event
+}
+
 type S3EventBridgeEventWithMessageId struct {
 	MessageId string
 	S3EventBridgeEvent
@@ -53,6 +71,22 @@ func (e *S3EventBridgeEvent) ObjectKey() string {
 	return e.Detail.Object.Key
 }
 
+// Sequencer extracts the sequencer that orders the events of an object key
+func (e *S3EventBridgeEvent) Sequencer() string {
+	return e.Detail.Object.Sequencer
+}
+
+// VersionId extracts the object version id (empty when the bucket is not versioned)
+func (e *S3EventBridgeEvent) VersionId() string {
+	return e.Detail.Object.VersionId
+}
+
+// IsDeleteMarkerCreated checks if a deletion only created a delete marker, the
+// object versions are retained
+func (e *S3EventBridgeEvent) IsDeleteMarkerCreated() bool {
+	return e.Detail.DeletionType == "Delete Marker Created"
+}
+
 // IsIgnoreFilesBucket checks if the bucket contains ignorable files
 func (e *S3EventBridgeEvent) IsIgnoreFilesBucket() bool {
 	return buckets.IsIgnoreFilesBucket(e.BucketName())
@@ -63,9 +97,20 @@ func (e *S3EventBridgeEvent) IsObjectCreated() bool {
 	return e.DetailType == "Object Created"
 }
 
-// IsObjectDeleted checks if the event is an object deletion event
+// IsObjectDeleted checks if the event is an object deletion event, including the expiry of a
+// version by a lifecycle rule
 func (e *S3EventBridgeEvent) IsObjectDeleted() bool {
-	return e.DetailType == "Object Deleted"
+	return e.DetailType == "Object Deleted" || e.IsLifecycleExpiration()
+}
+
+// IsLifecycleExpiration checks if the event is the expiry of a version by a lifecycle rule
+func (e *S3EventBridgeEvent) IsLifecycleExpiration() bool {
+	return e.DetailType == "Lifecycle Expiration"
+}
+
+// IsObjectRestoreCompleted checks if the event is the completion of a restore from an archive
+func (e *S3EventBridgeEvent) IsObjectRestoreCompleted() bool {
+	return e.DetailType == "Object Restore Completed"
 }
 
 // IsRestrictedBucket checks if the bucket is a restricted type
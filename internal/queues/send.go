@@ -0,0 +1,61 @@
+package queues
+
+import (
+	"context"
+	"duracloud/internal/files"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// MaxBatchSize is the most messages SendMessageBatch accepts
+const MaxBatchSize = 10
+
+// BatchSender sends messages to a queue in batches, sqs.Client implements it
+type BatchSender interface {
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+}
+
+// CreatedObject is an object version to deposit and its etag
+type CreatedObject struct {
+	Object files.S3Object
+	ETag   string
+}
+
+// SendObjectCreated sends an object created event for each of up to MaxBatchSize versions to the
+// queue of the file-uploaded function, it returns and logs the versions that were not sent
+func SendObjectCreated(ctx context.Context, client BatchSender, queueURL string, objects []CreatedObject) ([]CreatedObject, error) {
+	entries := make([]types.SendMessageBatchRequestEntry, 0, len(objects))
+	for i, obj := range objects {
+		body, err := json.Marshal(NewObjectCreatedEvent(obj.Object, obj.ETag))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, types.SendMessageBatchRequestEntry{
+			Id:          aws.String(strconv.Itoa(i)),
+			MessageBody: aws.String(string(body)),
+		})
+	}
+
+	resp, err := client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []CreatedObject
+	for _, entry := range resp.Failed {
+		i, _ := strconv.Atoi(aws.ToString(entry.Id))
+		log.Printf("Failed to send object created event of %s: %s", objects[i].Object.URI(), aws.ToString(entry.Message))
+		failed = append(failed, objects[i])
+	}
+
+	return failed, nil
+}
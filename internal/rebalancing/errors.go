@@ -0,0 +1,14 @@
+package rebalancing
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDryRunMismatch = errors.New("rebalancing in progress with another dry run setting")
+)
+
+func ErrorDryRunMismatch(bucket string, dryRun bool) error {
+	return fmt.Errorf("%w: bucket=%s dryRun=%t", ErrDryRunMismatch, bucket, dryRun)
+}
@@ -0,0 +1,76 @@
+package rebalancing
+
+import (
+	"duracloud/internal/db"
+	"duracloud/internal/progress"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// operation names the rebalancing progress, it is stored under rebalancing/ in the managed bucket
+const operation = "rebalancing"
+
+// Phases of a rebalancing, the scheduler entries of the bucket are read once in each
+const (
+	PhaseSurvey    = "survey"    // the verifications due on each day are counted
+	PhaseRebalance = "rebalance" // the verifications are moved to the lightest day of their window
+)
+
+// Progress is the state of the rebalancing of a bucket. NextKey is the last scheduler entry read
+// in the phase, Before is the load of each day found by the survey and After the load once the
+// entries read by the rebalance phase are moved. The policy is the one the rebalancing started
+// with so every entry is moved within the same window.
+type Progress struct {
+	Bucket   string          `json:"bucket"`
+	DryRun   bool            `json:"dryRun"`
+	Policy   db.FixityPolicy `json:"policy"`
+	Phase    string          `json:"phase"`
+	NextKey  string          `json:"nextKey,omitempty"`
+	Started  time.Time       `json:"started"`
+	Updated  time.Time       `json:"updated"`
+	Complete bool            `json:"complete"`
+	Entries  int             `json:"entries"`
+	Moved    int             `json:"moved"`
+	Kept     int             `json:"kept"`
+	Fixed    int             `json:"fixed"`
+	Before   db.Histogram    `json:"before"`
+	After    db.Histogram    `json:"after"`
+}
+
+// NewProgress starts the rebalancing of a bucket under a fixity policy
+func NewProgress(bucket string, policy db.FixityPolicy, dryRun bool) Progress {
+	now := time.Now().UTC()
+	return Progress{
+		Bucket:  bucket,
+		DryRun:  dryRun,
+		Policy:  policy,
+		Phase:   PhaseSurvey,
+		Started: now,
+		Updated: now,
+		Before:  db.Histogram{},
+	}
+}
+
+// ProgressKey returns the managed bucket key of the progress of a bucket
+func ProgressKey(bucket string) string {
+	return progress.Key(operation, bucket)
+}
+
+// BucketName returns the bucket being rebalanced
+func (p Progress) BucketName() string {
+	return p.Bucket
+}
+
+// CompletionKey returns where the before and after distributions are written in the managed bucket
+func (p Progress) CompletionKey() string {
+	return fmt.Sprintf("reports/rebalancing/%s/report-%s.json", p.Bucket, p.Updated.UTC().Format("20060102T150405Z"))
+}
+
+// ProgressStore stores the progress of the rebalancing of each bucket in the managed bucket
+type ProgressStore = progress.Store[Progress]
+
+func NewProgressStore(s3Client *s3.Client, bucket string) *ProgressStore {
+	return progress.NewStore[Progress](s3Client, bucket, operation)
+}
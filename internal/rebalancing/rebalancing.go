@@ -0,0 +1,57 @@
+package rebalancing
+
+import (
+	"duracloud/internal/db"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Scheduler reads and moves the scheduled verifications of a bucket, db.DB implements it
+type Scheduler interface {
+	SurveySchedule(bucket string, histogram db.Histogram, startKey string) (db.RebalanceResult, error)
+	RebalanceSchedule(bucket string, policy db.FixityPolicy, histogram db.Histogram, startKey string, dryRun bool) (db.RebalanceResult, error)
+}
+
+// Run continues the rebalancing of a bucket from its progress until it completes or a phase stops
+// before the context deadline, the progress is updated with what was read and moved
+func Run(scheduler Scheduler, progress *Progress) error {
+	defer func() { progress.Updated = time.Now().UTC() }()
+
+	if progress.Phase == PhaseSurvey {
+		if progress.Before == nil {
+			progress.Before = db.Histogram{}
+		}
+
+		result, err := scheduler.SurveySchedule(progress.Bucket, progress.Before, progress.NextKey)
+		progress.NextKey = result.NextKey
+		progress.Entries += result.Entries
+		if err != nil {
+			return fmt.Errorf("failed to survey the schedule of %s: %w", progress.Bucket, err)
+		}
+		if !result.Complete {
+			return nil
+		}
+
+		// The rebalance phase reads the entries again, moving them from the surveyed load
+		progress.After = maps.Clone(progress.Before)
+		progress.Phase = PhaseRebalance
+		progress.NextKey = ""
+	}
+
+	if progress.After == nil {
+		progress.After = db.Histogram{}
+	}
+
+	result, err := scheduler.RebalanceSchedule(progress.Bucket, progress.Policy, progress.After, progress.NextKey, progress.DryRun)
+	progress.NextKey = result.NextKey
+	progress.Moved += result.Moved
+	progress.Kept += result.Kept
+	progress.Fixed += result.Fixed
+	if err != nil {
+		return fmt.Errorf("failed to rebalance the schedule of %s: %w", progress.Bucket, err)
+	}
+
+	progress.Complete = result.Complete
+	return nil
+}
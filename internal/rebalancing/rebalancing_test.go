@@ -0,0 +1,104 @@
+package rebalancing
+
+import (
+	"duracloud/internal/db"
+	"testing"
+)
+
+// mockScheduler has one entry per day of its days, a pass over them stops after limit entries
+type mockScheduler struct {
+	days  []string
+	limit int
+	moves map[string]string
+}
+
+func (m *mockScheduler) pass(startKey string, fn func(day string)) db.RebalanceResult {
+	result := db.RebalanceResult{}
+	for index, day := range m.days {
+		if startKey != "" && day <= startKey {
+			continue
+		}
+		if m.limit > 0 && result.Entries == m.limit {
+			result.NextKey = m.days[index-1]
+			return result
+		}
+		fn(day)
+		result.Entries++
+	}
+	result.Complete = true
+	return result
+}
+
+func (m *mockScheduler) SurveySchedule(_ string, histogram db.Histogram, startKey string) (db.RebalanceResult, error) {
+	return m.pass(startKey, func(day string) { histogram.Add(day, 10) }), nil
+}
+
+func (m *mockScheduler) RebalanceSchedule(
+	_ string,
+	_ db.FixityPolicy,
+	histogram db.Histogram,
+	startKey string,
+	_ bool,
+) (db.RebalanceResult, error) {
+	moved := 0
+	result := m.pass(startKey, func(day string) {
+		if to, ok := m.moves[day]; ok {
+			histogram.Add(to, 10)
+			delete(histogram, day)
+			moved++
+		}
+	})
+	result.Moved = moved
+	return result, nil
+}
+
+func TestRun(t *testing.T) {
+	scheduler := &mockScheduler{
+		days:  []string{"2025-06-01", "2025-06-02", "2025-06-03"},
+		limit: 2,
+		moves: map[string]string{"2025-06-03": "2025-06-04"},
+	}
+
+	progress := NewProgress("bucket", db.DefaultFixityPolicy, false)
+	if err := Run(scheduler, &progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if progress.Phase != PhaseSurvey || progress.NextKey != "2025-06-02" || progress.Entries != 2 || progress.Complete {
+		t.Fatalf("Expected the survey to stop after two entries, got %+v", progress)
+	}
+
+	// The survey completes and the rebalance phase starts from the first entry
+	if err := Run(scheduler, &progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if progress.Phase != PhaseRebalance || progress.NextKey != "2025-06-02" || progress.Entries != 3 || progress.Complete {
+		t.Fatalf("Expected the rebalance phase to stop after two entries, got %+v", progress)
+	}
+
+	scheduler.limit = 0
+	if err := Run(scheduler, &progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !progress.Complete || progress.NextKey != "" || progress.Moved != 1 {
+		t.Fatalf("Expected the rebalancing to complete, got %+v", progress)
+	}
+
+	if len(progress.Before) != 3 || progress.Before["2025-06-03"].Objects != 1 {
+		t.Errorf("Unexpected distribution before: %v", progress.Before)
+	}
+	if len(progress.After) != 3 || progress.After["2025-06-04"].Objects != 1 {
+		t.Errorf("Unexpected distribution after: %v", progress.After)
+	}
+}
+
+func TestProgressKeys(t *testing.T) {
+	progress := NewProgress("bucket", db.DefaultFixityPolicy, true)
+	if ProgressKey(progress.Bucket) != "rebalancing/bucket.json" {
+		t.Errorf("Unexpected progress key: %s", ProgressKey(progress.Bucket))
+	}
+
+	expected := "reports/rebalancing/bucket/report-" + progress.Updated.Format("20060102T150405Z") + ".json"
+	if progress.CompletionKey() != expected {
+		t.Errorf("Expected %s, got %s", expected, progress.CompletionKey())
+	}
+}
@@ -3,6 +3,7 @@ package reports
 import (
 	"bytes"
 	"context"
+	"duracloud/internal/db"
 	"duracloud/internal/files"
 	"duracloud/internal/inventory"
 	"fmt"
@@ -15,6 +16,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
+// ReportFailureLimit is the number of failing objects listed for each bucket in the report
+const ReportFailureLimit = 100
+
+// BucketStats are the storage stats of a bucket, and its currently failing objects when the
+// report includes failures (MoreFailures is set when there are more than are listed)
 type BucketStats struct {
 	Name             string
 	TotalSize        int64
@@ -23,6 +29,8 @@ type BucketStats struct {
 	Tags             map[string]string
 	StatsDate        string
 	StatsGeneratedAt time.Time
+	Failures         []db.ChecksumRecord
+	MoreFailures     bool
 }
 
 type PrefixStats struct {
@@ -38,12 +46,25 @@ type ReportData struct {
 	TotalSize    int64
 	TotalObjects int64
 	BucketStats  []BucketStats
+	Verification *VerificationUsage
+}
+
+// VerificationUsage is the bytes read by checksum verification in each window of the byte
+// budget, and the verifications deferred because the budget was spent
+type VerificationUsage struct {
+	Budget             db.ByteBudget
+	Windows            []db.BudgetUsage
+	TotalBytes         int64
+	TotalVerifications int64
+	TotalDeferred      int64
 }
 
 type StorageReportGenerator struct {
 	s3Client          *s3.Client
 	stackName         string
+	failures          db.FailureLister
 	managedBucketName string
+	verification      *VerificationUsage
 }
 
 func NewStorageReportGenerator(s3Client *s3.Client, stackName, managedBucketName string) *StorageReportGenerator {
@@ -54,6 +75,25 @@ func NewStorageReportGenerator(s3Client *s3.Client, stackName, managedBucketName
 	}
 }
 
+// WithFailures lists the objects of each bucket whose last check failed in the report
+func (g *StorageReportGenerator) WithFailures(failures db.FailureLister) *StorageReportGenerator {
+	g.failures = failures
+	return g
+}
+
+// WithVerificationUsage includes the checksum verification usage of the byte budget in the report
+func (g *StorageReportGenerator) WithVerificationUsage(budget db.ByteBudget, windows []db.BudgetUsage) *StorageReportGenerator {
+	usage := &VerificationUsage{Budget: budget, Windows: windows}
+	for _, window := range windows {
+		usage.TotalBytes += window.Bytes
+		usage.TotalVerifications += window.Verifications
+		usage.TotalDeferred += window.Deferred
+	}
+
+	g.verification = usage
+	return g
+}
+
 func (g *StorageReportGenerator) GenerateReport(ctx context.Context, tmpl *template.Template) (string, error) {
 	log.Println("Finding buckets with stats")
 	statsReader := NewBucketStatsReader(ctx, g.s3Client, g.stackName)
@@ -105,6 +145,7 @@ func (g *StorageReportGenerator) calculateAggregates(bucketStats []BucketStats)
 		StackName:    g.stackName,
 		TotalBuckets: int64(len(bucketStats)),
 		BucketStats:  bucketStats,
+		Verification: g.verification,
 	}
 
 	// Calculate totals
@@ -177,6 +218,16 @@ func (g *StorageReportGenerator) loadBucketStats(
 
 		// Convert inventory stats to report stats
 		bucketStats := g.convertInventoryStats(stats, tags)
+
+		if g.failures != nil {
+			page, err := g.failures.ListFailures(bucketName, db.Page{Limit: ReportFailureLimit})
+			if err != nil {
+				log.Printf("Warning: failed to list failures for %s: %v", bucketName, err)
+			}
+			bucketStats.Failures = page.Records
+			bucketStats.MoreFailures = page.NextKey != ""
+		}
+
 		allStats = append(allStats, bucketStats)
 	}
 
@@ -0,0 +1,19 @@
+package seeding
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidSource  = errors.New("invalid seeding source")
+	ErrSourceMismatch = errors.New("seeding in progress from another source")
+)
+
+func ErrorInvalidSource(source string) error {
+	return fmt.Errorf("%w: source=%s (expected %s or %s)", ErrInvalidSource, source, SourceVersions, SourceInventory)
+}
+
+func ErrorSourceMismatch(bucket, source string) error {
+	return fmt.Errorf("%w: bucket=%s source=%s", ErrSourceMismatch, bucket, source)
+}
@@ -0,0 +1,71 @@
+package seeding
+
+import (
+	"duracloud/internal/progress"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// operation names the seeding progress, it is stored under seeding/ in the managed bucket
+const operation = "seeding"
+
+// Progress is the state of the seeding of a bucket. The markers are the last version handled,
+// versions after it in the listing (or the sorted inventory) are still to be seeded.
+type Progress struct {
+	Bucket          string    `json:"bucket"`
+	Source          string    `json:"source"`
+	Inventory       string    `json:"inventory,omitempty"`
+	KeyMarker       string    `json:"keyMarker,omitempty"`
+	VersionIdMarker string    `json:"versionIdMarker,omitempty"`
+	Started         time.Time `json:"started"`
+	Updated         time.Time `json:"updated"`
+	Complete        bool      `json:"complete"`
+	Listed          int       `json:"listed"`
+	Existing        int       `json:"existing"`
+	Deleted         int       `json:"deleted"`
+	Enqueued        int       `json:"enqueued"`
+	Failed          int       `json:"failed"`
+
+	FailedVersions []FailedVersion `json:"failedVersions,omitempty"`
+}
+
+// FailedVersion is a version whose deposit the queue rejected, seeding has moved past it so it
+// is deposited by seeding the bucket again (or by the inventory reconciler)
+type FailedVersion struct {
+	Key       string `json:"key"`
+	VersionId string `json:"versionId,omitempty"`
+}
+
+// NewProgress starts the seeding of a bucket from a source
+func NewProgress(bucket, source string) (Progress, error) {
+	if source != SourceVersions && source != SourceInventory {
+		return Progress{}, ErrorInvalidSource(source)
+	}
+
+	now := time.Now().UTC()
+	return Progress{Bucket: bucket, Source: source, Started: now, Updated: now}, nil
+}
+
+// ProgressKey returns the managed bucket key of the progress of a bucket
+func ProgressKey(bucket string) string {
+	return progress.Key(operation, bucket)
+}
+
+// BucketName returns the bucket being seeded
+func (p Progress) BucketName() string {
+	return p.Bucket
+}
+
+// CompletionKey returns where the completion summary is written in the managed bucket
+func (p Progress) CompletionKey() string {
+	return fmt.Sprintf("reports/seeding/%s/summary-%s.json", p.Bucket, p.Updated.UTC().Format("20060102T150405Z"))
+}
+
+// ProgressStore stores the progress of the seeding of each bucket in the managed bucket
+type ProgressStore = progress.Store[Progress]
+
+func NewProgressStore(s3Client *s3.Client, bucket string) *ProgressStore {
+	return progress.NewStore[Progress](s3Client, bucket, operation)
+}
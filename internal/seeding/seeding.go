@@ -0,0 +1,284 @@
+package seeding
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/files"
+	"duracloud/internal/inventory"
+	"duracloud/internal/queues"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// Sources of the versions to seed
+const (
+	SourceInventory = "inventory" // the latest consolidated inventory of the bucket
+	SourceVersions  = "versions"  // a listing of the object versions in the bucket
+)
+
+// DefaultRate is the number of deposits enqueued per second when no rate is set
+const DefaultRate = 20
+
+// seedReserve is the time kept back from the context deadline to save the progress of a seeding
+const seedReserve = 30 * time.Second
+
+// S3Client lists and heads the object versions of a bucket, s3.Client implements it
+type S3Client interface {
+	s3.HeadObjectAPIClient
+	s3.ListObjectVersionsAPIClient
+}
+
+// RecordGetter reads the checksum record of a version, db.DB implements it
+type RecordGetter interface {
+	Get(obj files.S3Object) (db.ChecksumRecord, error)
+}
+
+// Seeder enqueues a deposit of each version of a bucket without a checksum record, the events
+// are those S3 sends so the file-uploaded function deposits them like an upload
+type Seeder struct {
+	ctx      context.Context
+	s3Client S3Client
+	records  RecordGetter
+	queue    queues.BatchSender
+	queueURL string
+	rate     int
+
+	nextSend time.Time
+	pending  []queues.CreatedObject
+	position files.S3Object
+
+	// Counted since the last commit
+	deleted  int
+	existing int
+	listed   int
+}
+
+func NewSeeder(
+	ctx context.Context,
+	s3Client S3Client,
+	records RecordGetter,
+	queue queues.BatchSender,
+	queueURL string,
+) *Seeder {
+	return &Seeder{
+		ctx:      ctx,
+		s3Client: s3Client,
+		records:  records,
+		queue:    queue,
+		queueURL: queueURL,
+		rate:     DefaultRate,
+	}
+}
+
+// WithRate sets the number of deposits enqueued per second, zero or less is unlimited
+func (s *Seeder) WithRate(rate int) *Seeder {
+	s.rate = rate
+	return s
+}
+
+// SeedVersions seeds the versions of the bucket listed after the progress markers. It stops
+// before the context deadline leaving the progress incomplete, seed again to continue.
+func (s *Seeder) SeedVersions(progress *Progress) error {
+	input := &s3.ListObjectVersionsInput{
+		Bucket: aws.String(progress.Bucket),
+	}
+	if progress.KeyMarker != "" {
+		input.KeyMarker = aws.String(progress.KeyMarker)
+		if progress.VersionIdMarker != "" {
+			input.VersionIdMarker = aws.String(progress.VersionIdMarker)
+		}
+	}
+
+	paginator := s3.NewListObjectVersionsPaginator(s.s3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(s.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list versions of %s: %w", progress.Bucket, err)
+		}
+
+		// Delete markers are listed apart from the versions
+		for _, version := range page.Versions {
+			if s.stopping() {
+				return s.commit(progress)
+			}
+
+			obj := files.NewS3ObjectVersion(progress.Bucket, aws.ToString(version.Key), aws.ToString(version.VersionId))
+			if err := s.seed(progress, obj, aws.ToString(version.ETag)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.complete(progress)
+}
+
+// SeedInventory seeds the versions of an inventory in order of their db.VersionKey after the
+// progress markers. It stops before the context deadline leaving the progress incomplete, seed
+// again with the same inventory to continue.
+func (s *Seeder) SeedInventory(progress *Progress, objects map[string]inventory.InventoryObject) error {
+	versionKeys := make([]string, 0, len(objects))
+	for versionKey := range objects {
+		versionKeys = append(versionKeys, versionKey)
+	}
+	sort.Strings(versionKeys)
+
+	var marker string
+	if progress.KeyMarker != "" {
+		marker = db.VersionKey(files.NewS3ObjectVersion("", progress.KeyMarker, progress.VersionIdMarker))
+	}
+
+	for _, versionKey := range versionKeys {
+		if versionKey <= marker {
+			continue
+		}
+
+		if s.stopping() {
+			return s.commit(progress)
+		}
+
+		obj := objects[versionKey]
+		version := files.NewS3ObjectVersion(progress.Bucket, obj.Key, obj.VersionId)
+		if err := s.seed(progress, version, ""); err != nil {
+			return err
+		}
+	}
+
+	return s.complete(progress)
+}
+
+// seed adds a version without a checksum record to the pending deposits, an empty etag is read
+// from S3 as inventories do not list them
+func (s *Seeder) seed(progress *Progress, obj files.S3Object, etag string) error {
+	s.position = obj
+	if strings.HasSuffix(obj.Key, "/") {
+		return nil
+	}
+	s.listed++
+
+	_, err := s.records.Get(obj)
+	if err == nil {
+		s.existing++
+		return nil
+	}
+	if !errors.Is(err, db.ErrChecksumRecordNotFound) {
+		return err
+	}
+
+	if etag == "" {
+		head, err := s.s3Client.HeadObject(s.ctx, &s3.HeadObjectInput{
+			Bucket:    aws.String(obj.Bucket),
+			Key:       aws.String(obj.Key),
+			VersionId: obj.VersionIdInput(),
+		})
+		if isNotFound(err) {
+			// Deleted since the inventory was taken
+			s.deleted++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to head %s: %w", obj.URI(), err)
+		}
+		etag = aws.ToString(head.ETag)
+	}
+
+	s.pending = append(s.pending, queues.CreatedObject{Object: obj, ETag: etag})
+	if len(s.pending) == queues.MaxBatchSize {
+		return s.commit(progress)
+	}
+
+	return nil
+}
+
+// commit enqueues the pending deposits and moves the progress up to the last version seeded,
+// the progress is left as it was when they cannot be enqueued. Versions the queue rejects are
+// added to the failed versions of the progress, they are listed by the completion summary.
+func (s *Seeder) commit(progress *Progress) error {
+	if len(s.pending) > 0 {
+		if err := s.wait(); err != nil {
+			return err
+		}
+
+		failed, err := queues.SendObjectCreated(s.ctx, s.queue, s.queueURL, s.pending)
+		if err != nil {
+			return fmt.Errorf("failed to enqueue deposits of %s: %w", progress.Bucket, err)
+		}
+
+		if s.rate > 0 {
+			s.nextSend = time.Now().Add(time.Duration(len(s.pending)) * time.Second / time.Duration(s.rate))
+		}
+		progress.Enqueued += len(s.pending) - len(failed)
+		progress.Failed += len(failed)
+		for _, created := range failed {
+			progress.FailedVersions = append(progress.FailedVersions, FailedVersion{
+				Key:       created.Object.Key,
+				VersionId: created.Object.VersionId,
+			})
+		}
+		s.pending = s.pending[:0]
+	}
+
+	progress.Deleted += s.deleted
+	progress.Existing += s.existing
+	progress.Listed += s.listed
+	s.deleted, s.existing, s.listed = 0, 0, 0
+
+	if s.position.Key != "" {
+		progress.KeyMarker = s.position.Key
+		progress.VersionIdMarker = s.position.VersionId
+	}
+	progress.Updated = time.Now().UTC()
+
+	return nil
+}
+
+func (s *Seeder) complete(progress *Progress) error {
+	if err := s.commit(progress); err != nil {
+		return err
+	}
+	progress.Complete = true
+
+	log.Printf("Seeded %s: %d versions, %d with a record, %d deleted, %d enqueued, %d failed",
+		progress.Bucket, progress.Listed, progress.Existing, progress.Deleted, progress.Enqueued, progress.Failed)
+	return nil
+}
+
+// wait holds the pending deposits back until the rate allows them
+func (s *Seeder) wait() error {
+	delay := time.Until(s.nextSend)
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+func (s *Seeder) stopping() bool {
+	deadline, ok := s.ctx.Deadline()
+	return ok && time.Until(deadline) < seedReserve
+}
+
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "NoSuchKey", "NoSuchVersion", "NotFound":
+		return true
+	}
+	return false
+}
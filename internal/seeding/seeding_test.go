@@ -0,0 +1,256 @@
+package seeding
+
+import (
+	"context"
+	"duracloud/internal/db"
+	"duracloud/internal/inventory"
+	"duracloud/internal/queues"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+)
+
+// mockS3Client lists its versions in key order and heads them by key and version id
+type mockS3Client struct {
+	versions []types.ObjectVersion
+}
+
+func (m *mockS3Client) addVersion(key, versionId, etag string) {
+	m.versions = append(m.versions, types.ObjectVersion{
+		Key:       aws.String(key),
+		VersionId: aws.String(versionId),
+		ETag:      aws.String(etag),
+	})
+}
+
+func (m *mockS3Client) ListObjectVersions(
+	_ context.Context,
+	params *s3.ListObjectVersionsInput,
+	_ ...func(*s3.Options),
+) (*s3.ListObjectVersionsOutput, error) {
+	marker := aws.ToString(params.KeyMarker) + "#" + aws.ToString(params.VersionIdMarker)
+
+	var versions []types.ObjectVersion
+	for _, version := range m.versions {
+		if params.KeyMarker == nil || aws.ToString(version.Key)+"#"+aws.ToString(version.VersionId) > marker {
+			versions = append(versions, version)
+		}
+	}
+	return &s3.ListObjectVersionsOutput{Versions: versions}, nil
+}
+
+func (m *mockS3Client) HeadObject(
+	_ context.Context,
+	params *s3.HeadObjectInput,
+	_ ...func(*s3.Options),
+) (*s3.HeadObjectOutput, error) {
+	for _, version := range m.versions {
+		if aws.ToString(version.Key) == aws.ToString(params.Key) &&
+			aws.ToString(version.VersionId) == aws.ToString(params.VersionId) {
+			return &s3.HeadObjectOutput{ETag: version.ETag}, nil
+		}
+	}
+	return nil, &smithy.GenericAPIError{Code: "NotFound"}
+}
+
+// mockQueue keeps the object created events sent to it, the events of rejected versions fail
+type mockQueue struct {
+	events   []queues.S3EventBridgeEvent
+	rejected map[string]bool
+}
+
+func (m *mockQueue) SendMessageBatch(
+	_ context.Context,
+	params *sqs.SendMessageBatchInput,
+	_ ...func(*sqs.Options),
+) (*sqs.SendMessageBatchOutput, error) {
+	output := &sqs.SendMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		var event queues.S3EventBridgeEvent
+		if err := json.Unmarshal([]byte(aws.ToString(entry.MessageBody)), &event); err != nil {
+			return nil, err
+		}
+		if m.rejected[event.ObjectKey()+"#"+event.VersionId()] {
+			output.Failed = append(output.Failed, sqstypes.BatchResultErrorEntry{Id: entry.Id, Message: aws.String("rejected")})
+			continue
+		}
+		m.events = append(m.events, event)
+		output.Successful = append(output.Successful, sqstypes.SendMessageBatchResultEntry{Id: entry.Id})
+	}
+	return output, nil
+}
+
+func (m *mockQueue) keys() []string {
+	keys := make([]string, 0, len(m.events))
+	for _, event := range m.events {
+		keys = append(keys, event.ObjectKey()+"#"+event.VersionId())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newTestStore(t *testing.T) *db.MemoryStore {
+	store := db.NewMemoryStore()
+	for _, record := range []db.ChecksumRecord{
+		{ObjectKey: "deposited.txt", VersionId: "v1"},
+		{ObjectKey: "images/logo.png", VersionId: "v2"},
+	} {
+		record.BucketName = "bucket"
+		if err := store.Put(record); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return store
+}
+
+func TestSeedVersions(t *testing.T) {
+	client := &mockS3Client{}
+	client.addVersion("deposited.txt", "v1", `"etag-deposited"`)
+	client.addVersion("images/", "v1", `"etag-prefix"`)
+	client.addVersion("images/logo.png", "v1", `"etag-logo-1"`)
+	client.addVersion("images/logo.png", "v2", `"etag-logo-2"`)
+	client.addVersion("undeposited.txt", "v1", `"etag-undeposited"`)
+
+	queue := &mockQueue{}
+	progress, err := NewProgress("bucket", SourceVersions)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	seeder := NewSeeder(context.Background(), client, newTestStore(t), queue, "queue-url").WithRate(0)
+	if err := seeder.SeedVersions(&progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !progress.Complete || progress.Listed != 4 || progress.Existing != 2 || progress.Enqueued != 2 || progress.Failed != 0 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if progress.KeyMarker != "undeposited.txt" || progress.VersionIdMarker != "v1" {
+		t.Errorf("Expected the markers to be the last version, got %s %s", progress.KeyMarker, progress.VersionIdMarker)
+	}
+
+	if keys := queue.keys(); len(keys) != 2 || keys[0] != "images/logo.png#v1" || keys[1] != "undeposited.txt#v1" {
+		t.Fatalf("Unexpected deposits: %v", keys)
+	}
+	for _, event := range queue.events {
+		if !event.IsObjectCreated() || event.BucketName() != "bucket" || event.Etag() == "" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	}
+
+	// A seeding resumes after its markers
+	resumed := Progress{Bucket: "bucket", Source: SourceVersions, KeyMarker: "images/logo.png", VersionIdMarker: "v2"}
+	queue.events = nil
+	if err := seeder.SeedVersions(&resumed); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if keys := queue.keys(); len(keys) != 1 || keys[0] != "undeposited.txt#v1" || resumed.Listed != 1 {
+		t.Errorf("Unexpected resumed deposits: %v (%+v)", keys, resumed)
+	}
+}
+
+func TestSeedInventory(t *testing.T) {
+	client := &mockS3Client{}
+	client.addVersion("images/logo.png", "v1", `"etag-logo-1"`)
+	client.addVersion("undeposited.txt", "v1", `"etag-undeposited"`)
+
+	objects := map[string]inventory.InventoryObject{}
+	for _, obj := range []inventory.InventoryObject{
+		{Key: "deposited.txt", VersionId: "v1"},
+		{Key: "deleted.txt", VersionId: "v1"},
+		{Key: "images/logo.png", VersionId: "v1"},
+		{Key: "images/logo.png", VersionId: "v2"},
+		{Key: "undeposited.txt", VersionId: "v1"},
+	} {
+		objects[obj.Key+"#"+obj.VersionId] = obj
+	}
+
+	queue := &mockQueue{}
+	progress := Progress{Bucket: "bucket", Source: SourceInventory, KeyMarker: "deleted.txt", VersionIdMarker: "v1"}
+
+	seeder := NewSeeder(context.Background(), client, newTestStore(t), queue, "queue-url").WithRate(0)
+	if err := seeder.SeedInventory(&progress, objects); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The version before the markers is not seeded again
+	if !progress.Complete || progress.Listed != 4 || progress.Existing != 2 || progress.Deleted != 0 || progress.Enqueued != 2 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if keys := queue.keys(); len(keys) != 2 || keys[0] != "images/logo.png#v1" || keys[1] != "undeposited.txt#v1" {
+		t.Fatalf("Unexpected deposits: %v", keys)
+	}
+	if etag := queue.events[0].Etag(); etag != "etag-logo-1" && etag != "etag-undeposited" {
+		t.Errorf("Expected the etag read from S3, got %q", etag)
+	}
+
+	restarted := Progress{Bucket: "bucket", Source: SourceInventory}
+	queue.events = nil
+	if err := seeder.SeedInventory(&restarted, objects); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restarted.Deleted != 1 || restarted.Enqueued != 2 {
+		t.Errorf("Expected the deleted version to be skipped, got %+v", restarted)
+	}
+}
+
+func TestSeedRecordsFailedVersions(t *testing.T) {
+	client := &mockS3Client{}
+	client.addVersion("images/logo.png", "v1", `"etag-logo-1"`)
+	client.addVersion("undeposited.txt", "v1", `"etag-undeposited"`)
+
+	queue := &mockQueue{rejected: map[string]bool{"images/logo.png#v1": true}}
+	progress := Progress{Bucket: "bucket", Source: SourceVersions}
+
+	seeder := NewSeeder(context.Background(), client, db.NewMemoryStore(), queue, "queue-url").WithRate(0)
+	if err := seeder.SeedVersions(&progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !progress.Complete || progress.Enqueued != 1 || progress.Failed != 1 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	expected := FailedVersion{Key: "images/logo.png", VersionId: "v1"}
+	if len(progress.FailedVersions) != 1 || progress.FailedVersions[0] != expected {
+		t.Errorf("Expected the rejected version in the summary, got %v", progress.FailedVersions)
+	}
+}
+
+func TestSeedStopsBeforeDeadline(t *testing.T) {
+	client := &mockS3Client{}
+	client.addVersion("undeposited.txt", "v1", `"etag-undeposited"`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), seedReserve/2)
+	defer cancel()
+
+	queue := &mockQueue{}
+	progress := Progress{Bucket: "bucket", Source: SourceVersions}
+	if err := NewSeeder(ctx, client, db.NewMemoryStore(), queue, "queue-url").SeedVersions(&progress); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if progress.Complete || progress.KeyMarker != "" || len(queue.events) != 0 {
+		t.Errorf("Expected the seeding to stop before the first version, got %+v", progress)
+	}
+}
+
+func TestNewProgress(t *testing.T) {
+	if _, err := NewProgress("bucket", "everything"); err == nil {
+		t.Error("Expected an unknown source to be invalid")
+	}
+
+	progress, err := NewProgress("bucket", SourceInventory)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ProgressKey(progress.Bucket) != "seeding/bucket.json" {
+		t.Errorf("Unexpected progress key: %s", ProgressKey(progress.Bucket))
+	}
+}
@@ -0,0 +1,68 @@
+package sweeping
+
+import (
+	"duracloud/internal/db"
+	"duracloud/internal/progress"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// operation names the sweep progress, it is stored under sweeping/ in the managed bucket
+const operation = "sweeping"
+
+// Progress is the state of the sweep of a bucket that did not finish in one invocation. NextKey
+// is the last checksum record read, the next scheduled run resumes after it. The counts are
+// the totals of the pass so far.
+type Progress struct {
+	Bucket      string    `json:"bucket"`
+	NextKey     string    `json:"nextKey,omitempty"`
+	Started     time.Time `json:"started"`
+	Updated     time.Time `json:"updated"`
+	Complete    bool      `json:"complete"`
+	Records     int       `json:"records"`
+	Orphans     int       `json:"orphans"`
+	Rechecked   int       `json:"rechecked"`
+	Unscheduled int       `json:"unscheduled"`
+}
+
+// NewProgress starts the sweep of a bucket from its first record
+func NewProgress(bucket string) Progress {
+	now := time.Now().UTC()
+	return Progress{Bucket: bucket, Started: now, Updated: now}
+}
+
+// Add records the outcome of sweeping the bucket from NextKey, the pass continues from the last
+// record it reached
+func (p *Progress) Add(result db.SweepResult) {
+	p.Updated = time.Now().UTC()
+	p.Complete = result.Complete
+	p.NextKey = result.NextKey
+	p.Records += result.Records
+	p.Orphans += result.Orphans
+	p.Rechecked += result.Rechecked
+	p.Unscheduled += result.Unscheduled
+}
+
+// ProgressKey returns the managed bucket key of the progress of a bucket
+func ProgressKey(bucket string) string {
+	return progress.Key(operation, bucket)
+}
+
+// BucketName returns the bucket being swept
+func (p Progress) BucketName() string {
+	return p.Bucket
+}
+
+// CompletionKey returns where the totals of a complete sweep are written in the managed bucket
+func (p Progress) CompletionKey() string {
+	return fmt.Sprintf("reports/sweeping/%s/summary-%s.json", p.Bucket, p.Updated.UTC().Format("20060102T150405Z"))
+}
+
+// ProgressStore stores the progress of the sweep of each bucket in the managed bucket
+type ProgressStore = progress.Store[Progress]
+
+func NewProgressStore(s3Client *s3.Client, bucket string) *ProgressStore {
+	return progress.NewStore[Progress](s3Client, bucket, operation)
+}
@@ -0,0 +1,24 @@
+package sweeping
+
+import (
+	"duracloud/internal/db"
+	"testing"
+)
+
+func TestProgressAdd(t *testing.T) {
+	progress := NewProgress("duracloud-test-photos")
+
+	// The first invocation stops before the timeout, the next resumes after its last record
+	progress.Add(db.SweepResult{Pass: db.Pass{NextKey: "b.txt#v1"}, Records: 2, Orphans: 1})
+	if progress.Complete || progress.NextKey != "b.txt#v1" {
+		t.Fatalf("Expected the sweep to continue from b.txt#v1, got %+v", progress)
+	}
+
+	progress.Add(db.SweepResult{Pass: db.Pass{Complete: true}, Records: 3, Rechecked: 1, Unscheduled: 1})
+	if !progress.Complete || progress.NextKey != "" {
+		t.Errorf("Expected a complete sweep, got %+v", progress)
+	}
+	if progress.Records != 5 || progress.Orphans != 1 || progress.Rechecked != 1 || progress.Unscheduled != 1 {
+		t.Errorf("Expected the totals of the pass, got %+v", progress)
+	}
+}
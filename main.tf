@@ -28,12 +28,23 @@ module "duracloud" {
   report_generator_schedule          = "cron(0 8 * * ? *)"
 
   bucket_requested_image_uri           = "${var.repo}/bucket-requested:${var.stack}"
+  bucket_seeder_image_uri              = "${var.repo}/bucket-seeder:${var.stack}"
   checksum_exporter_image_uri          = "${var.repo}/checksum-exporter:${var.stack}"
   checksum_export_csv_report_image_uri = "${var.repo}/checksum-export-csv-report:${var.stack}"
   checksum_failure_image_uri           = "${var.repo}/checksum-failure:${var.stack}"
+  checksum_migrator_image_uri          = "${var.repo}/checksum-migrator:${var.stack}"
+  checksum_rebalancer_image_uri        = "${var.repo}/checksum-rebalancer:${var.stack}"
+  checksum_restore_image_uri           = "${var.repo}/checksum-restore:${var.stack}"
+  checksum_scheduler_image_uri         = "${var.repo}/checksum-scheduler:${var.stack}"
+  checksum_sweeper_image_uri           = "${var.repo}/checksum-sweeper:${var.stack}"
   checksum_verification_image_uri      = "${var.repo}/checksum-verification:${var.stack}"
   file_deleted_image_uri               = "${var.repo}/file-deleted:${var.stack}"
   file_uploaded_image_uri              = "${var.repo}/file-uploaded:${var.stack}"
+  inventory_reconciler_image_uri       = "${var.repo}/inventory-reconciler:${var.stack}"
   inventory_unwrap_image_uri           = "${var.repo}/inventory-unwrap:${var.stack}"
+  legacy_import_image_uri              = "${var.repo}/legacy-import:${var.stack}"
+  list_failures_image_uri              = "${var.repo}/list-failures:${var.stack}"
+  manifest_reconciler_image_uri        = "${var.repo}/manifest-reconciler:${var.stack}"
   report_generator_image_uri           = "${var.repo}/report-generator:${var.stack}"
+  verify_requested_image_uri           = "${var.repo}/verify-requested:${var.stack}"
 }
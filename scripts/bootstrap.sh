@@ -21,14 +21,25 @@ aws s3api put-public-access-block \
 # Create ECR repositories for each function
 FUNCTIONS=(
   "bucket-requested"
+  "bucket-seeder"
   "checksum-export-csv-report"
   "checksum-exporter"
   "checksum-failure"
+  "checksum-migrator"
+  "checksum-rebalancer"
+  "checksum-restore"
+  "checksum-scheduler"
+  "checksum-sweeper"
   "checksum-verification"
   "file-deleted"
   "file-uploaded"
+  "inventory-reconciler"
   "inventory-unwrap"
+  "legacy-import"
+  "list-failures"
+  "manifest-reconciler"
   "report-generator"
+  "verify-requested"
 )
 
 for function in "${FUNCTIONS[@]}"; do
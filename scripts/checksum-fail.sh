@@ -3,8 +3,9 @@
 STACK=${1:-duracloud-lyrasis}
 BUCKET=${2}
 OBJECT=${3}
+VERSION=${4:-null}
 
-TABLE_NAME="${STACK}-checksum-table"
+TABLE_NAME="${STACK}-checksum-version-table"
 
 if [ -n "$BUCKET" ] && [ -n "$OBJECT" ]; then
     echo "Forcing checksum failure for: $BUCKET/$OBJECT"
@@ -12,7 +13,7 @@ if [ -n "$BUCKET" ] && [ -n "$OBJECT" ]; then
         --table-name "$TABLE_NAME" \
         --key "{
             \"BucketName\": {\"S\": \"$BUCKET\"},
-            \"ObjectKey\": {\"S\": \"$OBJECT\"}
+            \"VersionKey\": {\"S\": \"$OBJECT#$VERSION\"}
         }" \
         --update-expression "SET LastChecksumSuccess = :false_val" \
         --expression-attribute-values "{
@@ -20,7 +21,7 @@ if [ -n "$BUCKET" ] && [ -n "$OBJECT" ]; then
         }" \
         --return-values UPDATED_NEW
 else
-    echo "Usage: $0 [stack-name] <bucket-name> <object-key>"
+    echo "Usage: $0 [stack-name] <bucket-name> <object-key> [version-id]"
     echo "Example: $0 duracloud-lyrasis my-bucket path/to/file.txt"
     exit 1
 fi
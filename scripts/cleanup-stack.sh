@@ -20,14 +20,15 @@ aws s3api list-buckets \
 
 clear_dynamodb_table() {
     local TABLE_NAME=$1
+    local SORT_KEY=${2:-VersionKey}
     echo "Clearing table: $TABLE_NAME"
     local temp_dir=$(mktemp -d)
 
     aws dynamodb scan \
         --table-name "$TABLE_NAME" \
-        --projection-expression "BucketName, ObjectKey" \
+        --projection-expression "BucketName, $SORT_KEY" \
         --max-items 1000 \
-        | jq -c '.Items[] | {DeleteRequest: {Key: {BucketName: .BucketName, ObjectKey: .ObjectKey}}}' \
+        | jq -c --arg sk "$SORT_KEY" '.Items[] | {DeleteRequest: {Key: {BucketName: .BucketName, ($sk): .[$sk]}}}' \
         | split -l 25 - "$temp_dir/items_"
 
     # Process each batch file
@@ -46,7 +47,7 @@ clear_dynamodb_table() {
     rm -rf "$temp_dir"
 }
 
-clear_dynamodb_table "${STACK}-checksum-table"
-clear_dynamodb_table "${STACK}-checksum-scheduler-table"
+clear_dynamodb_table "${STACK}-checksum-version-table"
+clear_dynamodb_table "${STACK}-checksum-version-scheduler-table"
 
 exit 0
@@ -3,8 +3,9 @@
 STACK=${1:-duracloud-lyrasis}
 BUCKET=${2}
 OBJECT=${3}
+VERSION=${4:-null}
 
-TABLE_NAME="${STACK}-checksum-scheduler-table"
+TABLE_NAME="${STACK}-checksum-version-scheduler-table"
 
 # Calculate TTL for 1 day ago (in Unix timestamp)
 # Check if we're on macOS (BSD date) or Linux (GNU date)
@@ -18,6 +19,12 @@ else
     ISO_DATE=$(date -d '1 day ago' --iso-8601=seconds)
 fi
 
+# Unversioned objects have the null version and no VersionId
+VERSION_ATTR=""
+if [ "$VERSION" != "null" ]; then
+    VERSION_ATTR="\"VersionId\": {\"S\": \"$VERSION\"},"
+fi
+
 if [ -n "$BUCKET" ] && [ -n "$OBJECT" ]; then
     echo "Creating scheduler: $BUCKET/$OBJECT"
     aws dynamodb put-item \
@@ -25,12 +32,14 @@ if [ -n "$BUCKET" ] && [ -n "$OBJECT" ]; then
         --item "{
             \"BucketName\": {\"S\": \"$BUCKET\"},
             \"ObjectKey\": {\"S\": \"$OBJECT\"},
+            \"VersionKey\": {\"S\": \"$OBJECT#$VERSION\"},
+            $VERSION_ATTR
             \"NextChecksumDate\": {\"S\": \"$ISO_DATE\"},
             \"TTL\": {\"N\": \"$EXPIRED_TTL\"}
         }" \
         --return-values NONE
 else
-    echo "Usage: $0 [stack-name] <bucket-name> <object-key>"
+    echo "Usage: $0 [stack-name] <bucket-name> <object-key> [version-id]"
     echo "Example: $0 duracloud-lyrasis my-bucket path/to/file.txt"
     exit 1
 fi
@@ -105,7 +105,7 @@ resource "aws_cloudwatch_metric_alarm" "dynamodb_checksum_table_write_capacity_a
   treat_missing_data  = "notBreaching"
 
   dimensions = {
-    TableName = aws_dynamodb_table.checksum_table.name
+    TableName = aws_dynamodb_table.checksum_version_table.name
   }
 
   alarm_actions = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
@@ -129,7 +129,7 @@ resource "aws_cloudwatch_metric_alarm" "dynamodb_scheduler_table_write_capacity_
   treat_missing_data  = "notBreaching"
 
   dimensions = {
-    TableName = aws_dynamodb_table.checksum_scheduler_table.name
+    TableName = aws_dynamodb_table.checksum_version_scheduler_table.name
   }
 
   alarm_actions = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
@@ -140,6 +140,52 @@ resource "aws_cloudwatch_metric_alarm" "dynamodb_scheduler_table_write_capacity_
   }
 }
 
+resource "aws_cloudwatch_metric_alarm" "checksum_sweeper_function_error_alarm" {
+  alarm_name          = "${local.stack_name}-checksum-sweeper-errors"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = "1"
+  metric_name         = "Errors"
+  namespace           = "AWS/Lambda"
+  period              = "300"
+  statistic           = "Sum"
+  threshold           = "0"
+  alarm_description   = "Error sweeping checksum records without a scheduled verification"
+  treat_missing_data  = "notBreaching"
+
+  dimensions = {
+    FunctionName = aws_lambda_function.checksum_sweeper_function.function_name
+  }
+
+  alarm_actions = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
+
+  tags = {
+    Name = "${local.stack_name}-checksum-sweeper-errors"
+  }
+}
+
+resource "aws_cloudwatch_metric_alarm" "inventory_reconciler_function_error_alarm" {
+  alarm_name          = "${local.stack_name}-inventory-reconciler-errors"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = "1"
+  metric_name         = "Errors"
+  namespace           = "AWS/Lambda"
+  period              = "300"
+  statistic           = "Sum"
+  threshold           = "0"
+  alarm_description   = "Error reconciling inventories with the checksum table"
+  treat_missing_data  = "notBreaching"
+
+  dimensions = {
+    FunctionName = aws_lambda_function.inventory_reconciler_function.function_name
+  }
+
+  alarm_actions = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
+
+  tags = {
+    Name = "${local.stack_name}-inventory-reconciler-errors"
+  }
+}
+
 resource "aws_cloudwatch_metric_alarm" "report_generator_function_error_alarm" {
   alarm_name          = "${local.stack_name}-report-generator-errors"
   comparison_operator = "GreaterThanThreshold"
@@ -187,6 +233,30 @@ resource "aws_cloudwatch_metric_alarm" "sqs_object_created_alarm" {
   }
 }
 
+resource "aws_cloudwatch_metric_alarm" "sqs_manifest_uploaded_alarm" {
+  alarm_name          = "${local.stack_name}-manifest-uploaded-dlq-messages"
+  comparison_operator = "GreaterThanOrEqualToThreshold"
+  evaluation_periods  = "1"
+  metric_name         = "ApproximateNumberOfVisibleMessages"
+  namespace           = "AWS/SQS"
+  period              = "300"
+  statistic           = "Average"
+  threshold           = "1"
+  alarm_description   = "Messages present in manifest uploaded DLQ"
+  treat_missing_data  = "notBreaching"
+
+  dimensions = {
+    QueueName = aws_sqs_queue.manifest_uploaded_dlq.name
+  }
+
+  alarm_actions = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
+  ok_actions    = local.enable_email_alerts ? [aws_sns_topic.email_alert_topic.arn] : []
+
+  tags = {
+    Name = "${local.stack_name}-manifest-uploaded-dlq-messages"
+  }
+}
+
 resource "aws_cloudwatch_metric_alarm" "sqs_object_deleted_alarm" {
   alarm_name          = "${local.stack_name}-object-deleted-dlq-messages"
   comparison_operator = "GreaterThanOrEqualToThreshold"
@@ -1,4 +1,80 @@
+resource "aws_dynamodb_table" "checksum_version_table" {
+  name         = "${local.stack_name}-checksum-version-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "BucketName"
+  range_key    = "VersionKey"
+
+  attribute {
+    name = "BucketName"
+    type = "S"
+  }
+
+  attribute {
+    name = "VersionKey"
+    type = "S"
+  }
+
+  attribute {
+    name = "FailureKey"
+    type = "S"
+  }
+
+  global_secondary_index {
+    name            = "FailuresIndex"
+    hash_key        = "BucketName"
+    range_key       = "FailureKey"
+    projection_type = "ALL"
+  }
+
+  stream_enabled   = true
+  stream_view_type = "NEW_AND_OLD_IMAGES"
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-checksum-version-table"
+  }
+}
+
+resource "aws_dynamodb_table" "checksum_version_scheduler_table" {
+  name         = "${local.stack_name}-checksum-version-scheduler-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "BucketName"
+  range_key    = "VersionKey"
+
+  attribute {
+    name = "BucketName"
+    type = "S"
+  }
+
+  attribute {
+    name = "VersionKey"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "TTL"
+    enabled        = true
+  }
+
+  stream_enabled   = true
+  stream_view_type = "OLD_IMAGE"
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-checksum-version-scheduler-table"
+  }
+}
+
+# The tables keyed by ObjectKey before records were version-aware, the checksum-migrator function
+# copies their records to the version tables. They are removed once retire_object_key_tables is set.
 resource "aws_dynamodb_table" "checksum_table" {
+  count        = local.retire_object_key_tables ? 0 : 1
   name         = "${local.stack_name}-checksum-table"
   billing_mode = "PAY_PER_REQUEST"
   hash_key     = "BucketName"
@@ -26,7 +102,13 @@ resource "aws_dynamodb_table" "checksum_table" {
   }
 }
 
+moved {
+  from = aws_dynamodb_table.checksum_table
+  to   = aws_dynamodb_table.checksum_table[0]
+}
+
 resource "aws_dynamodb_table" "checksum_scheduler_table" {
+  count        = local.retire_object_key_tables ? 0 : 1
   name         = "${local.stack_name}-checksum-scheduler-table"
   billing_mode = "PAY_PER_REQUEST"
   hash_key     = "BucketName"
@@ -58,3 +140,110 @@ resource "aws_dynamodb_table" "checksum_scheduler_table" {
     Name = "${local.stack_name}-checksum-scheduler-table"
   }
 }
+
+moved {
+  from = aws_dynamodb_table.checksum_scheduler_table
+  to   = aws_dynamodb_table.checksum_scheduler_table[0]
+}
+
+resource "aws_dynamodb_table" "checksum_history_table" {
+  name         = "${local.stack_name}-checksum-history-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "ObjectId"
+  range_key    = "EventDate"
+
+  attribute {
+    name = "ObjectId"
+    type = "S"
+  }
+
+  attribute {
+    name = "EventDate"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-checksum-history-table"
+  }
+}
+
+resource "aws_dynamodb_table" "checksum_budget_table" {
+  name         = "${local.stack_name}-checksum-budget-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "Period"
+  range_key    = "Start"
+
+  attribute {
+    name = "Period"
+    type = "S"
+  }
+
+  attribute {
+    name = "Start"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "TTL"
+    enabled        = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-checksum-budget-table"
+  }
+}
+
+resource "aws_dynamodb_table" "checksum_restore_table" {
+  name         = "${local.stack_name}-checksum-restore-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "BucketName"
+  range_key    = "VersionKey"
+
+  attribute {
+    name = "BucketName"
+    type = "S"
+  }
+
+  attribute {
+    name = "VersionKey"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "TTL"
+    enabled        = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-checksum-restore-table"
+  }
+}
+
+resource "aws_dynamodb_table" "legacy_checksum_table" {
+  name         = "${local.stack_name}-legacy-checksum-table"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "BucketName"
+  range_key    = "ObjectKey"
+
+  attribute {
+    name = "BucketName"
+    type = "S"
+  }
+
+  attribute {
+    name = "ObjectKey"
+    type = "S"
+  }
+
+  point_in_time_recovery {
+    enabled = true
+  }
+
+  tags = {
+    Name = "${local.stack_name}-legacy-checksum-table"
+  }
+}
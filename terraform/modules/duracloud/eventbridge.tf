@@ -16,6 +16,40 @@ resource "aws_cloudwatch_event_target" "checksum_exporter_target" {
   arn       = aws_lambda_function.checksum_exporter_function.arn
 }
 
+resource "aws_cloudwatch_event_rule" "checksum_sweeper_schedule" {
+  name                = "${local.stack_name}-checksum-sweeper-schedule"
+  description         = "Trigger the sweep of checksum records without a scheduled verification"
+  schedule_expression = local.checksum_sweeper_schedule
+  state               = "ENABLED"
+
+  tags = {
+    Name = "${local.stack_name}-checksum-sweeper-schedule"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "checksum_sweeper_target" {
+  rule      = aws_cloudwatch_event_rule.checksum_sweeper_schedule.name
+  target_id = "ChecksumSweeperTarget"
+  arn       = aws_lambda_function.checksum_sweeper_function.arn
+}
+
+resource "aws_cloudwatch_event_rule" "inventory_reconciler_schedule" {
+  name                = "${local.stack_name}-inventory-reconciler-schedule"
+  description         = "Trigger inventory reconciliation with the checksum table"
+  schedule_expression = local.inventory_reconciler_schedule
+  state               = "ENABLED"
+
+  tags = {
+    Name = "${local.stack_name}-inventory-reconciler-schedule"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "inventory_reconciler_target" {
+  rule      = aws_cloudwatch_event_rule.inventory_reconciler_schedule.name
+  target_id = "InventoryReconcilerTarget"
+  arn       = aws_lambda_function.inventory_reconciler_function.arn
+}
+
 resource "aws_cloudwatch_event_rule" "report_generator_schedule" {
   name                = "${local.stack_name}-report-generator-schedule"
   description         = "Trigger stats report generation"
@@ -41,6 +75,10 @@ resource "aws_cloudwatch_event_rule" "object_created_rule" {
   event_pattern = jsonencode({
     source      = ["aws.s3"]
     detail-type = ["Object Created"]
+    # Replication buckets send EventBridge events for restores, their objects are not deposits
+    resources = [{
+      anything-but = { suffix = "-repl" }
+    }]
     detail = {
       bucket = {
         name = [{
@@ -64,11 +102,16 @@ resource "aws_cloudwatch_event_target" "object_created_target" {
 
 resource "aws_cloudwatch_event_rule" "object_deleted_rule" {
   name        = "${local.stack_name}-object-deleted-rule"
-  description = "S3 Object Deleted Events"
+  description = "S3 Object Deleted and Lifecycle Expiration Events"
 
   event_pattern = jsonencode({
     source      = ["aws.s3"]
-    detail-type = ["Object Deleted"]
+    # Noncurrent versions are expired by the lifecycle rule rather than deleted
+    detail-type = ["Object Deleted", "Lifecycle Expiration"]
+    # Not replication buckets (see object_created_rule)
+    resources = [{
+      anything-but = { suffix = "-repl" }
+    }]
     detail = {
       bucket = {
         name = [{
@@ -89,3 +132,100 @@ resource "aws_cloudwatch_event_target" "object_deleted_target" {
   arn       = aws_sqs_queue.object_deleted.arn
   role_arn  = aws_iam_role.events_invoke_sqs_role.arn
 }
+
+# Manifest Uploaded Rule
+resource "aws_cloudwatch_event_rule" "manifest_uploaded_rule" {
+  name        = "${local.stack_name}-manifest-uploaded-rule"
+  description = "S3 Object Created Events for deposit manifests"
+
+  event_pattern = jsonencode({
+    source      = ["aws.s3"]
+    detail-type = ["Object Created"]
+    # Not replication buckets (see object_created_rule)
+    resources = [{
+      anything-but = { suffix = "-repl" }
+    }]
+    detail = {
+      bucket = {
+        name = [{
+          prefix = "${local.stack_name}-"
+        }]
+      }
+      object = {
+        key = [{
+          prefix = "duracloud-manifests/"
+        }]
+      }
+    }
+  })
+
+  tags = {
+    Name = "${local.stack_name}-manifest-uploaded-rule"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "manifest_uploaded_target" {
+  rule      = aws_cloudwatch_event_rule.manifest_uploaded_rule.name
+  target_id = "SendToSQSOnManifest"
+  arn       = aws_sqs_queue.manifest_uploaded.arn
+  role_arn  = aws_iam_role.events_invoke_sqs_role.arn
+}
+
+# Object Restore Completed Rule
+resource "aws_cloudwatch_event_rule" "object_restore_completed_rule" {
+  name        = "${local.stack_name}-object-restore-completed-rule"
+  description = "S3 Object Restore Completed Events for replication buckets"
+
+  event_pattern = jsonencode({
+    source      = ["aws.s3"]
+    detail-type = ["Object Restore Completed"]
+    detail = {
+      bucket = {
+        name = [{
+          wildcard = "${local.stack_name}-*-repl"
+        }]
+      }
+    }
+  })
+
+  tags = {
+    Name = "${local.stack_name}-object-restore-completed-rule"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "object_restore_completed_target" {
+  rule      = aws_cloudwatch_event_rule.object_restore_completed_rule.name
+  target_id = "ChecksumRestoreTarget"
+  arn       = aws_lambda_function.checksum_restore_function.arn
+}
+
+# Verify Requested Rule
+resource "aws_cloudwatch_event_rule" "verify_requested_rule" {
+  name        = "${local.stack_name}-verify-requested-rule"
+  description = "S3 Object Created Events for verification requests"
+
+  event_pattern = jsonencode({
+    source      = ["aws.s3"]
+    detail-type = ["Object Created"]
+    detail = {
+      bucket = {
+        name = [aws_s3_bucket.bucket_requested.bucket]
+      }
+      object = {
+        key = [{
+          suffix = "verify-requested.txt"
+        }]
+      }
+    }
+  })
+
+  tags = {
+    Name = "${local.stack_name}-verify-requested-rule"
+  }
+}
+
+resource "aws_cloudwatch_event_target" "verify_requested_target" {
+  rule      = aws_cloudwatch_event_rule.verify_requested_rule.name
+  target_id = "VerifyRequestedTarget"
+  arn       = aws_lambda_function.verify_requested_function.arn
+}
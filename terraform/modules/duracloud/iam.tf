@@ -129,6 +129,7 @@ resource "aws_iam_role_policy" "events_invoke_sqs_policy" {
         Effect = "Allow"
         Action = "sqs:SendMessage"
         Resource = [
+          aws_sqs_queue.manifest_uploaded.arn,
           aws_sqs_queue.object_created.arn,
           aws_sqs_queue.object_deleted.arn
         ]
@@ -211,6 +212,85 @@ resource "aws_iam_role_policy" "bucket_requested_function_policy" {
   })
 }
 
+# Bucket Seeder Function IAM
+resource "aws_iam_role" "bucket_seeder_function_role" {
+  name = "${local.stack_name}-bucket-seeder-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-bucket-seeder-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "bucket_seeder_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.bucket_seeder_function_role.name
+}
+
+resource "aws_iam_role_policy" "bucket_seeder_function_policy" {
+  name = "${local.stack_name}-bucket-seeder-function-policy"
+  role = aws_iam_role.bucket_seeder_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket",
+          "s3:ListBucketVersions"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:DeleteObject",
+          "s3:PutObject"
+        ]
+        Resource = [
+          "${aws_s3_bucket.managed_bucket.arn}/reports/seeding/*",
+          "${aws_s3_bucket.managed_bucket.arn}/seeding/*"
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem"
+        ]
+        Resource = aws_dynamodb_table.checksum_version_table.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sqs:SendMessage"
+        ]
+        Resource = aws_sqs_queue.object_created.arn
+      }
+    ]
+  })
+}
+
 # Checksum Exporter Function IAM
 resource "aws_iam_role" "checksum_exporter_function_role" {
   name = "${local.stack_name}-checksum-exporter-function-role"
@@ -253,8 +333,8 @@ resource "aws_iam_role_policy" "checksum_exporter_function_policy" {
           "dynamodb:DescribeExport"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_table.arn,
-          "${aws_dynamodb_table.checksum_table.arn}/export/*"
+          aws_dynamodb_table.checksum_version_table.arn,
+          "${aws_dynamodb_table.checksum_version_table.arn}/export/*"
         ]
       },
       {
@@ -315,6 +395,15 @@ resource "aws_iam_role_policy" "checksum_export_csv_report_function_policy" {
   policy = jsonencode({
     Version = "2012-10-17"
     Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:Query"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_history_table.arn
+        ]
+      },
       {
         Effect = "Allow"
         Action = [
@@ -391,7 +480,7 @@ resource "aws_iam_role_policy" "checksum_failure_function_policy" {
           "dynamodb:ListStreams"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_table.stream_arn
+          aws_dynamodb_table.checksum_version_table.stream_arn
         ]
       },
       {
@@ -405,9 +494,10 @@ resource "aws_iam_role_policy" "checksum_failure_function_policy" {
   })
 }
 
-# Checksum Verification Function IAM
-resource "aws_iam_role" "checksum_verification_function_role" {
-  name = "${local.stack_name}-checksum-verification-function-role"
+# Checksum Migrator Function IAM
+resource "aws_iam_role" "checksum_migrator_function_role" {
+  count = local.retire_object_key_tables ? 0 : 1
+  name  = "${local.stack_name}-checksum-migrator-function-role"
 
   assume_role_policy = jsonencode({
     Version = "2012-10-17"
@@ -423,18 +513,20 @@ resource "aws_iam_role" "checksum_verification_function_role" {
   })
 
   tags = {
-    Name = "${local.stack_name}-checksum-verification-function-role"
+    Name = "${local.stack_name}-checksum-migrator-function-role"
   }
 }
 
-resource "aws_iam_role_policy_attachment" "checksum_verification_function_basic" {
+resource "aws_iam_role_policy_attachment" "checksum_migrator_function_basic" {
+  count      = local.retire_object_key_tables ? 0 : 1
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
-  role       = aws_iam_role.checksum_verification_function_role.name
+  role       = aws_iam_role.checksum_migrator_function_role[0].name
 }
 
-resource "aws_iam_role_policy" "checksum_verification_function_policy" {
-  name = "${local.stack_name}-checksum-verification-function-policy"
-  role = aws_iam_role.checksum_verification_function_role.id
+resource "aws_iam_role_policy" "checksum_migrator_function_policy" {
+  count = local.retire_object_key_tables ? 0 : 1
+  name  = "${local.stack_name}-checksum-migrator-function-policy"
+  role  = aws_iam_role.checksum_migrator_function_role[0].id
 
   policy = jsonencode({
     Version = "2012-10-17"
@@ -442,50 +534,52 @@ resource "aws_iam_role_policy" "checksum_verification_function_policy" {
       {
         Effect = "Allow"
         Action = [
-          "dynamodb:DeleteItem",
-          "dynamodb:GetItem",
-          "dynamodb:PutItem",
-          "dynamodb:UpdateItem"
+          "dynamodb:Scan"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_table.arn,
-          aws_dynamodb_table.checksum_scheduler_table.arn
+          aws_dynamodb_table.checksum_table[0].arn
         ]
       },
       {
         Effect = "Allow"
         Action = [
-          "dynamodb:DescribeStream",
-          "dynamodb:GetRecords",
-          "dynamodb:GetShardIterator",
-          "dynamodb:ListStreams"
+          "dynamodb:GetItem"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_scheduler_table.stream_arn
+          aws_dynamodb_table.checksum_scheduler_table[0].arn
         ]
       },
       {
         Effect = "Allow"
         Action = [
-          "s3:GetObject",
-          "s3:GetObjectVersion"
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn,
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
         ]
-        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
       },
       {
+        # Records are keyed by the current version of their object, without ListBucket a
+        # deleted object reads as access denied rather than not found
         Effect = "Allow"
         Action = [
-          "sns:Publish"
+          "s3:GetObject",
+          "s3:GetObjectVersion",
+          "s3:ListBucket"
+        ]
+        Resource = [
+          "arn:aws:s3:::${local.stack_name}-*",
+          "arn:aws:s3:::${local.stack_name}-*/*"
         ]
-        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
       }
     ]
   })
 }
 
-# File Deleted Function IAM
-resource "aws_iam_role" "file_deleted_function_role" {
-  name = "${local.stack_name}-file-deleted-function-role"
+# Checksum Rebalancer Function IAM
+resource "aws_iam_role" "checksum_rebalancer_function_role" {
+  name = "${local.stack_name}-checksum-rebalancer-function-role"
 
   assume_role_policy = jsonencode({
     Version = "2012-10-17"
@@ -501,23 +595,18 @@ resource "aws_iam_role" "file_deleted_function_role" {
   })
 
   tags = {
-    Name = "${local.stack_name}-file-deleted-function-role"
+    Name = "${local.stack_name}-checksum-rebalancer-function-role"
   }
 }
 
-resource "aws_iam_role_policy_attachment" "file_deleted_function_basic" {
+resource "aws_iam_role_policy_attachment" "checksum_rebalancer_function_basic" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
-  role       = aws_iam_role.file_deleted_function_role.name
-}
-
-resource "aws_iam_role_policy_attachment" "file_deleted_function_sqs" {
-  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole"
-  role       = aws_iam_role.file_deleted_function_role.name
+  role       = aws_iam_role.checksum_rebalancer_function_role.name
 }
 
-resource "aws_iam_role_policy" "file_deleted_function_policy" {
-  name = "${local.stack_name}-file-deleted-function-policy"
-  role = aws_iam_role.file_deleted_function_role.id
+resource "aws_iam_role_policy" "checksum_rebalancer_function_policy" {
+  name = "${local.stack_name}-checksum-rebalancer-function-policy"
+  role = aws_iam_role.checksum_rebalancer_function_role.id
 
   policy = jsonencode({
     Version = "2012-10-17"
@@ -525,20 +614,62 @@ resource "aws_iam_role_policy" "file_deleted_function_policy" {
       {
         Effect = "Allow"
         Action = [
-          "dynamodb:DeleteItem"
+          "dynamodb:GetItem",
+          "dynamodb:UpdateItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem",
+          "dynamodb:Query"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetBucketTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket"
+        ]
+        Resource = aws_s3_bucket.managed_bucket.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/rebalancing/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:DeleteObject",
+          "s3:PutObject"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_table.arn,
-          aws_dynamodb_table.checksum_scheduler_table.arn
+          "${aws_s3_bucket.managed_bucket.arn}/reports/rebalancing/*",
+          "${aws_s3_bucket.managed_bucket.arn}/rebalancing/*"
         ]
       }
     ]
   })
 }
 
-# File Uploaded Function IAM
-resource "aws_iam_role" "file_uploaded_function_role" {
-  name = "${local.stack_name}-file-uploaded-function-role"
+# Checksum Scheduler Function IAM
+resource "aws_iam_role" "checksum_scheduler_function_role" {
+  name = "${local.stack_name}-checksum-scheduler-function-role"
 
   assume_role_policy = jsonencode({
     Version = "2012-10-17"
@@ -554,52 +685,55 @@ resource "aws_iam_role" "file_uploaded_function_role" {
   })
 
   tags = {
-    Name = "${local.stack_name}-file-uploaded-function-role"
+    Name = "${local.stack_name}-checksum-scheduler-function-role"
   }
 }
 
-resource "aws_iam_role_policy_attachment" "file_uploaded_function_basic" {
+resource "aws_iam_role_policy_attachment" "checksum_scheduler_function_basic" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
-  role       = aws_iam_role.file_uploaded_function_role.name
-}
-
-resource "aws_iam_role_policy_attachment" "file_uploaded_function_sqs" {
-  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole"
-  role       = aws_iam_role.file_uploaded_function_role.name
+  role       = aws_iam_role.checksum_scheduler_function_role.name
 }
 
-resource "aws_iam_role_policy" "file_uploaded_function_policy" {
-  name = "${local.stack_name}-file-uploaded-function-policy"
-  role = aws_iam_role.file_uploaded_function_role.id
+resource "aws_iam_role_policy" "checksum_scheduler_function_policy" {
+  name = "${local.stack_name}-checksum-scheduler-function-policy"
+  role = aws_iam_role.checksum_scheduler_function_role.id
 
   policy = jsonencode({
     Version = "2012-10-17"
     Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:Query",
+          "dynamodb:UpdateItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
       {
         Effect = "Allow"
         Action = [
           "dynamodb:PutItem"
         ]
         Resource = [
-          aws_dynamodb_table.checksum_table.arn,
-          aws_dynamodb_table.checksum_scheduler_table.arn
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
         ]
       },
       {
         Effect = "Allow"
         Action = [
-          "s3:GetObject",
-          "s3:GetObjectVersion"
+          "s3:GetBucketTagging"
         ]
-        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
       }
     ]
   })
 }
 
-# Inventory Unwrap Function IAM
-resource "aws_iam_role" "inventory_unwrap_function_role" {
-  name = "${local.stack_name}-inventory-unwrap-function-role"
+# Checksum Sweeper Function IAM
+resource "aws_iam_role" "checksum_sweeper_function_role" {
+  name = "${local.stack_name}-checksum-sweeper-function-role"
 
   assume_role_policy = jsonencode({
     Version = "2012-10-17"
@@ -615,18 +749,18 @@ resource "aws_iam_role" "inventory_unwrap_function_role" {
   })
 
   tags = {
-    Name = "${local.stack_name}-inventory-unwrap-function-role"
+    Name = "${local.stack_name}-checksum-sweeper-function-role"
   }
 }
 
-resource "aws_iam_role_policy_attachment" "inventory_unwrap_function_basic" {
+resource "aws_iam_role_policy_attachment" "checksum_sweeper_function_basic" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
-  role       = aws_iam_role.inventory_unwrap_function_role.name
+  role       = aws_iam_role.checksum_sweeper_function_role.name
 }
 
-resource "aws_iam_role_policy" "inventory_unwrap_function_policy" {
-  name = "${local.stack_name}-inventory-unwrap-function-policy"
-  role = aws_iam_role.inventory_unwrap_function_role.id
+resource "aws_iam_role_policy" "checksum_sweeper_function_policy" {
+  name = "${local.stack_name}-checksum-sweeper-function-policy"
+  role = aws_iam_role.checksum_sweeper_function_role.id
 
   policy = jsonencode({
     Version = "2012-10-17"
@@ -634,17 +768,29 @@ resource "aws_iam_role_policy" "inventory_unwrap_function_policy" {
       {
         Effect = "Allow"
         Action = [
-          "s3:ListBucket",
-          "s3:ListBucketMultipartUploads",
-          "s3:ListMultipartUploadParts",
-          "s3:AbortMultipartUpload",
-          "s3:GetObject",
-          "s3:PutObject",
-          "s3:PutObjectAcl"
+          "dynamodb:Query",
+          "dynamodb:UpdateItem"
         ]
         Resource = [
-          "${aws_s3_bucket.managed_bucket.arn}/inventory/*"
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListAllMyBuckets"
         ]
+        Resource = "*"
       },
       {
         Effect = "Allow"
@@ -652,14 +798,46 @@ resource "aws_iam_role_policy" "inventory_unwrap_function_policy" {
           "s3:ListBucket"
         ]
         Resource = aws_s3_bucket.managed_bucket.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/sweeping/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:DeleteObject",
+          "s3:PutObject"
+        ]
+        Resource = [
+          "${aws_s3_bucket.managed_bucket.arn}/reports/sweeping/*",
+          "${aws_s3_bucket.managed_bucket.arn}/sweeping/*"
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetBucketTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sns:Publish"
+        ]
+        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
       }
     ]
   })
 }
 
-# Report Generator Function IAM
-resource "aws_iam_role" "report_generator_function_role" {
-  name = "${local.stack_name}-report-generator-function-role"
+# Checksum Verification Function IAM
+resource "aws_iam_role" "checksum_verification_function_role" {
+  name = "${local.stack_name}-checksum-verification-function-role"
 
   assume_role_policy = jsonencode({
     Version = "2012-10-17"
@@ -675,18 +853,18 @@ resource "aws_iam_role" "report_generator_function_role" {
   })
 
   tags = {
-    Name = "${local.stack_name}-report-generator-function-role"
+    Name = "${local.stack_name}-checksum-verification-function-role"
   }
 }
 
-resource "aws_iam_role_policy_attachment" "report_generator_function_basic" {
+resource "aws_iam_role_policy_attachment" "checksum_verification_function_basic" {
   policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
-  role       = aws_iam_role.report_generator_function_role.name
+  role       = aws_iam_role.checksum_verification_function_role.name
 }
 
-resource "aws_iam_role_policy" "report_generator_function_policy" {
-  name = "${local.stack_name}-report-generator-function-policy"
-  role = aws_iam_role.report_generator_function_role.id
+resource "aws_iam_role_policy" "checksum_verification_function_policy" {
+  name = "${local.stack_name}-checksum-verification-function-policy"
+  role = aws_iam_role.checksum_verification_function_role.id
 
   policy = jsonencode({
     Version = "2012-10-17"
@@ -694,7 +872,792 @@ resource "aws_iam_role_policy" "report_generator_function_policy" {
       {
         Effect = "Allow"
         Action = [
-          "s3:GetBucketTagging",
+          "s3:GetBucketTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_history_table.arn,
+          aws_dynamodb_table.checksum_restore_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:UpdateItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_budget_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:DeleteItem",
+          "dynamodb:GetItem",
+          "dynamodb:PutItem",
+          "dynamodb:UpdateItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn,
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:DescribeStream",
+          "dynamodb:GetRecords",
+          "dynamodb:GetShardIterator",
+          "dynamodb:ListStreams"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_scheduler_table.stream_arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket",
+          "s3:ListBucketVersions"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:DeleteObject",
+          "s3:PutObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/checkpoints/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sns:Publish"
+        ]
+        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
+      }
+    ]
+  })
+}
+
+# Restores of archived replicas complete with an EventBridge event, which verification enables
+# on replication buckets created before Setup did
+resource "aws_iam_role_policy" "checksum_verification_restore_policy" {
+  count = local.replica_restores ? 1 : 0
+
+  name = "${local.stack_name}-checksum-verification-restore-policy"
+  role = aws_iam_role.checksum_verification_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:RestoreObject"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetBucketNotification",
+          "s3:PutBucketNotification"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl"
+      }
+    ]
+  })
+}
+
+# Repairs copy the replica over the primary, so writes are only granted when repair is on
+resource "aws_iam_role_policy" "checksum_verification_repair_policy" {
+  count = local.checksum_repair_mode == "on" ? 1 : 0
+
+  name = "${local.stack_name}-checksum-verification-repair-policy"
+  role = aws_iam_role.checksum_verification_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObjectVersionTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:AbortMultipartUpload",
+          "s3:PutObject",
+          "s3:PutObjectTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Deny"
+        Action = [
+          "s3:PutObject"
+        ]
+        Resource = [
+          "arn:aws:s3:::${local.stack_name}-*-repl/*",
+          "arn:aws:s3:::${local.stack_name}-*-logs/*"
+        ]
+      }
+    ]
+  })
+}
+
+# Checksum Restore Function IAM
+resource "aws_iam_role" "checksum_restore_function_role" {
+  name = "${local.stack_name}-checksum-restore-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-checksum-restore-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "checksum_restore_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.checksum_restore_function_role.name
+}
+
+resource "aws_iam_role_policy" "checksum_restore_function_policy" {
+  name = "${local.stack_name}-checksum-restore-function-policy"
+  role = aws_iam_role.checksum_restore_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_history_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:DeleteItem",
+          "dynamodb:GetItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_restore_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket",
+          "s3:ListBucketVersions"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:RestoreObject"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*-repl/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sns:Publish"
+        ]
+        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
+      }
+    ]
+  })
+}
+
+# A restored replica may repair its primary, with the same permissions as verification
+resource "aws_iam_role_policy" "checksum_restore_repair_policy" {
+  count = local.checksum_repair_mode == "on" ? 1 : 0
+
+  name   = "${local.stack_name}-checksum-restore-repair-policy"
+  role   = aws_iam_role.checksum_restore_function_role.id
+  policy = aws_iam_role_policy.checksum_verification_repair_policy[0].policy
+}
+
+# File Deleted Function IAM
+resource "aws_iam_role" "file_deleted_function_role" {
+  name = "${local.stack_name}-file-deleted-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-file-deleted-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "file_deleted_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.file_deleted_function_role.name
+}
+
+resource "aws_iam_role_policy_attachment" "file_deleted_function_sqs" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole"
+  role       = aws_iam_role.file_deleted_function_role.name
+}
+
+resource "aws_iam_role_policy" "file_deleted_function_policy" {
+  name = "${local.stack_name}-file-deleted-function-policy"
+  role = aws_iam_role.file_deleted_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_history_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:DeleteItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn,
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      }
+    ]
+  })
+}
+
+# File Uploaded Function IAM
+resource "aws_iam_role" "file_uploaded_function_role" {
+  name = "${local.stack_name}-file-uploaded-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-file-uploaded-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "file_uploaded_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.file_uploaded_function_role.name
+}
+
+resource "aws_iam_role_policy_attachment" "file_uploaded_function_sqs" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole"
+  role       = aws_iam_role.file_uploaded_function_role.name
+}
+
+resource "aws_iam_role_policy" "file_uploaded_function_policy" {
+  name = "${local.stack_name}-file-uploaded-function-policy"
+  role = aws_iam_role.file_uploaded_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetBucketTagging"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_history_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn,
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:UpdateItem"
+        ]
+        Resource = aws_dynamodb_table.legacy_checksum_table.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:DeleteObject",
+          "s3:PutObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/checkpoints/*"
+      }
+    ]
+  })
+}
+
+# Manifest Reconciler Function IAM
+resource "aws_iam_role" "manifest_reconciler_function_role" {
+  name = "${local.stack_name}-manifest-reconciler-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-manifest-reconciler-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "manifest_reconciler_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.manifest_reconciler_function_role.name
+}
+
+resource "aws_iam_role_policy_attachment" "manifest_reconciler_function_sqs" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole"
+  role       = aws_iam_role.manifest_reconciler_function_role.name
+}
+
+resource "aws_iam_role_policy" "manifest_reconciler_function_policy" {
+  name = "${local.stack_name}-manifest-reconciler-function-policy"
+  role = aws_iam_role.manifest_reconciler_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucketVersions"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:PutObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/reports/manifests/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sns:Publish"
+        ]
+        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
+      }
+    ]
+  })
+}
+
+# Inventory Reconciler Function IAM
+resource "aws_iam_role" "inventory_reconciler_function_role" {
+  name = "${local.stack_name}-inventory-reconciler-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-inventory-reconciler-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "inventory_reconciler_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.inventory_reconciler_function_role.name
+}
+
+resource "aws_iam_role_policy" "inventory_reconciler_function_policy" {
+  name = "${local.stack_name}-inventory-reconciler-function-policy"
+  role = aws_iam_role.inventory_reconciler_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket"
+        ]
+        Resource = [
+          "arn:aws:s3:::${local.stack_name}-*",
+          aws_s3_bucket.managed_bucket.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion"
+        ]
+        Resource = "arn:aws:s3:::${local.stack_name}-*/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:PutObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/reports/inventory/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:GetItem",
+          "dynamodb:Query"
+        ]
+        Resource = aws_dynamodb_table.checksum_version_table.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sqs:SendMessage"
+        ]
+        Resource = aws_sqs_queue.object_created.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "sns:Publish"
+        ]
+        Resource = local.enable_email_alerts ? aws_sns_topic.email_alert_topic.arn : "*"
+      }
+    ]
+  })
+}
+
+# Inventory Unwrap Function IAM
+resource "aws_iam_role" "inventory_unwrap_function_role" {
+  name = "${local.stack_name}-inventory-unwrap-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-inventory-unwrap-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "inventory_unwrap_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.inventory_unwrap_function_role.name
+}
+
+resource "aws_iam_role_policy" "inventory_unwrap_function_policy" {
+  name = "${local.stack_name}-inventory-unwrap-function-policy"
+  role = aws_iam_role.inventory_unwrap_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket",
+          "s3:ListBucketMultipartUploads",
+          "s3:ListMultipartUploadParts",
+          "s3:AbortMultipartUpload",
+          "s3:GetObject",
+          "s3:PutObject",
+          "s3:PutObjectAcl"
+        ]
+        Resource = [
+          "${aws_s3_bucket.managed_bucket.arn}/inventory/*"
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket"
+        ]
+        Resource = aws_s3_bucket.managed_bucket.arn
+      }
+    ]
+  })
+}
+
+# Legacy Import Function IAM
+resource "aws_iam_role" "legacy_import_function_role" {
+  name = "${local.stack_name}-legacy-import-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-legacy-import-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "legacy_import_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.legacy_import_function_role.name
+}
+
+resource "aws_iam_role_policy" "legacy_import_function_policy" {
+  name = "${local.stack_name}-legacy-import-function-policy"
+  role = aws_iam_role.legacy_import_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/imports/legacy/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:ListBucket"
+        ]
+        Resource = aws_s3_bucket.managed_bucket.arn
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:UpdateItem"
+        ]
+        Resource = aws_dynamodb_table.legacy_checksum_table.arn
+      }
+    ]
+  })
+}
+
+# List Failures Function IAM
+resource "aws_iam_role" "list_failures_function_role" {
+  name = "${local.stack_name}-list-failures-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-list-failures-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "list_failures_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.list_failures_function_role.name
+}
+
+resource "aws_iam_role_policy" "list_failures_function_policy" {
+  name = "${local.stack_name}-list-failures-function-policy"
+  role = aws_iam_role.list_failures_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:Query",
+          "dynamodb:UpdateItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn,
+          "${aws_dynamodb_table.checksum_version_table.arn}/index/*"
+        ]
+      }
+    ]
+  })
+}
+
+# Report Generator Function IAM
+resource "aws_iam_role" "report_generator_function_role" {
+  name = "${local.stack_name}-report-generator-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-report-generator-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "report_generator_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.report_generator_function_role.name
+}
+
+resource "aws_iam_role_policy" "report_generator_function_policy" {
+  name = "${local.stack_name}-report-generator-function-policy"
+  role = aws_iam_role.report_generator_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetBucketTagging",
           "s3:GetObject",
           "s3:ListAllMyBuckets",
           "s3:ListBucket"
@@ -715,6 +1678,16 @@ resource "aws_iam_role_policy" "report_generator_function_policy" {
           "${aws_s3_bucket.managed_bucket.arn}/reports/*"
         ]
       },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:Query"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_budget_table.arn,
+          "${aws_dynamodb_table.checksum_version_table.arn}/index/*"
+        ]
+      },
       {
         Effect = "Allow"
         Action = [
@@ -726,6 +1699,77 @@ resource "aws_iam_role_policy" "report_generator_function_policy" {
   })
 }
 
+# Verify Requested Function IAM
+resource "aws_iam_role" "verify_requested_function_role" {
+  name = "${local.stack_name}-verify-requested-function-role"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Action = "sts:AssumeRole"
+        Effect = "Allow"
+        Principal = {
+          Service = "lambda.amazonaws.com"
+        }
+      }
+    ]
+  })
+
+  tags = {
+    Name = "${local.stack_name}-verify-requested-function-role"
+  }
+}
+
+resource "aws_iam_role_policy_attachment" "verify_requested_function_basic" {
+  policy_arn = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+  role       = aws_iam_role.verify_requested_function_role.name
+}
+
+resource "aws_iam_role_policy" "verify_requested_function_policy" {
+  name = "${local.stack_name}-verify-requested-function-policy"
+  role = aws_iam_role.verify_requested_function_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject"
+        ]
+        Resource = "${aws_s3_bucket.bucket_requested.arn}/*"
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:Query"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "dynamodb:DeleteItem",
+          "dynamodb:PutItem"
+        ]
+        Resource = [
+          aws_dynamodb_table.checksum_version_scheduler_table.arn
+        ]
+      },
+      {
+        Effect = "Allow"
+        Action = [
+          "s3:PutObject"
+        ]
+        Resource = "${aws_s3_bucket.managed_bucket.arn}/logs/*"
+      }
+    ]
+  })
+}
+
 # IAM Groups and Policies
 resource "aws_iam_group" "s3_power_users_group" {
   name = "${local.stack_name}-S3PowerUsers"
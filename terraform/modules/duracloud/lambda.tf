@@ -8,6 +8,15 @@ resource "aws_cloudwatch_log_group" "bucket_requested_function" {
   }
 }
 
+resource "aws_cloudwatch_log_group" "bucket_seeder_function" {
+  name              = "/aws/lambda/${local.stack_name}-bucket-seeder"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-bucket-seeder-logs"
+  }
+}
+
 resource "aws_cloudwatch_log_group" "checksum_exporter_function" {
   name              = "/aws/lambda/${local.stack_name}-checksum-exporter"
   retention_in_days = 30
@@ -35,6 +44,52 @@ resource "aws_cloudwatch_log_group" "checksum_failure_function" {
   }
 }
 
+resource "aws_cloudwatch_log_group" "checksum_migrator_function" {
+  count             = local.retire_object_key_tables ? 0 : 1
+  name              = "/aws/lambda/${local.stack_name}-checksum-migrator"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-checksum-migrator-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "checksum_rebalancer_function" {
+  name              = "/aws/lambda/${local.stack_name}-checksum-rebalancer"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-checksum-rebalancer-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "checksum_restore_function" {
+  name              = "/aws/lambda/${local.stack_name}-checksum-restore"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${local.stack_name}-checksum-restore-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "checksum_scheduler_function" {
+  name              = "/aws/lambda/${local.stack_name}-checksum-scheduler"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-checksum-scheduler-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "checksum_sweeper_function" {
+  name              = "/aws/lambda/${local.stack_name}-checksum-sweeper"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-checksum-sweeper-logs"
+  }
+}
+
 resource "aws_cloudwatch_log_group" "checksum_verification_function" {
   name              = "/aws/lambda/${local.stack_name}-checksum-verification"
   retention_in_days = 7
@@ -62,6 +117,15 @@ resource "aws_cloudwatch_log_group" "file_uploaded_function" {
   }
 }
 
+resource "aws_cloudwatch_log_group" "inventory_reconciler_function" {
+  name              = "/aws/lambda/${local.stack_name}-inventory-reconciler"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-inventory-reconciler-logs"
+  }
+}
+
 resource "aws_cloudwatch_log_group" "inventory_unwrap_function" {
   name              = "/aws/lambda/${local.stack_name}-inventory-unwrap"
   retention_in_days = 30
@@ -71,6 +135,33 @@ resource "aws_cloudwatch_log_group" "inventory_unwrap_function" {
   }
 }
 
+resource "aws_cloudwatch_log_group" "legacy_import_function" {
+  name              = "/aws/lambda/${local.stack_name}-legacy-import"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-legacy-import-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "list_failures_function" {
+  name              = "/aws/lambda/${local.stack_name}-list-failures"
+  retention_in_days = 30
+
+  tags = {
+    Name = "${local.stack_name}-list-failures-logs"
+  }
+}
+
+resource "aws_cloudwatch_log_group" "manifest_reconciler_function" {
+  name              = "/aws/lambda/${local.stack_name}-manifest-reconciler"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${local.stack_name}-manifest-reconciler-logs"
+  }
+}
+
 resource "aws_cloudwatch_log_group" "report_generator_function" {
   name              = "/aws/lambda/${local.stack_name}-report-generator"
   retention_in_days = 7
@@ -80,6 +171,15 @@ resource "aws_cloudwatch_log_group" "report_generator_function" {
   }
 }
 
+resource "aws_cloudwatch_log_group" "verify_requested_function" {
+  name              = "/aws/lambda/${local.stack_name}-verify-requested"
+  retention_in_days = 7
+
+  tags = {
+    Name = "${local.stack_name}-verify-requested-logs"
+  }
+}
+
 # Lambda Functions
 resource "aws_lambda_function" "bucket_requested_function" {
   function_name = "${local.stack_name}-bucket-requested"
@@ -117,6 +217,42 @@ resource "aws_lambda_function" "bucket_requested_function" {
   }
 }
 
+resource "aws_lambda_function" "bucket_seeder_function" {
+  function_name = "${local.stack_name}-bucket-seeder"
+  role          = aws_iam_role.bucket_seeder_function_role.arn
+  image_uri     = local.bucket_seeder_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 1024
+  description   = "DuraCloud function that enqueues deposits of the objects of a bucket without checksum records"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.bucket_seeder_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE = aws_dynamodb_table.checksum_version_table.name
+      S3_BUCKET_PREFIX        = local.stack_name
+      S3_MANAGED_BUCKET       = aws_s3_bucket.managed_bucket.bucket
+      SEED_RATE               = tostring(local.bucket_seeder_rate)
+      SQS_OBJECT_CREATED_URL  = aws_sqs_queue.object_created.url
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.bucket_seeder_function_basic,
+    aws_iam_role_policy.bucket_seeder_function_policy,
+    aws_cloudwatch_log_group.bucket_seeder_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-bucket-seeder-function"
+  }
+}
+
 resource "aws_lambda_function" "checksum_exporter_function" {
   function_name = "${local.stack_name}-checksum-exporter"
   role          = aws_iam_role.checksum_exporter_function_role.arn
@@ -134,7 +270,7 @@ resource "aws_lambda_function" "checksum_exporter_function" {
 
   environment {
     variables = {
-      DYNAMODB_CHECKSUM_TABLE = aws_dynamodb_table.checksum_table.name
+      DYNAMODB_CHECKSUM_TABLE = aws_dynamodb_table.checksum_version_table.name
       S3_MANAGED_BUCKET       = aws_s3_bucket.managed_bucket.bucket
     }
   }
@@ -158,7 +294,7 @@ resource "aws_lambda_function" "checksum_export_csv_report_function" {
   architectures = [local.lambda_architecture]
   timeout       = 900
   memory_size   = 256
-  description   = "DuraCloud function that writes CSV Reports of DynamoDB table exports"
+  description   = "DuraCloud function that writes CSV and PREMIS Reports of DynamoDB table exports"
 
   ephemeral_storage {
     size = local.checksum_export_csv_report_storage
@@ -169,6 +305,13 @@ resource "aws_lambda_function" "checksum_export_csv_report_function" {
     log_group  = aws_cloudwatch_log_group.checksum_export_csv_report_function.name
   }
 
+  environment {
+    variables = {
+      DYNAMODB_HISTORY_TABLE = aws_dynamodb_table.checksum_history_table.name
+      EXPORT_FORMATS         = "csv,premis"
+    }
+  }
+
   depends_on = [
     aws_iam_role_policy_attachment.checksum_export_csv_report_function_basic,
     aws_iam_role_policy.checksum_export_csv_report_function_policy,
@@ -214,6 +357,189 @@ resource "aws_lambda_function" "checksum_failure_function" {
   }
 }
 
+resource "aws_lambda_function" "checksum_migrator_function" {
+  count         = local.retire_object_key_tables ? 0 : 1
+  function_name = "${local.stack_name}-checksum-migrator"
+  role          = aws_iam_role.checksum_migrator_function_role[0].arn
+  image_uri     = local.checksum_migrator_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 256
+  description   = "DuraCloud function that copies checksum records keyed by ObjectKey to the version tables"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.checksum_migrator_function[0].name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE             = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_OBJECT_KEY_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_table[0].name
+      DYNAMODB_OBJECT_KEY_SCHEDULER_TABLE = aws_dynamodb_table.checksum_scheduler_table[0].name
+      DYNAMODB_SCHEDULER_TABLE            = aws_dynamodb_table.checksum_version_scheduler_table.name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.checksum_migrator_function_basic,
+    aws_iam_role_policy.checksum_migrator_function_policy,
+    aws_cloudwatch_log_group.checksum_migrator_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-checksum-migrator-function"
+  }
+}
+
+resource "aws_lambda_function" "checksum_rebalancer_function" {
+  function_name = "${local.stack_name}-checksum-rebalancer"
+  role          = aws_iam_role.checksum_rebalancer_function_role.arn
+  image_uri     = local.checksum_rebalancer_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 256
+  description   = "DuraCloud function that flattens the daily load of scheduled checksum verifications"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.checksum_rebalancer_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
+      S3_BUCKET_PREFIX         = local.stack_name
+      S3_MANAGED_BUCKET        = aws_s3_bucket.managed_bucket.bucket
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.checksum_rebalancer_function_basic,
+    aws_iam_role_policy.checksum_rebalancer_function_policy,
+    aws_cloudwatch_log_group.checksum_rebalancer_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-checksum-rebalancer-function"
+  }
+}
+
+resource "aws_lambda_function" "checksum_restore_function" {
+  function_name = "${local.stack_name}-checksum-restore"
+  role          = aws_iam_role.checksum_restore_function_role.arn
+  image_uri     = local.checksum_restore_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 1024
+  description   = "DuraCloud function that finishes replica checks when an archived replica is restored"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.checksum_restore_function.name
+  }
+
+  environment {
+    variables = {
+      CHECKSUM_REPAIR_MODE     = local.checksum_repair_mode
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_HISTORY_TABLE   = aws_dynamodb_table.checksum_history_table.name
+      DYNAMODB_RESTORE_TABLE   = aws_dynamodb_table.checksum_restore_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
+      SNS_TOPIC_ARN            = aws_sns_topic.email_alert_topic.arn
+      STACK_NAME               = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.checksum_restore_function_basic,
+    aws_iam_role_policy.checksum_restore_function_policy,
+    aws_cloudwatch_log_group.checksum_restore_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-checksum-restore-function"
+  }
+}
+
+resource "aws_lambda_function" "checksum_scheduler_function" {
+  function_name = "${local.stack_name}-checksum-scheduler"
+  role          = aws_iam_role.checksum_scheduler_function_role.arn
+  image_uri     = local.checksum_scheduler_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 128
+  description   = "DuraCloud function that reschedules checksum verifications when a bucket fixity policy changes"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.checksum_scheduler_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
+      S3_BUCKET_PREFIX         = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.checksum_scheduler_function_basic,
+    aws_iam_role_policy.checksum_scheduler_function_policy,
+    aws_cloudwatch_log_group.checksum_scheduler_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-checksum-scheduler-function"
+  }
+}
+
+resource "aws_lambda_function" "checksum_sweeper_function" {
+  function_name = "${local.stack_name}-checksum-sweeper"
+  role          = aws_iam_role.checksum_sweeper_function_role.arn
+  image_uri     = local.checksum_sweeper_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 128
+  description   = "DuraCloud function that schedules checksum records left without a scheduled verification"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.checksum_sweeper_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
+      ORPHAN_ALERT_THRESHOLD   = tostring(local.checksum_sweeper_alert_threshold)
+      RECHECK_FAILED           = local.checksum_sweeper_recheck_failed
+      RECHECK_FAILED_AFTER     = local.checksum_sweeper_recheck_failed_after
+      S3_BUCKET_PREFIX         = local.stack_name
+      S3_MANAGED_BUCKET        = aws_s3_bucket.managed_bucket.bucket
+      SNS_TOPIC_ARN            = aws_sns_topic.email_alert_topic.arn
+      STACK_NAME               = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.checksum_sweeper_function_basic,
+    aws_iam_role_policy.checksum_sweeper_function_policy,
+    aws_cloudwatch_log_group.checksum_sweeper_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-checksum-sweeper-function"
+  }
+}
+
 resource "aws_lambda_function" "checksum_verification_function" {
   function_name = "${local.stack_name}-checksum-verification"
   role          = aws_iam_role.checksum_verification_function_role.arn
@@ -231,10 +557,20 @@ resource "aws_lambda_function" "checksum_verification_function" {
 
   environment {
     variables = {
-      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_table.name
-      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_scheduler_table.name
-      SNS_TOPIC_ARN            = aws_sns_topic.email_alert_topic.arn
-      STACK_NAME               = local.stack_name
+      CHECKSUM_REPAIR_MODE       = local.checksum_repair_mode
+      DYNAMODB_BUDGET_TABLE      = aws_dynamodb_table.checksum_budget_table.name
+      DYNAMODB_CHECKSUM_TABLE    = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_HISTORY_TABLE     = aws_dynamodb_table.checksum_history_table.name
+      DYNAMODB_RESTORE_TABLE     = aws_dynamodb_table.checksum_restore_table.name
+      DYNAMODB_SCHEDULER_TABLE   = aws_dynamodb_table.checksum_version_scheduler_table.name
+      REPLICA_CHECK_INTERVAL     = local.replica_check_interval
+      REPLICA_CHECKS             = tostring(local.replica_checks)
+      REPLICA_RESTORES           = tostring(local.replica_restores)
+      S3_MANAGED_BUCKET          = aws_s3_bucket.managed_bucket.bucket
+      SNS_TOPIC_ARN              = aws_sns_topic.email_alert_topic.arn
+      STACK_NAME                 = local.stack_name
+      VERIFICATION_BUDGET_PERIOD = local.verification_budget_period
+      VERIFICATION_BYTE_BUDGET   = local.verification_byte_budget
     }
   }
 
@@ -266,8 +602,9 @@ resource "aws_lambda_function" "file_deleted_function" {
 
   environment {
     variables = {
-      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_table.name
-      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_scheduler_table.name
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_HISTORY_TABLE   = aws_dynamodb_table.checksum_history_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
       S3_BUCKET_PREFIX         = local.stack_name
     }
   }
@@ -300,9 +637,12 @@ resource "aws_lambda_function" "file_uploaded_function" {
 
   environment {
     variables = {
-      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_table.name
-      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_scheduler_table.name
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_HISTORY_TABLE   = aws_dynamodb_table.checksum_history_table.name
+      DYNAMODB_LEGACY_TABLE    = aws_dynamodb_table.legacy_checksum_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
       S3_BUCKET_PREFIX         = local.stack_name
+      S3_MANAGED_BUCKET        = aws_s3_bucket.managed_bucket.bucket
     }
   }
 
@@ -317,6 +657,44 @@ resource "aws_lambda_function" "file_uploaded_function" {
   }
 }
 
+resource "aws_lambda_function" "inventory_reconciler_function" {
+  function_name = "${local.stack_name}-inventory-reconciler"
+  role          = aws_iam_role.inventory_reconciler_function_role.arn
+  image_uri     = local.inventory_reconciler_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 1024
+  description   = "DuraCloud function that reconciles bucket inventories with the checksum table"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.inventory_reconciler_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE = aws_dynamodb_table.checksum_version_table.name
+      ENQUEUE_DEPOSITS        = tostring(local.inventory_reconciler_enqueue_deposits)
+      S3_BUCKET_PREFIX        = local.stack_name
+      S3_MANAGED_BUCKET       = aws_s3_bucket.managed_bucket.bucket
+      SNS_TOPIC_ARN           = aws_sns_topic.email_alert_topic.arn
+      SQS_OBJECT_CREATED_URL  = aws_sqs_queue.object_created.url
+      STACK_NAME              = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.inventory_reconciler_function_basic,
+    aws_iam_role_policy.inventory_reconciler_function_policy,
+    aws_cloudwatch_log_group.inventory_reconciler_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-inventory-reconciler-function"
+  }
+}
+
 resource "aws_lambda_function" "inventory_unwrap_function" {
   function_name = "${local.stack_name}-inventory-unwrap"
   role          = aws_iam_role.inventory_unwrap_function_role.arn
@@ -347,6 +725,110 @@ resource "aws_lambda_function" "inventory_unwrap_function" {
   }
 }
 
+resource "aws_lambda_function" "legacy_import_function" {
+  function_name = "${local.stack_name}-legacy-import"
+  role          = aws_iam_role.legacy_import_function_role.arn
+  image_uri     = local.legacy_import_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 256
+  description   = "DuraCloud function that stages checksums imported from a legacy repository"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.legacy_import_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_LEGACY_TABLE = aws_dynamodb_table.legacy_checksum_table.name
+      S3_BUCKET_PREFIX      = local.stack_name
+      S3_MANAGED_BUCKET     = aws_s3_bucket.managed_bucket.bucket
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.legacy_import_function_basic,
+    aws_iam_role_policy.legacy_import_function_policy,
+    aws_cloudwatch_log_group.legacy_import_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-legacy-import-function"
+  }
+}
+
+resource "aws_lambda_function" "list_failures_function" {
+  function_name = "${local.stack_name}-list-failures"
+  role          = aws_iam_role.list_failures_function_role.arn
+  image_uri     = local.list_failures_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 128
+  description   = "DuraCloud function that lists (and indexes) the objects whose last checksum check failed"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.list_failures_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE = aws_dynamodb_table.checksum_version_table.name
+      S3_BUCKET_PREFIX        = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.list_failures_function_basic,
+    aws_iam_role_policy.list_failures_function_policy,
+    aws_cloudwatch_log_group.list_failures_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-list-failures-function"
+  }
+}
+
+resource "aws_lambda_function" "manifest_reconciler_function" {
+  function_name = "${local.stack_name}-manifest-reconciler"
+  role          = aws_iam_role.manifest_reconciler_function_role.arn
+  image_uri     = local.manifest_reconciler_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 300
+  memory_size   = 256
+  description   = "DuraCloud function that reconciles uploaded manifests with deposits"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.manifest_reconciler_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE  = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_SCHEDULER_TABLE = aws_dynamodb_table.checksum_version_scheduler_table.name
+      S3_BUCKET_PREFIX         = local.stack_name
+      S3_MANAGED_BUCKET        = aws_s3_bucket.managed_bucket.bucket
+      SNS_TOPIC_ARN            = aws_sns_topic.email_alert_topic.arn
+      STACK_NAME               = local.stack_name
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.manifest_reconciler_function_basic,
+    aws_iam_role_policy.manifest_reconciler_function_policy,
+    aws_cloudwatch_log_group.manifest_reconciler_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-manifest-reconciler-function"
+  }
+}
+
 resource "aws_lambda_function" "report_generator_function" {
   function_name = "${local.stack_name}-report-generator"
   role          = aws_iam_role.report_generator_function_role.arn
@@ -364,8 +846,12 @@ resource "aws_lambda_function" "report_generator_function" {
 
   environment {
     variables = {
-      S3_MANAGED_BUCKET = aws_s3_bucket.managed_bucket.bucket
-      STACK_NAME        = local.stack_name
+      DYNAMODB_BUDGET_TABLE      = aws_dynamodb_table.checksum_budget_table.name
+      DYNAMODB_CHECKSUM_TABLE    = aws_dynamodb_table.checksum_version_table.name
+      S3_MANAGED_BUCKET          = aws_s3_bucket.managed_bucket.bucket
+      STACK_NAME                 = local.stack_name
+      VERIFICATION_BUDGET_PERIOD = local.verification_budget_period
+      VERIFICATION_BYTE_BUDGET   = local.verification_byte_budget
     }
   }
 
@@ -381,8 +867,44 @@ resource "aws_lambda_function" "report_generator_function" {
 }
 
 # Lambda Event Source Mappings
+resource "aws_lambda_function" "verify_requested_function" {
+  function_name = "${local.stack_name}-verify-requested"
+  role          = aws_iam_role.verify_requested_function_role.arn
+  image_uri     = local.verify_requested_image_uri
+  package_type  = "Image"
+  architectures = [local.lambda_architecture]
+  timeout       = 900
+  memory_size   = 256
+  description   = "DuraCloud function that requests immediate verification of objects"
+
+  logging_config {
+    log_format = "JSON"
+    log_group  = aws_cloudwatch_log_group.verify_requested_function.name
+  }
+
+  environment {
+    variables = {
+      DYNAMODB_CHECKSUM_TABLE           = aws_dynamodb_table.checksum_version_table.name
+      DYNAMODB_SCHEDULER_TABLE          = aws_dynamodb_table.checksum_version_scheduler_table.name
+      S3_BUCKET_PREFIX                  = local.stack_name
+      S3_MANAGED_BUCKET                 = aws_s3_bucket.managed_bucket.bucket
+      S3_MAX_VERIFY_TARGETS_PER_REQUEST = "100"
+    }
+  }
+
+  depends_on = [
+    aws_iam_role_policy_attachment.verify_requested_function_basic,
+    aws_iam_role_policy.verify_requested_function_policy,
+    aws_cloudwatch_log_group.verify_requested_function,
+  ]
+
+  tags = {
+    Name = "${local.stack_name}-verify-requested-function"
+  }
+}
+
 resource "aws_lambda_event_source_mapping" "dynamodb_checksum_failure_source" {
-  event_source_arn                   = aws_dynamodb_table.checksum_table.stream_arn
+  event_source_arn                   = aws_dynamodb_table.checksum_version_table.stream_arn
   function_name                      = aws_lambda_function.checksum_failure_function.arn
   starting_position                  = "TRIM_HORIZON"
   batch_size                         = 10
@@ -405,12 +927,12 @@ resource "aws_lambda_event_source_mapping" "dynamodb_checksum_failure_source" {
 
   depends_on = [
     aws_lambda_function.checksum_failure_function,
-    aws_dynamodb_table.checksum_table
+    aws_dynamodb_table.checksum_version_table
   ]
 }
 
 resource "aws_lambda_event_source_mapping" "dynamodb_checksum_scheduler_source" {
-  event_source_arn                   = aws_dynamodb_table.checksum_scheduler_table.stream_arn
+  event_source_arn                   = aws_dynamodb_table.checksum_version_scheduler_table.stream_arn
   function_name                      = aws_lambda_function.checksum_verification_function.arn
   starting_position                  = "TRIM_HORIZON"
   batch_size                         = 10
@@ -426,7 +948,19 @@ resource "aws_lambda_event_source_mapping" "dynamodb_checksum_scheduler_source"
 
   depends_on = [
     aws_lambda_function.checksum_verification_function,
-    aws_dynamodb_table.checksum_scheduler_table
+    aws_dynamodb_table.checksum_version_scheduler_table
+  ]
+}
+
+resource "aws_lambda_event_source_mapping" "sqs_manifest_uploaded_source" {
+  event_source_arn        = aws_sqs_queue.manifest_uploaded.arn
+  function_name           = aws_lambda_function.manifest_reconciler_function.arn
+  batch_size              = 1
+  function_response_types = ["ReportBatchItemFailures"]
+
+  depends_on = [
+    aws_lambda_function.manifest_reconciler_function,
+    aws_sqs_queue.manifest_uploaded
   ]
 }
 
@@ -488,6 +1022,36 @@ resource "aws_lambda_permission" "checksum_export_csv_report_invoke_permission"
   depends_on = [aws_lambda_function.checksum_export_csv_report_function]
 }
 
+resource "aws_lambda_permission" "checksum_restore_invoke_permission" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.checksum_restore_function.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.object_restore_completed_rule.arn
+
+  depends_on = [aws_lambda_function.checksum_restore_function]
+}
+
+resource "aws_lambda_permission" "checksum_sweeper_invoke_permission" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.checksum_sweeper_function.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.checksum_sweeper_schedule.arn
+
+  depends_on = [aws_lambda_function.checksum_sweeper_function]
+}
+
+resource "aws_lambda_permission" "inventory_reconciler_invoke_permission" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.inventory_reconciler_function.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.inventory_reconciler_schedule.arn
+
+  depends_on = [aws_lambda_function.inventory_reconciler_function]
+}
+
 resource "aws_lambda_permission" "inventory_unwrap_invoke_permission" {
   statement_id   = "InventoryUnwrapAllowExecutionFromS3"
   action         = "lambda:InvokeFunction"
@@ -508,3 +1072,13 @@ resource "aws_lambda_permission" "report_generator_invoke_permission" {
 
   depends_on = [aws_lambda_function.report_generator_function]
 }
+
+resource "aws_lambda_permission" "verify_requested_invoke_permission" {
+  statement_id  = "AllowExecutionFromEventBridge"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.verify_requested_function.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.verify_requested_rule.arn
+
+  depends_on = [aws_lambda_function.verify_requested_function]
+}
@@ -1,5 +1,5 @@
 terraform {
-  required_version = ">= 1.0"
+  required_version = ">= 1.1"
   required_providers {
     aws = {
       source  = "hashicorp/aws"
@@ -12,22 +12,47 @@ data "aws_caller_identity" "current" {}
 data "aws_region" "current" {}
 
 locals {
-  stack_name                         = var.stack_name
-  checksum_exporter_schedule         = coalesce(var.checksum_exporter_schedule, null)
-  checksum_export_csv_report_storage = var.checksum_export_csv_report_storage
-  enable_email_alerts                = var.alert_email_address != ""
-  inventory_unwrap_storage           = var.inventory_unwrap_storage
-  lambda_architecture                = var.lambda_architecture
-  report_generator_schedule          = coalesce(var.report_generator_schedule, null)
+  stack_name                            = var.stack_name
+  bucket_seeder_rate                    = var.bucket_seeder_rate
+  checksum_exporter_schedule            = coalesce(var.checksum_exporter_schedule, null)
+  checksum_export_csv_report_storage    = var.checksum_export_csv_report_storage
+  checksum_repair_mode                  = var.checksum_repair_mode
+  checksum_sweeper_alert_threshold      = var.checksum_sweeper_alert_threshold
+  checksum_sweeper_recheck_failed       = var.checksum_sweeper_recheck_failed
+  checksum_sweeper_recheck_failed_after = var.checksum_sweeper_recheck_failed_after
+  checksum_sweeper_schedule             = coalesce(var.checksum_sweeper_schedule, null)
+  enable_email_alerts                   = var.alert_email_address != ""
+  inventory_reconciler_enqueue_deposits = var.inventory_reconciler_enqueue_deposits
+  inventory_reconciler_schedule         = coalesce(var.inventory_reconciler_schedule, null)
+  inventory_unwrap_storage              = var.inventory_unwrap_storage
+  lambda_architecture                   = var.lambda_architecture
+  replica_check_interval                = var.replica_check_interval
+  replica_checks                        = var.replica_checks
+  replica_restores                      = var.replica_checks && var.replica_restores
+  report_generator_schedule             = coalesce(var.report_generator_schedule, null)
+  retire_object_key_tables              = var.retire_object_key_tables
+  verification_budget_period            = var.verification_budget_period
+  verification_byte_budget              = var.verification_byte_budget
 
   # Conditional logic for external images
   bucket_requested_image_uri           = coalesce(var.bucket_requested_image_uri, null)
+  bucket_seeder_image_uri              = coalesce(var.bucket_seeder_image_uri, null)
   checksum_exporter_image_uri          = coalesce(var.checksum_exporter_image_uri, null)
   checksum_export_csv_report_image_uri = coalesce(var.checksum_export_csv_report_image_uri, null)
   checksum_failure_image_uri           = coalesce(var.checksum_failure_image_uri, null)
+  checksum_migrator_image_uri          = coalesce(var.checksum_migrator_image_uri, null)
+  checksum_rebalancer_image_uri        = coalesce(var.checksum_rebalancer_image_uri, null)
+  checksum_restore_image_uri           = coalesce(var.checksum_restore_image_uri, null)
+  checksum_scheduler_image_uri         = coalesce(var.checksum_scheduler_image_uri, null)
+  checksum_sweeper_image_uri           = coalesce(var.checksum_sweeper_image_uri, null)
   checksum_verification_image_uri      = coalesce(var.checksum_verification_image_uri, null)
   file_deleted_image_uri               = coalesce(var.file_deleted_image_uri, null)
   file_uploaded_image_uri              = coalesce(var.file_uploaded_image_uri, null)
+  inventory_reconciler_image_uri       = coalesce(var.inventory_reconciler_image_uri, null)
   inventory_unwrap_image_uri           = coalesce(var.inventory_unwrap_image_uri, null)
+  legacy_import_image_uri              = coalesce(var.legacy_import_image_uri, null)
+  list_failures_image_uri              = coalesce(var.list_failures_image_uri, null)
+  manifest_reconciler_image_uri        = coalesce(var.manifest_reconciler_image_uri, null)
   report_generator_image_uri           = coalesce(var.report_generator_image_uri, null)
+  verify_requested_image_uri           = coalesce(var.verify_requested_image_uri, null)
 }
@@ -15,12 +15,32 @@ output "bucket_requested_name" {
 
 output "checksum_table_name" {
   description = "Name of the DynamoDB checksum table"
-  value       = aws_dynamodb_table.checksum_table.name
+  value       = aws_dynamodb_table.checksum_version_table.name
 }
 
 output "checksum_scheduler_table_name" {
   description = "Name of the DynamoDB checksum scheduler table"
-  value       = aws_dynamodb_table.checksum_scheduler_table.name
+  value       = aws_dynamodb_table.checksum_version_scheduler_table.name
+}
+
+output "checksum_restore_table_name" {
+  description = "Name of the DynamoDB checksum restore table"
+  value       = aws_dynamodb_table.checksum_restore_table.name
+}
+
+output "checksum_budget_table_name" {
+  description = "Name of the DynamoDB checksum budget table"
+  value       = aws_dynamodb_table.checksum_budget_table.name
+}
+
+output "checksum_history_table_name" {
+  description = "Name of the DynamoDB checksum history table"
+  value       = aws_dynamodb_table.checksum_history_table.name
+}
+
+output "legacy_checksum_table_name" {
+  description = "Name of the DynamoDB legacy checksum table"
+  value       = aws_dynamodb_table.legacy_checksum_table.name
 }
 
 output "sns_topic_arn" {
@@ -32,12 +52,23 @@ output "lambda_functions" {
   description = "Map of Lambda function names and ARNs"
   value = {
     bucket_requested_function           = aws_lambda_function.bucket_requested_function.arn
+    bucket_seeder_function              = aws_lambda_function.bucket_seeder_function.arn
     checksum_exporter_function          = aws_lambda_function.checksum_exporter_function.arn
     checksum_export_csv_report_function = aws_lambda_function.checksum_export_csv_report_function.arn
     checksum_failure_function           = aws_lambda_function.checksum_failure_function.arn
+    checksum_migrator_function          = one(aws_lambda_function.checksum_migrator_function[*].arn)
+    checksum_rebalancer_function        = aws_lambda_function.checksum_rebalancer_function.arn
+    checksum_restore_function           = aws_lambda_function.checksum_restore_function.arn
+    checksum_scheduler_function         = aws_lambda_function.checksum_scheduler_function.arn
+    checksum_sweeper_function           = aws_lambda_function.checksum_sweeper_function.arn
     checksum_verification_function      = aws_lambda_function.checksum_verification_function.arn
     file_deleted_function               = aws_lambda_function.file_deleted_function.arn
     file_uploaded_function              = aws_lambda_function.file_uploaded_function.arn
+    inventory_reconciler_function       = aws_lambda_function.inventory_reconciler_function.arn
+    legacy_import_function              = aws_lambda_function.legacy_import_function.arn
+    list_failures_function              = aws_lambda_function.list_failures_function.arn
+    manifest_reconciler_function        = aws_lambda_function.manifest_reconciler_function.arn
     report_generator_function           = aws_lambda_function.report_generator_function.arn
+    verify_requested_function           = aws_lambda_function.verify_requested_function.arn
   }
 }
\ No newline at end of file
@@ -54,3 +54,33 @@ resource "aws_sqs_queue" "object_deleted" {
     Name = "${local.stack_name}-object-deleted"
   }
 }
+
+resource "aws_sqs_queue" "manifest_uploaded_dlq" {
+  name                      = "${local.stack_name}-manifest-uploaded-dlq"
+  message_retention_seconds = 604800 # 7 days
+
+  redrive_allow_policy = jsonencode({
+    redrivePermission = "allowAll"
+  })
+
+  tags = {
+    Name = "${local.stack_name}-manifest-uploaded-dlq"
+  }
+}
+
+# Manifests are delayed and retried while the objects they list are deposited
+resource "aws_sqs_queue" "manifest_uploaded" {
+  name                       = "${local.stack_name}-manifest-uploaded"
+  delay_seconds              = 300 # 5 minutes for the first deposits to land
+  visibility_timeout_seconds = 900 # 15 minutes between reconciliation attempts
+  receive_wait_time_seconds  = 20
+
+  redrive_policy = jsonencode({
+    deadLetterTargetArn = aws_sqs_queue.manifest_uploaded_dlq.arn
+    maxReceiveCount     = 15 # more than the reconciliation attempts
+  })
+
+  tags = {
+    Name = "${local.stack_name}-manifest-uploaded"
+  }
+}
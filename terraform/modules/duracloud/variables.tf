@@ -15,6 +15,18 @@ variable "bucket_requested_image_uri" {
   default     = "docker.io/duracloud/bucket-requested:latest"
 }
 
+variable "bucket_seeder_image_uri" {
+  description = "Docker image for Bucket Seeder function"
+  type        = string
+  default     = "docker.io/duracloud/bucket-seeder:latest"
+}
+
+variable "bucket_seeder_rate" {
+  description = "Deposits per second the Bucket Seeder function enqueues (0 for unlimited)"
+  type        = number
+  default     = 20
+}
+
 variable "checksum_exporter_image_uri" {
   description = "Docker image for Checksum Exporter function"
   type        = string
@@ -45,6 +57,74 @@ variable "checksum_failure_image_uri" {
   default     = "docker.io/duracloud/checksum-failure:latest"
 }
 
+variable "checksum_migrator_image_uri" {
+  description = "Docker image for Checksum Migrator function"
+  type        = string
+  default     = "docker.io/duracloud/checksum-migrator:latest"
+}
+
+variable "checksum_rebalancer_image_uri" {
+  description = "Docker image for Checksum Rebalancer function"
+  type        = string
+  default     = "docker.io/duracloud/checksum-rebalancer:latest"
+}
+
+variable "checksum_repair_mode" {
+  description = "Repair of corrupted objects from their replica during verification (off, dry-run or on)"
+  type        = string
+  default     = "off"
+  validation {
+    condition     = contains(["off", "dry-run", "on"], var.checksum_repair_mode)
+    error_message = "Checksum repair mode must be off, dry-run or on."
+  }
+}
+
+variable "checksum_restore_image_uri" {
+  description = "Docker image for Checksum Restore function"
+  type        = string
+  default     = "docker.io/duracloud/checksum-restore:latest"
+}
+
+variable "checksum_scheduler_image_uri" {
+  description = "Docker image for Checksum Scheduler function"
+  type        = string
+  default     = "docker.io/duracloud/checksum-scheduler:latest"
+}
+
+variable "checksum_sweeper_alert_threshold" {
+  description = "Number of orphaned checksum records found by a sweep that sends an alert (0 disables it)"
+  type        = number
+  default     = 100
+}
+
+variable "checksum_sweeper_image_uri" {
+  description = "Docker image for Checksum Sweeper function"
+  type        = string
+  default     = "docker.io/duracloud/checksum-sweeper:latest"
+}
+
+variable "checksum_sweeper_recheck_failed" {
+  description = "Failing checksum records the sweeper schedules to be checked again (all, none or transient)"
+  type        = string
+  default     = "transient"
+  validation {
+    condition     = contains(["all", "none", "transient"], var.checksum_sweeper_recheck_failed)
+    error_message = "Checksum sweeper recheck failed must be all, none or transient."
+  }
+}
+
+variable "checksum_sweeper_recheck_failed_after" {
+  description = "Period after their last check that failing checksum records are checked again (e.g. 7d or 1m)"
+  type        = string
+  default     = "7d"
+}
+
+variable "checksum_sweeper_schedule" {
+  description = "Cron schedule for sweeping checksum records without a scheduled verification"
+  type        = string
+  default     = "cron(0 8 * * ? *)"
+}
+
 variable "checksum_verification_image_uri" {
   description = "Docker image for Checksum Verification function"
   type        = string
@@ -63,6 +143,24 @@ variable "file_uploaded_image_uri" {
   default     = "docker.io/duracloud/file-uploaded:latest"
 }
 
+variable "inventory_reconciler_enqueue_deposits" {
+  description = "Enqueue deposits of the versions the inventory reconciler finds without a checksum record"
+  type        = bool
+  default     = false
+}
+
+variable "inventory_reconciler_image_uri" {
+  description = "Docker image for Inventory Reconciler function"
+  type        = string
+  default     = "docker.io/duracloud/inventory-reconciler:latest"
+}
+
+variable "inventory_reconciler_schedule" {
+  description = "Cron schedule for inventory reconciliation with the checksum table"
+  type        = string
+  default     = "cron(0 10 ? * SUN *)"
+}
+
 variable "inventory_unwrap_image_uri" {
   description = "Docker image for Inventory Unwrap function"
   type        = string
@@ -75,18 +173,82 @@ variable "inventory_unwrap_storage" {
   default     = 512
 }
 
+variable "legacy_import_image_uri" {
+  description = "Docker image for Legacy Import function"
+  type        = string
+  default     = "docker.io/duracloud/legacy-import:latest"
+}
+
+variable "list_failures_image_uri" {
+  description = "Docker image for List Failures function"
+  type        = string
+  default     = "docker.io/duracloud/list-failures:latest"
+}
+
+variable "manifest_reconciler_image_uri" {
+  description = "Docker image for Manifest Reconciler function"
+  type        = string
+  default     = "docker.io/duracloud/manifest-reconciler:latest"
+}
+
 variable "report_generator_image_uri" {
   description = "Docker image for Report Generator function"
   type        = string
   default     = "docker.io/duracloud/report-generator:latest"
 }
 
+variable "replica_checks" {
+  description = "Check the replica of each object in its replication bucket during verification"
+  type        = bool
+  default     = false
+}
+
+variable "replica_restores" {
+  description = "Restore archived replicas so their check can finish, requires replica_checks"
+  type        = bool
+  default     = false
+}
+
+variable "replica_check_interval" {
+  description = "Period after its last check that verification checks the replica of an object again (e.g. 1y or 6m, 0d checks it on every verification)"
+  type        = string
+  default     = "1y"
+}
+
 variable "report_generator_schedule" {
   description = "Cron schedule for storage report generation"
   type        = string
   default     = "cron(0 8 ? * SUN *)"
 }
 
+variable "retire_object_key_tables" {
+  description = "Remove the checksum and scheduler tables keyed by ObjectKey, only once the checksum-migrator function has copied their records"
+  type        = bool
+  default     = false
+}
+
+variable "verification_budget_period" {
+  description = "Window of the verification byte budget (daily or hourly)"
+  type        = string
+  default     = "daily"
+  validation {
+    condition     = contains(["daily", "hourly"], var.verification_budget_period)
+    error_message = "Verification budget period must be daily or hourly."
+  }
+}
+
+variable "verification_byte_budget" {
+  description = "Bytes checksum verification may read per budget window, such as 500GB or 2TB (empty for unlimited)"
+  type        = string
+  default     = ""
+}
+
+variable "verify_requested_image_uri" {
+  description = "Docker image for Verify Requested function"
+  type        = string
+  default     = "docker.io/duracloud/verify-requested:latest"
+}
+
 variable "lambda_architecture" {
   description = "Architecture for Lambda functions"
   type        = string
@@ -19,8 +19,8 @@ func TestChecksumFailureWorkflow(t *testing.T) {
 	ctx := context.Background()
 
 	// Table names
-	checksumTable := fmt.Sprintf("%s-checksum-table", stackName)
-	schedulerTable := fmt.Sprintf("%s-checksum-scheduler-table", stackName)
+	checksumTable := fmt.Sprintf("%s-checksum-version-table", stackName)
+	schedulerTable := fmt.Sprintf("%s-checksum-version-scheduler-table", stackName)
 
 	ddb := db.NewDB(ctx, clients.DynamoDB, checksumTable, schedulerTable)
 
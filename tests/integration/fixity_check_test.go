@@ -128,8 +128,8 @@ func TestDatabaseOperations(t *testing.T) {
 	clients, stackName := setupTestClients(t)
 	ctx := context.Background()
 
-	checksumTable := fmt.Sprintf("%s-checksum-table", stackName)
-	schedulerTable := fmt.Sprintf("%s-checksum-scheduler-table", stackName)
+	checksumTable := fmt.Sprintf("%s-checksum-version-table", stackName)
+	schedulerTable := fmt.Sprintf("%s-checksum-version-scheduler-table", stackName)
 	ddb := db.NewDB(ctx, clients.DynamoDB, checksumTable, schedulerTable)
 
 	t.Run("ChecksumRecordCRUD", func(t *testing.T) {
@@ -174,7 +174,7 @@ func TestDatabaseOperations(t *testing.T) {
 		assert.False(t, finalRecord.LastChecksumSuccess)
 
 		// Test record deletion
-		err = ddb.Delete(obj)
+		err = ddb.Delete(obj, "")
 		require.NoError(t, err, "Should delete checksum record")
 
 		_, err = ddb.Get(obj)
@@ -13,7 +13,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -51,8 +53,8 @@ func NewFixityTestHelper(t *testing.T) *FixityTestHelper {
 	return &FixityTestHelper{
 		Clients:            clients,
 		StackName:          stackName,
-		ChecksumTableName:  fmt.Sprintf("%s-checksum-table", stackName),
-		SchedulerTableName: fmt.Sprintf("%s-checksum-scheduler-table", stackName),
+		ChecksumTableName:  fmt.Sprintf("%s-checksum-version-table", stackName),
+		SchedulerTableName: fmt.Sprintf("%s-checksum-version-scheduler-table", stackName),
 		Context:            ctxWithAWS,
 	}
 }
@@ -76,6 +78,17 @@ func (h *FixityTestHelper) CreateTestBucket(t *testing.T, suffix string) string
 	return fullBucketName
 }
 
+// CurrentVersion returns the current version of an object, checksum records are kept per version
+func (h *FixityTestHelper) CurrentVersion(t *testing.T, bucketName, fileName string) files.S3Object {
+	head, err := h.Clients.S3.HeadObject(h.Context, &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	require.NoError(t, err, "Should retrieve the current version of %s/%s", bucketName, fileName)
+
+	return files.NewS3ObjectVersion(bucketName, fileName, aws.ToString(head.VersionId))
+}
+
 // InvokeVerificationFunction directly invokes the checksum verification Lambda function
 func (h *FixityTestHelper) InvokeVerificationFunction(t *testing.T, record db.ChecksumRecord) {
 	// Create DynamoDB stream event payload similar to TTL expiry
@@ -96,8 +109,8 @@ func (h *FixityTestHelper) InvokeVerificationFunction(t *testing.T, record db.Ch
 						"BucketName": map[string]string{
 							"S": record.BucketName,
 						},
-						"ObjectKey": map[string]string{
-							"S": record.ObjectKey,
+						"VersionKey": map[string]string{
+							"S": db.VersionKey(record.Object()),
 						},
 					},
 					"OldImage": map[string]any{
@@ -107,6 +120,12 @@ func (h *FixityTestHelper) InvokeVerificationFunction(t *testing.T, record db.Ch
 						"ObjectKey": map[string]string{
 							"S": record.ObjectKey,
 						},
+						"VersionId": map[string]string{
+							"S": record.VersionId,
+						},
+						"VersionKey": map[string]string{
+							"S": db.VersionKey(record.Object()),
+						},
 						"NextChecksumDate": map[string]string{
 							"S": record.NextChecksumDate.Format(time.RFC3339),
 						},
@@ -142,7 +161,7 @@ func (h *FixityTestHelper) InvokeVerificationFunction(t *testing.T, record db.Ch
 // SimulateCorruption simulates file corruption by modifying the stored checksum in the database
 func (h *FixityTestHelper) SimulateCorruption(t *testing.T, bucketName, fileName string) db.ChecksumRecord {
 	ddb := db.NewDB(h.Context, h.Clients.DynamoDB, h.ChecksumTableName, h.SchedulerTableName)
-	obj := files.NewS3Object(bucketName, fileName)
+	obj := h.CurrentVersion(t, bucketName, fileName)
 
 	// Get the current record
 	record, err := ddb.Get(obj)
@@ -215,7 +234,7 @@ func (h *FixityTestHelper) ValidateSuccessfulVerification(t *testing.T, beforeRe
 
 // WaitForThenValidateChecksum waits for the initial checksum calculation and validates the record
 func (h *FixityTestHelper) WaitForThenValidateChecksum(t *testing.T, bucketName, fileName, expectedChecksum string) db.ChecksumRecord {
-	obj := files.NewS3Object(bucketName, fileName)
+	obj := h.CurrentVersion(t, bucketName, fileName)
 
 	// Configure wait parameters for initial checksum processing
 	cfg := DefaultWaitConfig()
@@ -245,7 +264,7 @@ func (h *FixityTestHelper) WaitForThenValidateChecksum(t *testing.T, bucketName,
 
 // WaitForVerification waits for verification processing and returns the updated record
 func (h *FixityTestHelper) WaitForVerification(t *testing.T, bucketName, fileName string, lastChecksumDate time.Time) db.ChecksumRecord {
-	obj := files.NewS3Object(bucketName, fileName)
+	obj := h.CurrentVersion(t, bucketName, fileName)
 
 	// Configure wait parameters
 	cfg := DefaultWaitConfig()